@@ -0,0 +1,403 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words crypto subtle encoding json errors http strconv strings github faulunch internal i18n location ltime types
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tkw1536/faulunch/internal"
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+// registerAdminRoutes registers administrative routes to the server mux.
+// All of these require the AdminToken to be passed.
+func (server *Server) registerAdminRoutes() {
+	// admin api
+	server.mux.HandleFunc("GET /api/v1/admin/overrides/{location}/{day}", server.requireAdmin(server.handleAPIAdminOverrides))
+	server.mux.HandleFunc("POST /api/v1/admin/overrides/{location}/{day}", server.requireAdmin(server.handleAPIAdminCreateOverride))
+	server.mux.HandleFunc("PUT /api/v1/admin/overrides/{location}/{day}/{id}", server.requireAdmin(server.handleAPIAdminUpdateOverride))
+	server.mux.HandleFunc("DELETE /api/v1/admin/overrides/{location}/{day}/{id}", server.requireAdmin(server.handleAPIAdminDeleteOverride))
+	server.mux.HandleFunc("GET /api/v1/admin/unknown-locations", server.requireAdmin(server.handleAPIAdminUnknownLocations))
+	server.mux.HandleFunc("GET /api/v1/admin/unknown-annotations", server.requireAdmin(server.handleAPIAdminUnknownAnnotations))
+	server.mux.HandleFunc("GET /api/v1/admin/categories", server.requireAdmin(server.handleAPIAdminCategories))
+	server.mux.HandleFunc("PUT /api/v1/admin/categories/{name}", server.requireAdmin(server.handleAPIAdminSetCategory))
+
+	// admin page
+	server.mux.HandleFunc("GET /admin/{location}/{day}", server.requireAdmin(server.HandleAdmin))
+	server.mux.HandleFunc("POST /admin/{location}/{day}", server.requireAdmin(server.HandleAdminForm))
+}
+
+const (
+	unauthorizedError = `{"status":"Unauthorized"}`
+	forbiddenError    = `{"status":"Forbidden"}`
+	badRequestError   = `{"status":"Bad Request"}`
+)
+
+// adminCrossOrigin rejects cross-origin browser requests to administrative routes.
+// Browsers resend http basic authentication automatically, so without it other sites could submit the admin form on behalf of an administrator.
+var adminCrossOrigin = http.NewCrossOriginProtection()
+
+// requireAdmin wraps handler to only be called when the request carries the admin token.
+// The token may be passed either as a bearer token, or as the password of http basic authentication.
+// Cross-origin requests other than GET and HEAD are rejected, see [http.CrossOriginProtection].
+// Successful requests other than GET and HEAD drop all cached responses.
+func (server *Server) requireAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if server.AdminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !server.isAdmin(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="faulunch admin"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(unauthorizedError))
+			return
+		}
+
+		if err := adminCrossOrigin.Check(r); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(forbiddenError))
+			return
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			handler(w, r)
+			return
+		}
+
+		// administrators may have modified the database
+		recorder := &internal.ResponseRecorder{ResponseWriter: w}
+		handler(recorder, r)
+		if recorder.StatusCode() < http.StatusBadRequest {
+			server.InvalidateCache()
+		}
+	}
+}
+
+// isAdmin checks if the given request carries the admin token.
+func (server *Server) isAdmin(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(server.AdminToken)) == 1
+}
+
+// handleBadRequest sends a bad request response to the caller
+func (server *Server) handleBadRequest(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte(badRequestError))
+}
+
+// adminTarget parses the location and day from the given request.
+// ok indicates if both are valid.
+func adminTarget(r *http.Request) (loc location.Location, day ltime.Day, ok bool) {
+	loc = location.Location(r.PathValue("location"))
+	day = ltime.ParseDay(r.PathValue("day"))
+	return loc, day, loc.Valid() && day != 0
+}
+
+func (server *Server) handleAPIAdminOverrides(w http.ResponseWriter, r *http.Request) {
+	loc, day, ok := adminTarget(r)
+	if !ok {
+		server.handleNotFound(w)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "API.Admin.Overrides").Str("location", string(loc)).Stringer("day", day).Logger()
+
+	overrides, err := server.API.Overrides(r.Context(), loc, day)
+	logger.Trace().Err(err).Msg("API.Overrides")
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
+
+func (server *Server) handleAPIAdminCreateOverride(w http.ResponseWriter, r *http.Request) {
+	server.handleAPIAdminStoreOverride(w, r, 0)
+}
+
+func (server *Server) handleAPIAdminUpdateOverride(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+	if err != nil || id == 0 {
+		server.handleNotFound(w)
+		return
+	}
+	server.handleAPIAdminStoreOverride(w, r, uint(id))
+}
+
+func (server *Server) handleAPIAdminStoreOverride(w http.ResponseWriter, r *http.Request, id uint) {
+	loc, day, ok := adminTarget(r)
+	if !ok {
+		server.handleNotFound(w)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "API.Admin.StoreOverride").Str("location", string(loc)).Stringer("day", day).Logger()
+
+	var override MenuOverride
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil || override.Validate() != nil {
+		server.handleBadRequest(w)
+		return
+	}
+	override.ID = id
+	override.Location = loc
+	override.Day = day
+
+	err := server.API.StoreOverride(r.Context(), &override)
+	logger.Info().Err(err).Uint("id", override.ID).Msg("API.StoreOverride")
+	switch {
+	case errors.Is(err, ErrNoOverride):
+		server.handleNotFound(w)
+		return
+	case err != nil:
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+func (server *Server) handleAPIAdminDeleteOverride(w http.ResponseWriter, r *http.Request) {
+	loc, day, ok := adminTarget(r)
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+	if !ok || err != nil {
+		server.handleNotFound(w)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "API.Admin.DeleteOverride").Str("location", string(loc)).Stringer("day", day).Logger()
+
+	err = server.API.DeleteOverride(r.Context(), loc, day, uint(id))
+	logger.Info().Err(err).Uint64("id", id).Msg("API.DeleteOverride")
+	switch {
+	case errors.Is(err, ErrNoOverride):
+		server.handleNotFound(w)
+		return
+	case err != nil:
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleAPIAdminUnknownLocations(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "API.Admin.UnknownLocations").Logger()
+
+	unknown, err := server.API.UnknownLocations(r.Context())
+	logger.Trace().Err(err).Msg("API.UnknownLocations")
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unknown)
+}
+
+func (server *Server) handleAPIAdminUnknownAnnotations(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "API.Admin.UnknownAnnotations").Logger()
+
+	unknown, err := server.API.UnknownAnnotations(r.Context())
+	logger.Trace().Err(err).Msg("API.UnknownAnnotations")
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unknown)
+}
+
+func (server *Server) handleAPIAdminCategories(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "API.Admin.Categories").Logger()
+
+	categories, err := server.API.CategoryTranslations(r.Context())
+	logger.Trace().Err(err).Msg("API.CategoryTranslations")
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// categoryTranslationRequest is the body of a request to set a category translation.
+type categoryTranslationRequest struct {
+	Translation string `json:"translation"`
+}
+
+func (server *Server) handleAPIAdminSetCategory(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	logger := server.Logger.With().Str("route", "API.Admin.SetCategory").Str("name", name).Logger()
+
+	var req categoryTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || name == "" {
+		server.handleBadRequest(w)
+		return
+	}
+
+	err := server.API.SetCategoryTranslation(r.Context(), &logger, name, strings.TrimSpace(req.Translation))
+	logger.Info().Err(err).Str("translation", req.Translation).Msg("API.SetCategoryTranslation")
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type adminContext struct {
+	globalContext
+
+	Day       ltime.Day
+	Location  location.Location
+	Items     []MenuItem
+	Overrides []MenuOverride
+}
+
+// Position returns the position of the item with the given index among the upstream items sharing its category.
+// It returns 0 for items added by an override.
+func (ac adminContext) Position(index int) int {
+	return ac.Items[index].position
+}
+
+// EmptyOverride returns an empty override, used to render the form for adding a new override.
+func (ac adminContext) EmptyOverride() MenuOverride {
+	return MenuOverride{}
+}
+
+// HandleAdmin renders the admin page to edit the menu of the given location and day.
+func (server *Server) HandleAdmin(w http.ResponseWriter, r *http.Request) {
+	loc, day, ok := adminTarget(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "HandleAdmin").Str("location", string(loc)).Stringer("day", day).Logger()
+
+	ac := adminContext{
+		globalContext: globalContext{
+			Lang:       i18n.English,
+			requestURI: r.URL.RequestURI(),
+			legal:      server.Legal,
+		},
+		Location: loc,
+		Day:      day,
+	}
+	if err := ac.loadLastSync(r.Context(), &server.API); err != nil {
+		logger.Debug().Err(err).Msg("LoadLastSync")
+	}
+
+	var err error
+
+	ac.Items, err = server.API.MenuItems(loc, day)
+	logger.Debug().Err(err).Msg("API.MenuItems")
+	if err != nil {
+		http.Error(w, "failed to load menu items", http.StatusInternalServerError)
+		return
+	}
+
+	ac.Overrides, err = server.API.Overrides(r.Context(), loc, day)
+	logger.Debug().Err(err).Msg("API.Overrides")
+	if err != nil {
+		http.Error(w, "failed to load overrides", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/html")
+	err = apiServerTemplate.ExecuteTemplate(w, "admin.html", ac)
+	logger.Debug().Err(err).Msg("ExecuteTemplate")
+}
+
+// HandleAdminForm handles a form submitted from the admin page.
+// It then redirects back to the admin page.
+func (server *Server) HandleAdminForm(w http.ResponseWriter, r *http.Request) {
+	loc, day, ok := adminTarget(r)
+	if !ok || r.ParseForm() != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "HandleAdminForm").Str("location", string(loc)).Stringer("day", day).Logger()
+
+	id, _ := strconv.ParseUint(r.PostForm.Get("id"), 10, 0)
+
+	var err error
+	switch r.PostForm.Get("action") {
+	case "delete":
+		err = server.API.DeleteOverride(r.Context(), loc, day, uint(id))
+		logger.Info().Err(err).Uint64("id", id).Msg("API.DeleteOverride")
+	case "save":
+		override := overrideFromForm(r)
+		override.ID = uint(id)
+		override.Location = loc
+		override.Day = day
+
+		err = server.API.StoreOverride(r.Context(), &override)
+		logger.Info().Err(err).Uint("id", override.ID).Msg("API.StoreOverride")
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+}
+
+// overrideFromForm reads a MenuOverride from the post form of r.
+func overrideFromForm(r *http.Request) (override MenuOverride) {
+	form := r.PostForm
+
+	override.Category = strings.TrimSpace(form.Get("category"))
+	override.Position, _ = strconv.Atoi(form.Get("position"))
+	override.CategoryEN = strings.TrimSpace(form.Get("categoryEN"))
+	override.Hidden = form.Get("hidden") != ""
+
+	override.TitleDE = strings.TrimSpace(form.Get("titleDE"))
+	override.TitleEN = strings.TrimSpace(form.Get("titleEN"))
+	override.DescriptionDE = strings.TrimSpace(form.Get("descriptionDE"))
+	override.DescriptionEN = strings.TrimSpace(form.Get("descriptionEN"))
+	override.BeilagenDE = strings.TrimSpace(form.Get("beilagenDE"))
+	override.BeilagenEN = strings.TrimSpace(form.Get("beilagenEN"))
+
+	override.Preis1 = priceFromForm(form.Get("preis1"))
+	override.Preis2 = priceFromForm(form.Get("preis2"))
+	override.Preis3 = priceFromForm(form.Get("preis3"))
+	return
+}
+
+// priceFromForm parses a price from a form value.
+// Returns nil if the value is empty or invalid.
+func priceFromForm(value string) *types.LPrice {
+	var f types.SmartFloat64
+	if value == "" || f.UnmarshalText([]byte(value)) != nil {
+		return nil
+	}
+	price := types.LPrice(f)
+	return &price
+}
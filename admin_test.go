@@ -0,0 +1,67 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words http httptest strings testing github zerolog faulunch
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+)
+
+func TestServer_requireAdmin(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+
+	const (
+		page = "/admin/mensa-sued/1760911200"
+		form = "action=save&category=Aktion&titleDE=K%C3%BCrbissuppe"
+	)
+
+	tests := []struct {
+		name   string
+		token  string // admin token of the server
+		method string
+		path   string
+		header map[string]string
+		want   int
+	}{
+		{"disabled", "", http.MethodGet, page, map[string]string{"Authorization": "Bearer "}, http.StatusNotFound},
+		{"disabled api", "", http.MethodGet, "/api/v1/admin/categories", nil, http.StatusNotFound},
+		{"no credentials", "secret", http.MethodGet, page, nil, http.StatusUnauthorized},
+		{"wrong bearer token", "secret", http.MethodGet, "/api/v1/admin/categories", map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"wrong password", "secret", http.MethodGet, page, map[string]string{"Authorization": "Basic YWRtaW46d3Jvbmc="}, http.StatusUnauthorized},
+		{"bearer token", "secret", http.MethodGet, "/api/v1/admin/categories", map[string]string{"Authorization": "Bearer secret"}, http.StatusOK},
+		{"password", "secret", http.MethodGet, page, map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0"}, http.StatusOK},
+		{"invalid target", "secret", http.MethodGet, "/admin/does-not-exist/0", map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0"}, http.StatusNotFound},
+
+		{"form", "secret", http.MethodPost, page, map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0"}, http.StatusSeeOther},
+		{"form from same origin", "secret", http.MethodPost, page, map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0", "Origin": "http://example.com", "Sec-Fetch-Site": "same-origin"}, http.StatusSeeOther},
+		{"form from other site", "secret", http.MethodPost, page, map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0", "Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"form from other origin", "secret", http.MethodPost, page, map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"form without credentials", "secret", http.MethodPost, page, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}, AdminToken: tt.token}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("%s %s returned status %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("unauthorized response has no WWW-Authenticate header")
+			}
+		})
+	}
+}
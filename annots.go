@@ -1,158 +1,154 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words html template regexp strings github zerolog faulunch internal fmap
+//spellchecker:words html template regexp strings unicode utf8 github zerolog faulunch internal fmap
 import (
 	"html/template"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/rs/zerolog"
 	"github.com/tkw1536/faulunch/internal"
 	"github.com/tkw1536/faulunch/internal/annotations"
 )
 
-var annotationPattern = regexp.MustCompile(`\([^)\s]+\)`)
+// UnknownToken represents an unknown annotation found in a text field of a MenuItem.
+type UnknownToken struct {
+	Token   string `json:"token"`   // the unknown annotation, after correcting typos
+	Field   string `json:"field"`   // name of the field it was found in, e.g. "TitleDE"
+	Offset  int    `json:"offset"`  // byte offset of the original token within the field
+	Context string `json:"context"` // text surrounding the token
+}
+
+// annotationCollector collects the annotations found in the text fields of a MenuItem.
+type annotationCollector struct {
+	logger *zerolog.Logger
+	item   *MenuItem
+
+	additives   map[annotations.Additive]struct{}
+	allergens   map[annotations.Allergen]struct{}
+	ingredients map[annotations.Ingredient]struct{}
+	unknown     []UnknownToken
+}
 
 func (item *MenuItem) extractAnnotations(logger *zerolog.Logger) {
-	additives := make(map[annotations.Additive]struct{})
-	allergens := make(map[annotations.Allergen]struct{})
-	ingredients := make(map[annotations.Ingredient]struct{}, len(item.Piktogramme.Data()))
+	collector := annotationCollector{
+		logger: logger,
+		item:   item,
+
+		additives:   make(map[annotations.Additive]struct{}),
+		allergens:   make(map[annotations.Allergen]struct{}),
+		ingredients: make(map[annotations.Ingredient]struct{}, len(item.Piktogramme.Data())),
+	}
 
 	for _, ing := range item.Piktogramme.Data() {
-		ingredients[ing] = struct{}{}
+		collector.ingredients[ing] = struct{}{}
 	}
 
-	item.HTMLTitleDE = item.renderAnnotations(logger, item.TitleDE, false, additives, allergens, ingredients)
-	item.HTMLTitleEN = item.renderAnnotations(logger, item.TitleEN, true, additives, allergens, ingredients)
+	item.HTMLTitleDE = collector.render("TitleDE", item.TitleDE, false)
+	item.HTMLTitleEN = collector.render("TitleEN", item.TitleEN, true)
 
-	item.HTMLDescriptionDE = item.renderAnnotations(logger, item.DescriptionDE, false, additives, allergens, ingredients)
-	item.HTMLDescriptionEN = item.renderAnnotations(logger, item.DescriptionEN, true, additives, allergens, ingredients)
+	item.HTMLDescriptionDE = collector.render("DescriptionDE", item.DescriptionDE, false)
+	item.HTMLDescriptionEN = collector.render("DescriptionEN", item.DescriptionEN, true)
 
-	item.HTMLBeilagenDE = item.renderAnnotations(logger, item.BeilagenDE, false, additives, allergens, ingredients)
-	item.HTMLBeilagenEN = item.renderAnnotations(logger, item.BeilagenEN, true, additives, allergens, ingredients)
+	item.HTMLBeilagenDE = collector.render("BeilagenDE", item.BeilagenDE, false)
+	item.HTMLBeilagenEN = collector.render("BeilagenEN", item.BeilagenEN, true)
 
 	// store all the additive and ingredient data
 	// then sort it for convenience
 
-	internal.SetJSONData(&item.AdditiveAnnotations, internal.SortedKeysOf(additives, func(a, b annotations.Additive) int { return a.Cmp(b) }))
-	internal.SetJSONData(&item.AllergenAnnotations, internal.SortedKeysOf(allergens, func(a, b annotations.Allergen) int { return a.Cmp(b) }))
-	internal.SetJSONData(&item.IngredientAnnotations, internal.SortedKeysOf(ingredients, func(a, b annotations.Ingredient) int { return a.Cmp(b) }))
+	internal.SetJSONData(&item.AdditiveAnnotations, internal.SortedKeysOf(collector.additives, func(a, b annotations.Additive) int { return a.Cmp(b) }))
+	internal.SetJSONData(&item.AllergenAnnotations, internal.SortedKeysOf(collector.allergens, func(a, b annotations.Allergen) int { return a.Cmp(b) }))
+	internal.SetJSONData(&item.EUAllergens, annotations.EUAllergens(item.AllergenAnnotations.Data()))
+	internal.SetJSONData(&item.IngredientAnnotations, internal.SortedKeysOf(collector.ingredients, func(a, b annotations.Ingredient) int { return a.Cmp(b) }))
+	internal.SetJSONData(&item.UnknownTokens, collector.unknown)
 }
 
-// RenderAnnotations renders annotations in the provided text
-func (menu *MenuItem) renderAnnotations(logger *zerolog.Logger, text string, english bool, additives map[annotations.Additive]struct{}, allergens map[annotations.Allergen]struct{}, ingredients map[annotations.Ingredient]struct{}) template.HTML {
-	values := annotationPattern.Split(text, -1)
-	for i, v := range values {
-		values[i] = template.HTMLEscapeString(v)
-	}
-
-	matches := annotationPattern.FindAllString(text, -1)
-
+// render renders the annotations in the given text field.
+// Groups without any known annotation are rendered as plain text.
+func (collector *annotationCollector) render(field string, text string, english bool) template.HTML {
 	var builder strings.Builder
 	var buffer []string
-	for i, value := range values {
 
-		// write the current non-match
-		// and check if there is a following value
-		builder.WriteString(value)
-		if len(matches) <= i {
-			continue
-		}
-
-		// trim off the leading and trailing bracket
-		matches[i] = matches[i][1 : len(matches[i])-1]
-
-		// find all the individual annotations
-		// and check if there is at least one valid one
-		annots := strings.FieldsFunc(matches[i], func(r rune) bool { return r == ',' || r == '.' })
-		annots = fixAnnotTypos(annots)
-		if !anyValidAnnot(annots...) {
-			// no valid annotation => skip
-			builder.WriteRune('(')
-			builder.WriteString(matches[i])
-			builder.WriteRune(')')
-			continue
-		}
+	for _, node := range annotations.Parse(text) {
+		switch node := node.(type) {
+		case annotations.Text:
+			builder.WriteString(template.HTMLEscapeString(node.Value))
+		case annotations.Group:
+			// correct typos, remembering the token each annotation came from
+			var annots []annotations.Token
+			for _, token := range node.Tokens {
+				for _, fixed := range annotations.FixTypo(token.Value) {
+					annots = append(annots, annotations.Token{Span: token.Span, Value: fixed})
+				}
+			}
 
-		// replace all the valid annotations
+			if !anyValidAnnot(annots) {
+				// no valid annotation => skip
+				builder.WriteString(template.HTMLEscapeString("(" + node.Value + ")"))
+				continue
+			}
 
-		builder.WriteString("<span class='annot'>")
+			// replace all the valid annotations
+			builder.WriteString("<span class='annot'>")
+
+			buffer = buffer[:0]
+			for _, annot := range annots {
+				html, ok := collector.renderAnnot(annot.Value, english)
+				if !ok {
+					collector.logger.Error().Str("annot", annot.Value).Str("field", field).Int("offset", annot.Start).Int("day", int(collector.item.Day)).Str("location", string(collector.item.Location)).Bool("english", english).Msg("Unknown annotation")
+					collector.unknown = append(collector.unknown, UnknownToken{Token: annot.Value, Field: field, Offset: annot.Start, Context: surrounding(text, annot.Span, contextSize)})
+				}
+				buffer = append(buffer, string(html))
+			}
 
-		buffer = buffer[:0]
-		for _, annot := range annots {
-			buffer = append(buffer, string(menu.renderAnnot(logger, annot, english, additives, allergens, ingredients)))
+			builder.WriteString(strings.Join(buffer, ", "))
+			builder.WriteString("</span>")
 		}
-
-		builder.WriteString(strings.Join(buffer, ", "))
-		builder.WriteString("</span>")
 	}
 
 	return template.HTML(builder.String())
 }
 
-// fixes typos in the annotations
-func fixAnnotTypos(annots []string) []string {
-	fix := make([]string, 0, len(annots))
-	for _, a := range annots {
-		switch a {
-		case "Vegan":
-			fix = append(fix, "veg")
-		case "EiEi", "Egg":
-			fix = append(fix, "Ei")
-		case "Mi7":
-			fix = append(fix, "Mi", "7")
-		case "Sel1":
-			fix = append(fix, "Sel", "1")
-		case "RWz":
-			fix = append(fix, "R", "Wz")
-		case "Sul": // Not sure about this one
-			fix = append(fix, "Su")
-		case "VWz":
-			fix = append(fix, "V", "Wz")
-		case "SelGe":
-			fix = append(fix, "Sel", "Ge")
-		case "SuGe":
-			fix = append(fix, "Su", "Ge")
-		case "Wzel":
-			fix = append(fix, "Wz")
-		case "Sun":
-			fix = append(fix, "So")
-		case "Ma":
-			fix = append(fix, "Man")
-		case "Wed":
-			fix = append(fix, "Mi")
-		case "Se":
-			fix = append(fix, "Wa")
-		case "3", "cond":
-			// these are mismatches and no longer used
-		default:
-			fix = append(fix, a)
-		}
+// contextSize is the number of bytes of context to keep around unknown annotations
+const contextSize = 30
+
+// surrounding returns the text surrounding the given span, including up to size bytes on each side.
+// The context is extended to not split any utf-8 encoded runes.
+func surrounding(text string, span annotations.Span, size int) string {
+	start := max(span.Start-size, 0)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
 	}
-	return fix
+	end := min(span.End+size, len(text))
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	return text[start:end]
 }
 
-// validMatches checks if at least one annotation inside the match is valid
-func anyValidAnnot(matches ...string) bool {
-	for _, c := range matches {
-		if annotations.Additive(c).Known() || annotations.Allergen(c).Known() || annotations.Ingredient(c).Known() {
+// anyValidAnnot checks if at least one of the given annotations is valid
+func anyValidAnnot(annots []annotations.Token) bool {
+	for _, c := range annots {
+		if annotations.Additive(c.Value).Known() || annotations.Allergen(c.Value).Known() || annotations.Ingredient(c.Value).Known() {
 			return true
 		}
 	}
 	return false
 }
 
-// renders and extracts a single annotation
-func (menu *MenuItem) renderAnnot(logger *zerolog.Logger, annot string, english bool, additives map[annotations.Additive]struct{}, allergens map[annotations.Allergen]struct{}, ingredients map[annotations.Ingredient]struct{}) template.HTML {
+// renderAnnot renders and records a single annotation.
+// ok indicates if the annotation is known, unknown annotations are rendered as plain text.
+func (collector *annotationCollector) renderAnnot(annot string, english bool) (html template.HTML, ok bool) {
 	{
 		add := annotations.Additive(annot)
 		if add, ok := add.Normalize(); ok {
-			additives[add] = struct{}{}
+			collector.additives[add] = struct{}{}
 			if english {
-				return add.ENHTML()
+				return add.ENHTML(), true
 			} else {
-				return add.DEHTML()
+				return add.DEHTML(), true
 			}
 		}
 	}
@@ -161,11 +157,11 @@ func (menu *MenuItem) renderAnnot(logger *zerolog.Logger, annot string, english
 
 		all := annotations.Allergen(annot)
 		if all, ok := all.Normalize(); ok {
-			allergens[all] = struct{}{}
+			collector.allergens[all] = struct{}{}
 			if english {
-				return all.ENHTML()
+				return all.ENHTML(), true
 			} else {
-				return all.DEHTML()
+				return all.DEHTML(), true
 			}
 		}
 	}
@@ -173,35 +169,35 @@ func (menu *MenuItem) renderAnnot(logger *zerolog.Logger, annot string, english
 	{
 		ing := annotations.Ingredient(annot)
 		if ing, ok := ing.Normalize(); ok {
-			ingredients[ing] = struct{}{}
+			collector.ingredients[ing] = struct{}{}
 			if english {
-				return ing.ENHTML()
+				return ing.ENHTML(), true
 			} else {
-				return ing.DEHTML()
+				return ing.DEHTML(), true
 			}
 		}
 	}
 
-	logger.Error().Str("annot", annot).Int("day", int(menu.Day)).Str("location", string(menu.Location)).Bool("english", english).Msg("Unknown annotation")
-
-	return template.HTML(template.HTMLEscapeString(annot))
+	return template.HTML(template.HTMLEscapeString(annot)), false
 }
 
 var pictogramRegexp = regexp.MustCompile(regexp.QuoteMeta("https://www.max-manager.de/daten-extern/sw-erlangen-nuernberg/icons/") + `([^\.]+)` + regexp.QuoteMeta(".png"))
 
-// ParseIngredients parses ingredients from a list of pictograms
-func (menu *MenuItem) parseIngredients(s string, logger *zerolog.Logger) []annotations.Ingredient {
+// ParseIngredients parses ingredients from a list of pictograms.
+// Also returns the names of unknown pictograms.
+func (menu *MenuItem) parseIngredients(s string, logger *zerolog.Logger) (ings []annotations.Ingredient, unknown []string) {
 	ingredients := make(map[annotations.Ingredient]struct{})
 	for _, match := range pictogramRegexp.FindAllStringSubmatch(s, -1) {
 		ing := annotations.Ingredient(match[1])
 		ing.DoNormalize()
 		if !ing.Known() {
 			logger.Error().Str("ingredient", match[1]).Time("day", menu.Day.Time()).Str("location", string(menu.Location)).Msg("Unknown Ingredient")
+			unknown = append(unknown, match[1])
 			continue
 		}
 		ingredients[ing] = struct{}{}
 	}
 
-	ings := internal.SortedKeysOf(ingredients, func(a, b annotations.Ingredient) int { return a.Cmp(b) })
-	return ings
+	ings = internal.SortedKeysOf(ingredients, func(a, b annotations.Ingredient) int { return a.Cmp(b) })
+	return ings, unknown
 }
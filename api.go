@@ -1,13 +1,16 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words errors slices gorm
+//spellchecker:words errors maps slices gorm
 import (
+	"cmp"
 	"errors"
+	"maps"
 	"net/http"
 
 	"slices"
 
+	"github.com/rs/zerolog"
 	"github.com/tkw1536/faulunch/internal/location"
 	"github.com/tkw1536/faulunch/internal/ltime"
 	"gorm.io/gorm"
@@ -24,7 +27,7 @@ type API struct {
 // Locations returns the list of available locations in the database.
 // They are sorted by their type.
 func (api *API) Locations() (locations []location.Location, err error) {
-	res := api.DB.Model(&MenuItem{}).Distinct("Location").Pluck("location", &locations)
+	res := api.menuDays().Distinct("Location").Pluck("location", &locations)
 	err = res.Error
 
 	slices.SortStableFunc(locations, func(a, b location.Location) int {
@@ -47,9 +50,18 @@ type Pagination struct {
 
 var errNoCurrent = errors.New("no current item")
 
+// menuDays returns a query over all (location, day) pairs that have a menu.
+// This includes days that are only known from a [MenuOverride].
+func (api *API) menuDays() *gorm.DB {
+	return api.DB.Table("(?) AS menu_days", api.DB.Raw(
+		"SELECT location, day FROM menu_items UNION SELECT location, day FROM menu_overrides WHERE hidden = ?",
+		false,
+	))
+}
+
 func (api *API) CurrentDay(location location.Location, query ltime.Day) (day ltime.Day, err error) {
 	var days []ltime.Day
-	res := api.DB.Model(&MenuItem{}).
+	res := api.menuDays().
 		Where("Location = ? AND Day <= ? ", location, query).
 		Distinct("Day").Order("day DESC").
 		Limit(1).
@@ -73,21 +85,21 @@ func (api *API) DayPagination(location location.Location, query ltime.Day, size
 
 	// make a query to fill everything up
 	for _, q := range []*gorm.DB{
-		api.DB.Model(&MenuItem{}).Where("Location = ? AND Day <= ? ", location, query).
+		api.menuDays().Where("Location = ? AND Day <= ? ", location, query).
 			Distinct("Day").Order("day DESC").
 			Limit(size+1).
 			Pluck("day", &pagination.Prev),
-		api.DB.Model(&MenuItem{}).
+		api.menuDays().
 			Where("Location = ? AND Day > ? ", location, query).
 			Distinct("Day").Order("day ASC").
 			Limit(size).
 			Pluck("day", &pagination.Next),
-		api.DB.Model(&MenuItem{}).
+		api.menuDays().
 			Where("Location = ?", location).
 			Distinct("Day").Order("day ASC").
 			Limit(size).
 			Pluck("day", &pagination.Head),
-		api.DB.Model(&MenuItem{}).
+		api.menuDays().
 			Where("Location = ?", location).
 			Distinct("Day").Order("day DESC").
 			Limit(size).
@@ -174,7 +186,7 @@ func reverse[S ~[]E, E any](s S) {
 
 // KnowsLocation checks if at least one time for the given location is known
 func (api *API) KnowsLocation(location location.Location) (exists bool, err error) {
-	err = api.DB.Model(&MenuItem{}).Where("Location = ?", location).Select("count(*) > 0").Find(&exists).Error
+	err = api.menuDays().Where("Location = ?", location).Select("count(*) > 0").Find(&exists).Error
 	return
 }
 
@@ -183,17 +195,199 @@ func (api *API) Days(location location.Location, day ltime.Day, count int) (days
 	start := day.Normalize()
 	end := day.Add(count)
 
-	res := api.DB.Model(&MenuItem{}).Where("Location = ? AND day >= ? AND day < ?", location, start, end).Order("day DESC").Distinct().Pluck("day", &days)
+	res := api.menuDays().Where("Location = ? AND day >= ? AND day < ?", location, start, end).Order("day DESC").Distinct().Pluck("day", &days)
 	err = res.Error
 	return
 }
 
 // MenuItems returns the menu items for the given day and time.
+// Any overrides for the day are merged in.
 // They are sorted by category.
 // If it does not exist, an empty menu item is returned.
 func (api *API) MenuItems(location location.Location, day ltime.Day) (items []MenuItem, err error) {
-	res := api.DB.Model(&MenuItem{}).Where("Location = ? AND day = ?", location, day).Order("Category ASC").Find(&items)
+	res := api.DB.Model(&MenuItem{}).Where("Location = ? AND day = ?", location, day).Order("Category ASC, ID ASC").Find(&items)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var overrides []MenuOverride
+	res = api.DB.Model(&MenuOverride{}).Where("Location = ? AND day = ?", location, day).Order("ID ASC").Find(&overrides)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var translations CategoryTranslations
+	if len(overrides) > 0 {
+		translations, err = loadCategoryTranslations(api.DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger := zerolog.Nop()
+	items = mergeOverrides(&logger, translations, items, overrides)
+
 	slices.SortStableFunc(items, func(a, b MenuItem) int { return a.Cmp(b) })
-	err = res.Error
-	return
+	return items, nil
+}
+
+// MenuDay is a day with a menu.
+type MenuDay struct {
+	Day   ltime.Day  `json:"day"`
+	Count int        `json:"count"`           // number of items on the menu
+	Items []MenuItem `json:"items,omitempty"` // items on the menu, omitted unless requested
+}
+
+// MenuDays is a page of days with a menu at a location.
+type MenuDays struct {
+	Location string    `json:"location"` // id of the location
+	Days     []MenuDay `json:"days"`
+	Next     string    `json:"next,omitempty"` // cursor of the next page, omitted on the last page
+}
+
+// MenusPage is a page of days with a menu at several locations.
+type MenusPage struct {
+	Menus []LocationMenuDay `json:"menus"`
+	Next  string            `json:"next,omitempty"` // cursor of the next page, omitted on the last page
+}
+
+// MenuCounts returns the days with a menu at any of the given locations from from up to and including to, along with the number of items on each.
+// They are sorted like [API.Menus], but do not include any items.
+//
+// Items are counted by the database, only days with an override are loaded to merge their overrides.
+func (api *API) MenuCounts(locations []location.Location, from, to ltime.Day) (menus []LocationMenuDay, err error) {
+	start := from.Normalize()
+	end := to.Add(1)
+
+	type key struct {
+		Location location.Location
+		Day      ltime.Day
+	}
+
+	var counts []struct {
+		Location location.Location
+		Day      ltime.Day
+		Count    int
+	}
+	res := api.DB.Model(&MenuItem{}).Select("location, day, COUNT(*) AS count").Where("Location IN ? AND day >= ? AND day < ?", locations, start, end).Group("location, day").Scan(&counts)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var overridden []key
+	res = api.DB.Model(&MenuOverride{}).Distinct("location", "day").Where("Location IN ? AND day >= ? AND day < ?", locations, start, end).Scan(&overridden)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	countByKey := make(map[key]int, len(counts))
+	for _, count := range counts {
+		countByKey[key{Location: count.Location, Day: count.Day}] = count.Count
+	}
+
+	// merging overrides may hide, edit or add items, so count the merged items instead.
+	// days only known from an override have a menu if it adds an item (see [API.menuDays]).
+	for _, k := range overridden {
+		items, err := api.MenuItems(k.Location, k.Day)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := countByKey[k]; ok || len(items) > 0 {
+			countByKey[k] = len(items)
+		}
+	}
+
+	keys := slices.Collect(maps.Keys(countByKey))
+	slices.SortFunc(keys, func(a, b key) int {
+		if a.Day != b.Day {
+			return cmp.Compare(a.Day, b.Day)
+		}
+		return cmp.Compare(slices.Index(locations, a.Location), slices.Index(locations, b.Location))
+	})
+
+	menus = make([]LocationMenuDay, len(keys))
+	for i, k := range keys {
+		menus[i] = LocationMenuDay{
+			Location: string(k.Location),
+			MenuDay:  MenuDay{Day: k.Day, Count: countByKey[k]},
+		}
+	}
+	return menus, nil
+}
+
+// LocationMenuDay is a day with a menu at a location.
+type LocationMenuDay struct {
+	Location string `json:"location"` // id of the location
+	MenuDay
+}
+
+// Menus returns the days with a menu at any of the given locations from from up to and including to.
+// They are sorted by day, oldest first, and then in the order of locations.
+// Each day includes its menu items, as returned by [API.MenuItems].
+//
+// Items and overrides of all locations and days are loaded in a single query each.
+func (api *API) Menus(locations []location.Location, from, to ltime.Day) (menus []LocationMenuDay, err error) {
+	start := from.Normalize()
+	end := to.Add(1)
+
+	var items []MenuItem
+	res := api.DB.Model(&MenuItem{}).Where("Location IN ? AND day >= ? AND day < ?", locations, start, end).Order("Day ASC, Category ASC, ID ASC").Find(&items)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var overrides []MenuOverride
+	res = api.DB.Model(&MenuOverride{}).Where("Location IN ? AND day >= ? AND day < ?", locations, start, end).Order("Day ASC, ID ASC").Find(&overrides)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var translations CategoryTranslations
+	if len(overrides) > 0 {
+		translations, err = loadCategoryTranslations(api.DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// group by location and day, which have a menu if they have an item or a visible override (see [API.menuDays])
+	type key struct {
+		Location location.Location
+		Day      ltime.Day
+	}
+	itemsByKey := make(map[key][]MenuItem)
+	overridesByKey := make(map[key][]MenuOverride)
+	var keys []key
+	for _, item := range items {
+		k := key{item.Location, item.Day}
+		if _, ok := itemsByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		itemsByKey[k] = append(itemsByKey[k], item)
+	}
+	for _, override := range overrides {
+		k := key{override.Location, override.Day}
+		overridesByKey[k] = append(overridesByKey[k], override)
+		if _, ok := itemsByKey[k]; !ok && !override.Hidden && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		if a.Day != b.Day {
+			return cmp.Compare(a.Day, b.Day)
+		}
+		return cmp.Compare(slices.Index(locations, a.Location), slices.Index(locations, b.Location))
+	})
+
+	logger := zerolog.Nop()
+	menus = make([]LocationMenuDay, len(keys))
+	for i, k := range keys {
+		items := mergeOverrides(&logger, translations, itemsByKey[k], overridesByKey[k])
+		slices.SortStableFunc(items, func(a, b MenuItem) int { return a.Cmp(b) })
+		menus[i] = LocationMenuDay{
+			Location: string(k.Location),
+			MenuDay:  MenuDay{Day: k.Day, Count: len(items), Items: items},
+		}
+	}
+	return menus, nil
 }
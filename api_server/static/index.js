@@ -36,7 +36,7 @@
     // create element
     const a = document.createElement('a');
     a.setAttribute('href', 'javascript:void(0)');
-    a.append(document.createTextNode(document.documentElement.lang !== 'de' ? 'Share' : 'Teilen'));
+    a.append(document.createTextNode(element.getAttribute('data-share-text') ?? 'Share'));
 
     // add the link
     element.prepend(document.createTextNode(' '));
@@ -137,17 +137,18 @@
                 li: item.li,
                 sort: value.sort ?? 0,
                 value: value.value ?? null,
+                unsuitable: item.li.classList.contains('unsuitable'),
             };
         });
 
         // remove all the items from the list
         sortedItems.forEach(li => autoSortList.removeChild(li.li));
 
-        // sort in the right order
+        // sort in the right order, items unsuitable for the dietary profile always go last
         if (increasing) {
-            sortedItems.sort((a, b) => a.sort - b.sort);
+            sortedItems.sort((a, b) => (a.unsuitable - b.unsuitable) || (a.sort - b.sort));
         } else {
-            sortedItems.sort((a, b) => b.sort - a.sort);
+            sortedItems.sort((a, b) => (a.unsuitable - b.unsuitable) || (b.sort - a.sort));
         };
 
         // add the items back and update the value element
@@ -248,4 +249,78 @@
 
 
     doSort(null, true);
-})();
\ No newline at end of file
+})();
+(function () {
+    // find the location list and the place to put the ui
+    // and make sure the browser can determine the location
+    const locationList = document.querySelector('ul#location-list');
+    const nearMeUI = document.querySelector('#near-me-ui');
+    if (!locationList || !nearMeUI) return;
+    if (!('geolocation' in navigator)) return;
+
+    const sortText = nearMeUI.getAttribute('data-sort-text');
+    const errorText = nearMeUI.getAttribute('data-error-text');
+
+    // computes the distance in meters between two coordinates using the haversine formula
+    const distance = (lat1, lon1, lat2, lon2) => {
+        const rad = (deg) => deg * Math.PI / 180;
+        const dLat = rad(lat2 - lat1);
+        const dLon = rad(lon2 - lon1);
+        const a = Math.sin(dLat / 2) * Math.sin(dLat / 2) + Math.cos(rad(lat1)) * Math.cos(rad(lat2)) * Math.sin(dLon / 2) * Math.sin(dLon / 2);
+        return 2 * 6371000 * Math.asin(Math.sqrt(a));
+    };
+
+    // formats a distance for display
+    const format = (meters) => {
+        if (meters < 1000) return Math.round(meters) + ' m';
+        return (meters / 1000).toLocaleString(document.documentElement.lang, { minimumFractionDigits: 1, maximumFractionDigits: 1 }) + ' km';
+    };
+
+    const doSort = (lat, lon) => {
+        const items = Array.from(locationList.querySelectorAll('li'))
+            .map((li, index) => {
+                const liLat = parseFloat(li.getAttribute('data-lat'));
+                const liLon = parseFloat(li.getAttribute('data-lon'));
+                const known = !isNaN(liLat) && !isNaN(liLon);
+                return { li: li, index: index, distance: known ? distance(lat, lon, liLat, liLon) : Infinity };
+            });
+
+        // locations without coordinates go last, in their original order
+        items.sort((a, b) => (a.distance - b.distance) || (a.index - b.index));
+
+        items.forEach(item => {
+            locationList.removeChild(item.li);
+
+            const span = item.li.querySelector('span.distance');
+            if (span) {
+                span.parentNode.removeChild(span);
+            };
+
+            if (item.distance !== Infinity) {
+                const value = document.createElement('span');
+                value.setAttribute('class', 'distance');
+                value.appendChild(document.createTextNode(' (' + format(item.distance) + ')'));
+                item.li.appendChild(value);
+            };
+
+            locationList.appendChild(item.li);
+        });
+    };
+
+    const a = document.createElement('a');
+    a.setAttribute('href', 'javascript:void(0)');
+    a.appendChild(document.createTextNode(sortText));
+    nearMeUI.appendChild(a);
+
+    a.addEventListener('click', (evt) => {
+        evt.preventDefault();
+
+        navigator.geolocation.getCurrentPosition(
+            (position) => doSort(position.coords.latitude, position.coords.longitude),
+            () => {
+                nearMeUI.innerHTML = '';
+                nearMeUI.appendChild(document.createTextNode(errorText));
+            },
+        );
+    });
+})();
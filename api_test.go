@@ -0,0 +1,53 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words reflect testing github zerolog faulunch internal location ltime
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+func TestAPI_MenuCounts(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	api := faulunch.API{DB: db}
+
+	monday := ltime.ParseDay("1760911200")
+	locations := []location.Location{location.MensaSued}
+
+	// hide an item on monday, and add a menu on wednesday
+	for _, override := range []faulunch.MenuOverride{
+		{Location: location.MensaSued, Day: monday, Category: "Essen 1", Hidden: true},
+		{Location: location.MensaSued, Day: monday.Add(2), Category: "Aktion", TitleDE: "Kürbissuppe"},
+		{Location: location.MensaSued, Day: monday.Add(3), Category: "Aktion", Hidden: true},
+	} {
+		if err := api.StoreOverride(t.Context(), &override); err != nil {
+			t.Fatalf("StoreOverride() error = %v", err)
+		}
+	}
+
+	counts, err := api.MenuCounts(locations, monday.Add(-1), monday.Add(7))
+	if err != nil {
+		t.Fatalf("MenuCounts() error = %v", err)
+	}
+
+	menus, err := api.Menus(locations, monday.Add(-1), monday.Add(7))
+	if err != nil {
+		t.Fatalf("Menus() error = %v", err)
+	}
+	for i := range menus {
+		menus[i].Items = nil
+	}
+
+	if !reflect.DeepEqual(counts, menus) {
+		t.Errorf("MenuCounts() = %v, want %v", counts, menus)
+	}
+	if len(counts) != 3 || counts[2].Day != monday.Add(2) || counts[2].Count != 1 {
+		t.Errorf("MenuCounts() = %v, want three days including the added one", counts)
+	}
+}
@@ -0,0 +1,711 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words html template http reflect regexp slices strings sync github faulunch internal annotations location ltime openapi gorm datatypes
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/openapi"
+	"gorm.io/datatypes"
+)
+
+// apiVersion is a version of the public api, described by its own openapi document.
+type apiVersion struct {
+	Base     string // path all routes are relative to
+	Document string // path the openapi document is served at
+	Explorer string // path the interactive documentation is served at
+
+	Info   openapi.Info
+	Tags   []openapi.Tag
+	Routes []apiRoute
+	Types  func() map[reflect.Type]openapi.Type // documents the go types used in responses
+
+	once sync.Once
+	doc  *openapi.Document
+	err  error
+}
+
+// apiVersions are all versions of the public api.
+var apiVersions = []*apiVersion{apiV1, apiV2}
+
+// apiV1 is version 1 of the public api.
+var apiV1 = &apiVersion{
+	Base:     "/api/v1",
+	Document: "/api/openapi.json",
+	Explorer: "/api/",
+
+	Info: openapi.Info{
+		Title:       "FauLunch API",
+		Description: "This API provides information about the current, past and future menus of serveries related to FAU.",
+		Version:     "1.0.0",
+	},
+	Tags:   apiTags,
+	Routes: apiRoutes,
+	Types:  apiTypes,
+}
+
+// apiRoute is a route of the public api, along with its documentation.
+type apiRoute struct {
+	Method  string
+	Path    string // path relative to the base of the api version, with parameters in braces
+	Handler func(server *Server, w http.ResponseWriter, r *http.Request)
+
+	Tags        []string
+	Summary     string
+	Description string
+	Parameters  []openapi.Parameter
+	Responses   map[int]apiResponse
+	Deprecated  bool
+}
+
+// apiResponse documents a single response of an api route.
+type apiResponse struct {
+	Description string
+
+	Body        reflect.Type    // go type of the json body, if any
+	Schema      *openapi.Schema // schema of the body, used instead of Body if not nil
+	ContentType string          // content type of the body, defaults to application/json
+
+	Alternative reflect.Type // go type of an alternative json body, if any
+
+	Lines reflect.Type // go type of each line of an alternative JSON Lines body, if any
+}
+
+// apiStatusResponse documents a response with a status body, as sent by [Server.handleNotFound] and friends.
+func apiStatusResponse(description, component string) apiResponse {
+	return apiResponse{Description: description, Schema: openapi.Ref(component)}
+}
+
+// Parameters of queries for a range of days, see [parseDaysQuery].
+var (
+	apiFromParameter    = openapi.Parameter{In: "query", Name: "from", Description: "Unix timestamp (seconds since epoch) of the first day of the range, defaults to 21 days ago", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(1)}, Example: 1682028000}
+	apiToParameter      = openapi.Parameter{In: "query", Name: "to", Description: "Unix timestamp (seconds since epoch) of the last day of the range, defaults to 27 days after from", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(1)}, Example: 1684360800}
+	apiOrderParameter   = openapi.Parameter{In: "query", Name: "order", Description: "Order of the days", Schema: &openapi.Schema{Type: "string", Enum: apiEnum("desc", "asc"), Default: "desc"}, Example: "asc"}
+	apiLimitParameter   = openapi.Parameter{In: "query", Name: "limit", Description: "Maximal number of days to return. Pages default to 28 days, JSON Lines are unlimited if omitted.", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(1), Maximum: openapi.Number(365)}, Example: 7}
+	apiAfterParameter   = openapi.Parameter{In: "query", Name: "after", Description: "Cursor returned as next by the previous page. The other parameters must be unchanged.", Schema: &openapi.Schema{Type: "string"}}
+	apiIncludeParameter = openapi.Parameter{In: "query", Name: "include", Description: "Set to items to include the items of each day", Schema: &openapi.Schema{Type: "string", Enum: apiEnum("items")}, Example: "items"}
+	apiDaysParameter    = openapi.Parameter{In: "query", Name: "days", Description: "Number of days of the range, clamped to 1 ... 365. Ignored if to is given, use to instead.", Schema: &openapi.Schema{Type: "integer"}, Example: 28, Deprecated: true}
+)
+
+// apiLocationParameter documents the location path parameter.
+var apiLocationParameter = openapi.Parameter{
+	In:          "path",
+	Name:        "location",
+	Description: "ID of the location",
+	Required:    true,
+	Schema:      &openapi.Schema{Type: "string"},
+	Example:     "mensa-sued",
+}
+
+// apiLocationDaysParameters are the parameters of all queries for a range of days at a single location.
+var apiLocationDaysParameters = []openapi.Parameter{
+	apiLocationParameter,
+	apiFromParameter,
+	apiToParameter,
+	apiDaysParameter,
+	apiOrderParameter,
+	apiLimitParameter,
+	apiAfterParameter,
+	apiIncludeParameter,
+}
+
+// apiRoutes are all routes of version 1 of the public api.
+var apiRoutes = []apiRoute{
+	{
+		Method:      http.MethodGet,
+		Path:        "/healthcheck",
+		Handler:     (*Server).handleAPIHealth,
+		Tags:        []string{"health"},
+		Summary:     "Check if the API is healthy",
+		Description: "Returns a healthy status if the database connection is active, otherwise returns an internal server error.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  apiStatusResponse("API is healthy", "HealthyStatus"),
+			http.StatusInternalServerError: apiStatusResponse("API is unhealthy", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/sync",
+		Handler:     (*Server).handleAPISync,
+		Tags:        []string{"sync"},
+		Summary:     "Fetches the last synchronization event",
+		Description: "Returns the last time menus were synced from the upstream server.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Last sync fetched successfully", Body: reflect.TypeFor[SyncEvent]()},
+			http.StatusInternalServerError: apiStatusResponse("Unable to get last sync", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/locations",
+		Handler:     (*Server).handleAPILocations,
+		Tags:        []string{"locations"},
+		Summary:     "List all available locations",
+		Description: "Get a list of available locations. Returned in consistent order.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "List succeeded", Body: reflect.TypeFor[[]location.Location]()},
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/locations/nearby",
+		Handler:     (*Server).handleAPINearby,
+		Tags:        []string{"locations"},
+		Summary:     "List locations by distance",
+		Description: "Get a list of locations with known coordinates, sorted by distance to the given coordinates. Each location includes a summary of the menu of the current day.",
+		Parameters: []openapi.Parameter{
+			{In: "query", Name: "lat", Description: "Latitude to compute distances to", Required: true, Schema: &openapi.Schema{Type: "number", Minimum: openapi.Number(-90), Maximum: openapi.Number(90)}, Example: 49.5803},
+			{In: "query", Name: "lon", Description: "Longitude to compute distances to", Required: true, Schema: &openapi.Schema{Type: "number", Minimum: openapi.Number(-180), Maximum: openapi.Number(180)}, Example: 11.029},
+			{In: "query", Name: "limit", Description: "Maximal number of locations to return, unlimited if omitted or 0", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(0)}, Example: 3},
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "List succeeded", Body: reflect.TypeFor[[]NearbyLocation]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid coordinates", "BadRequestError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/menu",
+		Handler:     (*Server).handleAPIMenus,
+		Tags:        []string{"menu"},
+		Summary:     "Return the menus of several locations in a range of days",
+		Description: "Returns the days with a menu at the given locations in the given range, sorted by day and then in the order of the locations. The range may contain at most 365 days. If there are more days than the limit, the response contains a cursor to pass as the after parameter to get the next page. If the Accept header includes application/jsonl, the days are streamed as JSON Lines instead.",
+		Parameters: []openapi.Parameter{
+			{In: "query", Name: "location", Description: "IDs of the locations, defaults to all locations", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}, Example: []string{"mensa-sued", "cafeteria-come-in"}},
+			apiFromParameter,
+			apiToParameter,
+			apiOrderParameter,
+			apiLimitParameter,
+			apiAfterParameter,
+			apiIncludeParameter,
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Menus listed successfully", Body: reflect.TypeFor[MenusPage](), Lines: reflect.TypeFor[LocationMenuDay]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid parameters", "BadRequestError"),
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/days/{location}",
+		Handler:     (*Server).handleAPIDays,
+		Tags:        []string{"menu"},
+		Summary:     "Return a page of days with a menu",
+		Description: "Returns the days with a menu in the given range, along with the number of items on each day and optionally the items themselves. The range may contain at most 365 days. If there are more days than the limit, the response contains a cursor to pass as the after parameter to get the next page. If the Accept header includes application/jsonl, the days are streamed as JSON Lines instead.",
+		Parameters:  apiLocationDaysParameters,
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Days listed successfully", Body: reflect.TypeFor[MenuDays](), Lines: reflect.TypeFor[LocationMenuDay]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid parameters", "BadRequestError"),
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/menu/{location}",
+		Handler:     (*Server).handleAPIMenuDays,
+		Tags:        []string{"menu"},
+		Summary:     "Return a list of available menu times.",
+		Description: "Deprecated, use /days/{location} instead. Takes the same parameters and returns the same responses as /days/{location}, except that the plain list of available dates is returned, with the newest first, unless any of to, order, limit, after or include is given.",
+		Parameters:  apiLocationDaysParameters,
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Available dates, newest first, or a page of days if range parameters are given", Body: reflect.TypeFor[[]ltime.Day](), Alternative: reflect.TypeFor[MenuDays](), Lines: reflect.TypeFor[LocationMenuDay]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid parameters", "BadRequestError"),
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+		Deprecated: true,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/menu/{location}/{day}",
+		Handler:     (*Server).handleAPIMenu,
+		Tags:        []string{"menu"},
+		Summary:     "Return the menu for the given location and day",
+		Description: "Returns the menu for the given day",
+		Parameters: []openapi.Parameter{
+			apiLocationParameter,
+			{In: "path", Name: "day", Description: "Unix timestamp (seconds since epoch) of the day to get the menu for", Required: true, Schema: &openapi.Schema{Type: "integer"}, Example: 1682028000},
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Available menu items", Body: reflect.TypeFor[[]MenuItem]()},
+			http.StatusNotFound:            apiStatusResponse("Location or day Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("Getting menu failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/annotations",
+		Handler:     (*Server).handleAPIAnnotations,
+		Tags:        []string{"annotations"},
+		Summary:     "Lists all known annotations",
+		Description: "Returns all known allergens, additives and ingredients with their meaning. Allergens are mapped to the EU allergen groups, additives to their E-number ranges. Clients can use this to render the legend of a menu.",
+		Responses: map[int]apiResponse{
+			http.StatusOK: {Description: "Catalogue returned successfully", Body: reflect.TypeFor[annotations.Catalogue]()},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/sqlite",
+		Handler:     (*Server).handleAPIsqlite,
+		Tags:        []string{"export"},
+		Summary:     "Downloads the database",
+		Description: "Returns a consistent copy of the entire sqlite database. The copy is cached on the server until the next synchronization.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Database returned successfully", Schema: &openapi.Schema{Type: "string", Format: "binary"}, ContentType: "application/x-sqlite3"},
+			http.StatusNotFound:            apiStatusResponse("Export disabled", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("Export failed", "InternalServerError"),
+		},
+	},
+}
+
+// apiTags are the tags of version 1 of the public api.
+var apiTags = []openapi.Tag{
+	{Name: "locations", Description: "List available locations"},
+	{Name: "menu", Description: "Access menus"},
+	{Name: "sync", Description: "Access meta information about synchronization"},
+	{Name: "health", Description: "Health check endpoints"},
+	{Name: "annotations", Description: "Describe the annotations used in menus"},
+	{Name: "export", Description: "Export the database"},
+}
+
+// apiStatuses are the bodies sent by handlers without any data, by component name.
+var apiStatuses = []struct {
+	Component   string
+	Description string
+	Body        string
+}{
+	{"HealthyStatus", "A status indicating that the API is healthy", statusHealthy},
+	{"InternalServerError", "An error indicating that an internal server error occurred", internalServerError},
+	{"NotFoundError", "An error indicating that the value was not found", notFoundError},
+	{"BadRequestError", "An error indicating that the request was invalid", badRequestError},
+}
+
+// jsonTypeOf returns the type of a [datatypes.JSONType] holding T, documented as T.
+func jsonTypeOf[T any]() (reflect.Type, openapi.Type) {
+	return reflect.TypeFor[datatypes.JSONType[T]](), openapi.Type{As: reflect.TypeFor[T]()}
+}
+
+// apiTypes documents the go types used in responses of version 1 of the public api.
+func apiTypes() map[reflect.Type]openapi.Type {
+	dayDoc := openapi.Type{Description: "Unix timestamp (seconds since epoch) of a day", Example: 1682028000}
+	htmlField := func(field string) openapi.Field {
+		return openapi.Field{Description: "html version of the " + field + " field with annotation references surrounded by spans and inline links to appropriate annotation tables"}
+	}
+
+	types := map[reflect.Type]openapi.Type{
+		reflect.TypeFor[ltime.Day]():     dayDoc,
+		reflect.TypeFor[template.HTML](): {},
+
+		reflect.TypeFor[location.Location](): {
+			Name:        "Location",
+			As:          reflect.TypeFor[location.LocationJSON](),
+			Description: "A single FAULunch location",
+			Fields: map[string]openapi.Field{
+				"id":             {Description: "ID of the location", Example: "mensa-sued"},
+				"Name":           {Description: "Name of the location", Example: "Südmensa"},
+				"Refactory":      {Description: "Is this location a full refactory?", Example: true},
+				"Cafe":           {Description: "Is this location a cafe?", Example: false},
+				"Internal":       {Description: "Does this location accept specific visitor only?", Example: false},
+				"Street":         {Description: "Street name part of the address", Example: "Erwin-Rommel-Straße"},
+				"StreetNo":       {Description: "Street number part of the address", Example: "60"},
+				"ZIP":            {Description: "ZIP code of the address", Example: "91058"},
+				"City":           {Description: "City of the address", Example: "Erlangen"},
+				"Latitude":       {Description: "Approximate latitude of the address, 0 if unknown", Example: 49.5803},
+				"Longitude":      {Description: "Approximate longitude of the address, 0 if unknown", Example: 11.029},
+				"Hours":          {Description: "Regular opening hours"},
+				"BreakHours":     {Description: "Opening hours during semester breaks, same as Hours if empty"},
+				"Closures":       {Description: "Periods where the location is closed, such as holidays"},
+				"SemesterBreaks": {Description: "Lecture-free periods, where BreakHours apply instead of Hours"},
+			},
+		},
+		reflect.TypeFor[location.Weekly](): {
+			Name:        "WeeklyHours",
+			As:          reflect.TypeFor[map[string][]location.TimeRange](),
+			Description: "Opening hours per weekday, keyed by lowercase english weekday name. Closed days are omitted. Empty if unknown.",
+			Example:     map[string]any{"monday": []any{map[string]any{"open": "11:00", "close": "14:00"}}},
+		},
+		reflect.TypeFor[location.TimeRange](): {
+			Description: "A range of time within a day",
+			Fields: map[string]openapi.Field{
+				"open":  {Example: "11:00"},
+				"close": {Example: "14:00"},
+			},
+		},
+		reflect.TypeFor[location.Period](): {
+			Description: "A period of days, including both ends",
+			Fields: map[string]openapi.Field{
+				"from": {Schema: &openapi.Schema{Type: "string", Format: "date"}, Example: "2026-07-25"},
+				"to":   {Schema: &openapi.Schema{Type: "string", Format: "date"}, Example: "2026-10-11"},
+			},
+		},
+		reflect.TypeFor[location.Closure](): {
+			Description: "A period of days (including both ends) where a location is closed",
+			Fields: map[string]openapi.Field{
+				"from":     {Schema: &openapi.Schema{Type: "string", Format: "date"}, Example: "2026-12-24"},
+				"to":       {Schema: &openapi.Schema{Type: "string", Format: "date"}, Example: "2027-01-06"},
+				"reasonDE": {Example: "Weihnachtsferien"},
+				"reasonEN": {Example: "Christmas break"},
+			},
+		},
+
+		reflect.TypeFor[MenuItem](): {
+			Description: "A single item on a menu",
+			Fields: map[string]openapi.Field{
+				"Category":              {Description: "German (and machine name) for the line within the location where the menu item is available.", Example: "Essen 1"},
+				"CategoryEN":            {Description: "English version of Category (automatically translated)", Example: "Meal 1"},
+				"TitleDE":               {Description: "The German title of the food item", Example: "Apfelstrudel (1,7,Wz,Mi) mit Vanillesoße (Mi)"},
+				"TitleEN":               {Description: "The English title of the food item", Example: "Apple strudel (1,7,Wz,Mi) with vanilla sauce (Mi)"},
+				"DescriptionDE":         {Description: "The German description of the food item"},
+				"DescriptionEN":         {Description: "The English description of the food item"},
+				"BeilagenDE":            {Description: "The German side dishes of the food item"},
+				"BeilagenEN":            {Description: "The English side dishes of the food item"},
+				"Preis1":                {Description: "Price of the item for students in euros.", Example: 2.28},
+				"Preis2":                {Description: "Price of the item for employees in euros.", Example: 3.8},
+				"Preis3":                {Description: "Price of the item for guests in euros.", Example: 4.56},
+				"Piktogramme":           {Description: "Ingredients of the item. Typically displayed as pictograms."},
+				"Kj":                    {Description: "The amount of energy in kilo jules", Example: 3690},
+				"Kcal":                  {Description: "The amount of energy in kilo calories", Example: 881},
+				"Fett":                  {Description: "amount of fat in grams", Example: 41.6},
+				"Gesfett":               {Description: "amount of saturated fatty acids in grams", Example: 23.6},
+				"Kh":                    {Description: "amount of carbohydrates in grams", Example: 107.9},
+				"Zucker":                {Description: "amount of sugar in grams", Example: 56.9},
+				"Ballaststoffe":         {Description: "amount of dietary fibre in grams", Example: 0},
+				"Eiweiss":               {Description: "amount of protein in grams", Example: 14.1},
+				"Salz":                  {Description: "amount of salt in grams", Example: 1.2},
+				"GlutenFree":            {Description: "is the menu item gluten free"},
+				"DietaryCategory":       {Description: "the dietary category of the menu item"},
+				"DietaryUnknown":        {Description: "true if no pictograms are known for the menu item, or some of its annotations or pictograms are unknown. In this case HalalCompatible and the free-from labels are false, and the meat labels are only set if pictograms are known."},
+				"ContainsPork":          {Description: "does the menu item contain pork according to its pictograms"},
+				"ContainsBeef":          {Description: "does the menu item contain beef according to its pictograms"},
+				"ContainsPoultry":       {Description: "does the menu item contain poultry according to its pictograms"},
+				"ContainsLamb":          {Description: "does the menu item contain lamb according to its pictograms"},
+				"ContainsGame":          {Description: "does the menu item contain game according to its pictograms"},
+				"LactoseFree":           {Description: "are allergens declared for the menu item, all of its annotations known and no milk allergen declared"},
+				"EggFree":               {Description: "are allergens declared for the menu item, all of its annotations known and no egg allergen declared"},
+				"NutFree":               {Description: "are allergens declared for the menu item, all of its annotations known and neither nut nor peanut allergens declared"},
+				"HalalCompatible":       {Description: "are pictograms and all annotations known for the menu item and neither pork nor alcohol declared"},
+				"Edited":                {Description: "has this menu item been manually added or changed by an administrator"},
+				"HTMLTitleDE":           htmlField("TitleDE"),
+				"HTMLTitleEN":           htmlField("TitleEN"),
+				"HTMLDescriptionDE":     htmlField("DescriptionDE"),
+				"HTMLDescriptionEN":     htmlField("DescriptionEN"),
+				"HTMLBeilagenDE":        htmlField("BeilagenDE"),
+				"HTMLBeilagenEN":        htmlField("BeilagenEN"),
+				"AllergenAnnotations":   {Description: "Allergens found in item description."},
+				"EUAllergens":           {Description: "EU allergen groups (Regulation (EU) No 1169/2011, Annex II) of the allergens found in the item description, in the order of Annex II."},
+				"AdditiveAnnotations":   {Description: "Additives found in item description."},
+				"IngredientAnnotations": {Description: "Ingredients found in item description or pictogram field."},
+			},
+		},
+		reflect.TypeFor[MenuSummary](): {
+			Description: "A short summary of a menu item. See MenuItem for a description of the fields.",
+			Fields: map[string]openapi.Field{
+				"Category":   {Example: "Essen 1"},
+				"CategoryEN": {Example: "Meal 1"},
+				"Preis1":     {Example: 2.28},
+				"Preis2":     {Example: 3.8},
+				"Preis3":     {Example: 4.56},
+			},
+		},
+		reflect.TypeFor[NearbyLocation](): {
+			Description: "A location along with its distance and a summary of its menu",
+			Fields: map[string]openapi.Field{
+				"distance": {Description: "Distance to the requested coordinates in meters", Example: 1234.5},
+				"day":      {Description: "Unix timestamp (seconds since epoch) of the day of the menu"},
+				"items":    {Description: "Summary of the menu on the given day, empty if there is none"},
+			},
+		},
+		reflect.TypeFor[MenuDays](): {
+			Description: "A page of days with a menu at a location",
+			Fields: map[string]openapi.Field{
+				"location": {Description: "ID of the location", Example: "mensa-sued"},
+				"days":     {Description: "Days with a menu, in the requested order"},
+				"next":     {Description: "Cursor of the next page, omitted on the last page", Example: "1682028000"},
+			},
+		},
+		reflect.TypeFor[MenusPage](): {
+			Description: "A page of days with a menu at several locations",
+			Fields: map[string]openapi.Field{
+				"menus": {Description: "Days with a menu, in the requested order"},
+				"next":  {Description: "Cursor of the next page, omitted on the last page", Example: "1682028000"},
+			},
+		},
+		reflect.TypeFor[LocationMenuDay](): {
+			Description: "A day with a menu at a location",
+			Fields: map[string]openapi.Field{
+				"location": {Description: "ID of the location", Example: "mensa-sued"},
+				"count":    {Description: "Number of items on the menu", Example: 12},
+				"items":    {Description: "Items on the menu, only included if requested"},
+			},
+		},
+		reflect.TypeFor[MenuDay](): {
+			Description: "A day with a menu",
+			Fields: map[string]openapi.Field{
+				"count": {Description: "Number of items on the menu", Example: 12},
+				"items": {Description: "Items on the menu, only included if requested"},
+			},
+		},
+		reflect.TypeFor[DietaryCategory](): {
+			Schema:  &openapi.Schema{Type: "string", Enum: apiEnum(DietaryCategories()...)},
+			Example: "meat",
+		},
+
+		reflect.TypeFor[SyncEvent](): {
+			Description: "An event representing a synchronization with the upstream server",
+			Fields: map[string]openapi.Field{
+				"start": {Description: "Unix timestamp (seconds since epoch) when the synchronization was started", Example: 1683704244},
+				"stop":  {Description: "Unix timestamp (seconds since epoch) when the synchronization was stopped", Example: 1683704246},
+			},
+		},
+		reflect.TypeFor[SyncReport](): {
+			Description: "Report of a synchronization. Events stored before reports were introduced have no warnings.",
+		},
+		reflect.TypeFor[SyncWarning](): {
+			Description: "A problem with the upstream data found during synchronization. Data affected by an `unpaired-item` warning is stored with only one language, other affected data is not stored.",
+			Fields: map[string]openapi.Field{
+				"location":  {Description: "Slug of the affected location, if known", Example: "mensa-sued"},
+				"feedID":    {Description: "Location id of the german plan", Example: 1},
+				"feedIDEN":  {Description: "Location id of the english plan, if different from the german one"},
+				"day":       {Description: "Unix timestamp of the affected day, if any"},
+				"category":  {Description: "Category of the affected item, if any", Example: "Suppe"},
+				"title":     {Description: "Title of the affected item, if any"},
+				"missingEN": {Description: "For `missing-day` and `unpaired-item`, true if the day or item is missing from the english plan, false if it is missing from the german plan"},
+				"message":   {Description: "Human-readable description of the problem"},
+			},
+		},
+		reflect.TypeFor[SyncWarningKind](): {
+			Schema: &openapi.Schema{
+				Type: "string",
+				Enum: apiEnum(WarningUnknownLocation, WarningLocationMismatch, WarningMissingDay, WarningDuplicateDay, WarningUnpairedItem),
+				Description: "Kind of problem:\n\n" +
+					"* `unknown-location` - the plans refer to a location not in the registry\n" +
+					"* `location-mismatch` - the german and english plans refer to different locations\n" +
+					"* `missing-day` - a day is only present in one language\n" +
+					"* `unpaired-item` - an item has no counterpart in the other language, it is stored with only one language\n",
+			},
+		},
+
+		reflect.TypeFor[annotations.Catalogue](): {Name: "AnnotationCatalogue", Description: "All known annotations in display order"},
+		reflect.TypeFor[annotations.AllergenEntry](): {
+			Description: "An allergen along with its meaning and EU allergen group",
+			Fields:      map[string]openapi.Field{"de": {Description: "German meaning"}, "en": {Description: "English meaning"}},
+		},
+		reflect.TypeFor[annotations.EUAllergenEntry](): {
+			Description: "An EU allergen group along with its meaning",
+			Fields:      map[string]openapi.Field{"number": {Description: "Number of the group in Annex II", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(1), Maximum: openapi.Number(14)}}, "de": {Description: "German meaning"}, "en": {Description: "English meaning"}},
+		},
+		reflect.TypeFor[annotations.AdditiveEntry](): {
+			Description: "An additive along with its meaning and E-number ranges",
+			Fields: map[string]openapi.Field{
+				"de":       {Description: "German meaning"},
+				"en":       {Description: "English meaning"},
+				"eNumbers": {Description: "E-number ranges of the declared substances. Empty for additives not declared by E-numbers, such as caffeine."},
+			},
+		},
+		reflect.TypeFor[annotations.IngredientEntry](): {
+			Description: "An ingredient along with its meaning",
+			Fields:      map[string]openapi.Field{"de": {Description: "German meaning"}, "en": {Description: "English meaning"}},
+		},
+		reflect.TypeFor[annotations.ENumberRange](): {
+			Description: "An inclusive range of E-numbers. A single E-number has equal from and to.",
+			Fields: map[string]openapi.Field{
+				"from": {Description: "First E-number of the range, without the E prefix", Example: 220},
+				"to":   {Description: "Last E-number of the range, without the E prefix", Example: 228},
+			},
+		},
+	}
+
+	// annotations are documented using the catalogue
+	catalogue := annotations.NewCatalogue()
+	types[reflect.TypeFor[annotations.Allergen]()] = apiAnnotationType("Allergen", "An allergen of an item. The abbreviations mean:", catalogue.Allergens, func(e annotations.AllergenEntry) (string, string) { return string(e.ID), e.EN })
+	types[reflect.TypeFor[annotations.Additive]()] = apiAnnotationType("Additive", "An additive of an item. The numbers mean:", catalogue.Additives, func(e annotations.AdditiveEntry) (string, string) { return string(e.ID), e.EN })
+	types[reflect.TypeFor[annotations.Ingredient]()] = apiAnnotationType("Ingredient", "An ingredient of an item. The abbreviations mean:", catalogue.Ingredients, func(e annotations.IngredientEntry) (string, string) { return string(e.ID), e.EN })
+	types[reflect.TypeFor[annotations.EUAllergen]()] = apiAnnotationType("EUAllergen", "One of the 14 allergen groups that must be declared according to Annex II of Regulation (EU) No 1169/2011:", catalogue.EUAllergens, func(e annotations.EUAllergenEntry) (string, string) { return string(e.ID), e.EN })
+
+	for _, add := range []func() (reflect.Type, openapi.Type){
+		jsonTypeOf[[]annotations.Allergen],
+		jsonTypeOf[[]annotations.Additive],
+		jsonTypeOf[[]annotations.Ingredient],
+		jsonTypeOf[[]annotations.EUAllergen],
+	} {
+		t, doc := add()
+		types[t] = doc
+	}
+
+	return types
+}
+
+// apiEnum returns the given values for use as [openapi.Schema.Enum].
+func apiEnum[T any](values ...T) []any {
+	enum := make([]any, len(values))
+	for i, value := range values {
+		enum[i] = value
+	}
+	return enum
+}
+
+// apiAnnotationType documents an annotation type using the entries of the catalogue.
+func apiAnnotationType[Entry any](name, intro string, entries []Entry, describe func(Entry) (id, meaning string)) openapi.Type {
+	var description strings.Builder
+	description.WriteString(intro + "\n\n")
+
+	enum := make([]any, len(entries))
+	for i, entry := range entries {
+		id, meaning := describe(entry)
+		enum[i] = id
+		fmt.Fprintf(&description, "* `%s` - %s\n", id, meaning)
+	}
+	return openapi.Type{
+		Name:   name,
+		Schema: &openapi.Schema{Type: "string", Enum: enum, Description: description.String()},
+	}
+}
+
+// APIDocument returns the openapi document describing version 1 of the public api.
+// It is generated from the api routes and the go types of their responses.
+func APIDocument() (*openapi.Document, error) {
+	return apiV1.document()
+}
+
+// APIDocumentV2 returns the openapi document describing version 2 of the public api, see [APIDocument].
+func APIDocumentV2() (*openapi.Document, error) {
+	return apiV2.document()
+}
+
+// document returns the openapi document of this version, generating it on first use.
+func (version *apiVersion) document() (*openapi.Document, error) {
+	version.once.Do(func() {
+		version.doc, version.err = version.newDocument()
+	})
+	return version.doc, version.err
+}
+
+var pathParameterRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+func (version *apiVersion) newDocument() (*openapi.Document, error) {
+	reflector := &openapi.Reflector{Types: version.Types()}
+	for _, status := range apiStatuses {
+		schema := &openapi.Schema{
+			Type:        "object",
+			Description: status.Description,
+			Required:    []string{"status"},
+			Properties: map[string]*openapi.Schema{
+				"status": {Type: "string", Enum: []any{strings.TrimSuffix(strings.TrimPrefix(status.Body, `{"status":"`), `"}`)}},
+			},
+		}
+		if err := reflector.AddComponent(status.Component, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    version.Info,
+		Servers: []openapi.Server{{URL: version.Base}},
+		Tags:    version.Tags,
+		Paths:   make(map[string]openapi.PathItem),
+	}
+
+	for _, route := range version.Routes {
+		op, err := route.operation(reflector, version.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s%s: %w", route.Method, version.Base, route.Path, err)
+		}
+
+		item := doc.Paths[route.Path]
+		if item == nil {
+			item = make(openapi.PathItem)
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	doc.Components.Schemas = reflector.Components()
+	return doc, nil
+}
+
+// operation generates the openapi operation of this route.
+func (route apiRoute) operation(reflector *openapi.Reflector, tags []openapi.Tag) (*openapi.Operation, error) {
+	// check that path parameters are documented
+	var inPath []string
+	for _, match := range pathParameterRegexp.FindAllStringSubmatch(route.Path, -1) {
+		inPath = append(inPath, match[1])
+	}
+	var documented []string
+	for _, param := range route.Parameters {
+		if param.In == "path" {
+			documented = append(documented, param.Name)
+		}
+	}
+	if !slices.Equal(inPath, documented) {
+		return nil, fmt.Errorf("path parameters %v do not match documented parameters %v", inPath, documented)
+	}
+
+	for _, tag := range route.Tags {
+		if !slices.ContainsFunc(tags, func(t openapi.Tag) bool { return t.Name == tag }) {
+			return nil, fmt.Errorf("unknown tag %q", tag)
+		}
+	}
+
+	op := &openapi.Operation{
+		Tags:        route.Tags,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Parameters:  route.Parameters,
+		Responses:   make(map[string]*openapi.Response, len(route.Responses)),
+		Deprecated:  route.Deprecated,
+	}
+	for status, response := range route.Responses {
+		schema := response.Schema
+		if schema == nil && response.Body != nil {
+			var err error
+			schema, err = reflector.Schema(response.Body)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if schema != nil && response.Alternative != nil {
+			alternative, err := reflector.Schema(response.Alternative)
+			if err != nil {
+				return nil, err
+			}
+			schema = &openapi.Schema{OneOf: []*openapi.Schema{schema, alternative}}
+		}
+
+		contentType := response.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		res := &openapi.Response{Description: response.Description}
+		if schema != nil {
+			res.Content = map[string]openapi.MediaType{contentType: {Schema: schema}}
+		}
+		if response.Lines != nil {
+			lines, err := reflector.Schema(response.Lines)
+			if err != nil {
+				return nil, err
+			}
+			if res.Content == nil {
+				res.Content = make(map[string]openapi.MediaType, 1)
+			}
+			res.Content[openapi.JSONLines] = openapi.MediaType{Schema: lines}
+		}
+		op.Responses[fmt.Sprint(status)] = res
+	}
+	return op, nil
+}
@@ -0,0 +1,766 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words encoding json errors http reflect strconv strings time github faulunch apiv2 internal annotations location ltime openapi gorm
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkw1536/faulunch/apiv2"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/openapi"
+	"gorm.io/gorm"
+)
+
+// apiV2 is version 2 of the public api.
+// Its schema is defined by the [apiv2] package and independent of the database.
+var apiV2 = &apiVersion{
+	Base:     "/api/v2",
+	Document: "/api/v2/openapi.json",
+	Explorer: "/api/v2/",
+
+	Info: openapi.Info{
+		Title:       "FauLunch API v2",
+		Description: "This API provides information about the current, past and future menus of serveries related to FAU. Unlike version 1, it uses a schema independent of the database, with localized texts, structured annotations and ISO 8601 dates.",
+		Version:     "2.0.0",
+	},
+	Tags: []openapi.Tag{
+		{Name: "locations", Description: "List available locations"},
+		{Name: "menu", Description: "Access menus"},
+		{Name: "sync", Description: "Access meta information about synchronization"},
+	},
+	Routes: apiV2Routes,
+	Types:  apiV2Types,
+}
+
+// apiV2LocationParameter documents the location path parameter of version 2.
+var apiV2LocationParameter = openapi.Parameter{
+	In:          "path",
+	Name:        "location",
+	Description: "ID of the location",
+	Required:    true,
+	Schema:      &openapi.Schema{Type: "string"},
+	Example:     "mensa-sued",
+}
+
+// apiV2DateSchema is the schema of a date parameter.
+var apiV2DateSchema = &openapi.Schema{Type: "string", Format: "date"}
+
+// apiV2Routes are all routes of version 2 of the public api.
+var apiV2Routes = []apiRoute{
+	{
+		Method:      http.MethodGet,
+		Path:        "/sync",
+		Handler:     (*Server).handleAPIv2Sync,
+		Tags:        []string{"sync"},
+		Summary:     "Fetches the last synchronization event",
+		Description: "Returns the last time menus were synced from the upstream server, along with any problems found.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Last sync fetched successfully", Body: reflect.TypeFor[apiv2.SyncEvent]()},
+			http.StatusNotFound:            apiStatusResponse("Menus were never synced", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("Unable to get last sync", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/locations",
+		Handler:     (*Server).handleAPIv2Locations,
+		Tags:        []string{"locations"},
+		Summary:     "List all available locations",
+		Description: "Get a list of locations with at least one menu. Returned in consistent order.",
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "List succeeded", Body: reflect.TypeFor[[]apiv2.Location]()},
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/locations/nearby",
+		Handler:     (*Server).handleAPIv2Nearby,
+		Tags:        []string{"locations"},
+		Summary:     "List locations by distance",
+		Description: "Get a list of locations with known coordinates, sorted by distance to the given coordinates, nearest first. Each location includes the menu of the current day.",
+		Parameters: []openapi.Parameter{
+			{In: "query", Name: "lat", Description: "Latitude to compute distances to", Required: true, Schema: &openapi.Schema{Type: "number", Minimum: openapi.Number(-90), Maximum: openapi.Number(90)}, Example: 49.5803},
+			{In: "query", Name: "lon", Description: "Longitude to compute distances to", Required: true, Schema: &openapi.Schema{Type: "number", Minimum: openapi.Number(-180), Maximum: openapi.Number(180)}, Example: 11.029},
+			{In: "query", Name: "limit", Description: "Maximal number of locations to return, unlimited if omitted or 0", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(0)}, Example: 3},
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "List succeeded", Body: reflect.TypeFor[[]apiv2.NearbyLocation]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid coordinates", "BadRequestError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/locations/{location}",
+		Handler:     (*Server).handleAPIv2Location,
+		Tags:        []string{"locations"},
+		Summary:     "Describe a single location",
+		Description: "Returns the description of a single location.",
+		Parameters:  []openapi.Parameter{apiV2LocationParameter},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Location found", Body: reflect.TypeFor[apiv2.Location]()},
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("Getting location failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/menu/{location}",
+		Handler:     (*Server).handleAPIv2MenuDays,
+		Tags:        []string{"menu"},
+		Summary:     "List the days with a menu",
+		Description: "Returns the days with a menu in the given range, newest first. The range may contain at most 365 days.",
+		Parameters: []openapi.Parameter{
+			apiV2LocationParameter,
+			{In: "query", Name: "from", Description: "First day of the range, defaults to 21 days ago", Schema: apiV2DateSchema, Example: "2023-05-01"},
+			{In: "query", Name: "to", Description: "Last day of the range, defaults to 27 days after from", Schema: apiV2DateSchema, Example: "2023-05-28"},
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Days listed successfully", Body: reflect.TypeFor[apiv2.Days]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid range", "BadRequestError"),
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("List failed", "InternalServerError"),
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/menu/{location}/{date}",
+		Handler:     (*Server).handleAPIv2Menu,
+		Tags:        []string{"menu"},
+		Summary:     "Return the menu for the given location and day",
+		Description: "Returns the menu of the given day. The menu is empty if there is no menu on the given day.",
+		Parameters: []openapi.Parameter{
+			apiV2LocationParameter,
+			{In: "path", Name: "date", Description: "Day to get the menu for", Required: true, Schema: apiV2DateSchema, Example: "2023-05-10"},
+		},
+		Responses: map[int]apiResponse{
+			http.StatusOK:                  {Description: "Menu returned successfully", Body: reflect.TypeFor[apiv2.Menu]()},
+			http.StatusBadRequest:          apiStatusResponse("Invalid date", "BadRequestError"),
+			http.StatusNotFound:            apiStatusResponse("Location Not Found", "NotFoundError"),
+			http.StatusInternalServerError: apiStatusResponse("Getting menu failed", "InternalServerError"),
+		},
+	},
+}
+
+// apiV2Types documents the go types used in responses of version 2 of the public api.
+func apiV2Types() map[reflect.Type]openapi.Type {
+	date := openapi.Field{Schema: apiV2DateSchema}
+	dateTime := openapi.Field{Schema: &openapi.Schema{Type: "string", Format: "date-time"}}
+
+	catalogue := annotations.NewCatalogue()
+	var allergens, euAllergens, additives, ingredients []any
+	for _, a := range catalogue.Allergens {
+		allergens = append(allergens, string(a.ID))
+	}
+	for _, e := range catalogue.EUAllergens {
+		euAllergens = append(euAllergens, string(e.ID))
+	}
+	for _, a := range catalogue.Additives {
+		additives = append(additives, string(a.ID))
+	}
+	for _, i := range catalogue.Ingredients {
+		ingredients = append(ingredients, string(i.ID))
+	}
+
+	return map[reflect.Type]openapi.Type{
+		reflect.TypeFor[apiv2.Localized](): {
+			Description: "A text in german and english",
+		},
+
+		reflect.TypeFor[apiv2.Location](): {
+			Description: "A single FAULunch location",
+			Fields: map[string]openapi.Field{
+				"id":             {Description: "ID of the location", Example: "mensa-sued"},
+				"name":           {Description: "Name of the location", Example: "Südmensa"},
+				"kind":           {Schema: &openapi.Schema{Type: "string", Enum: apiEnum(apiv2.KindServery, apiv2.KindCafe, apiv2.KindInternal, apiv2.KindOther)}, Description: "Kind of the location"},
+				"coordinates":    {Description: "Approximate coordinates of the address, null if unknown"},
+				"hours":          {Description: "Regular opening hours, empty if unknown"},
+				"breakHours":     {Description: "Opening hours during semester breaks, same as hours if empty"},
+				"closures":       {Description: "Periods where the location is closed, such as holidays"},
+				"semesterBreaks": {Description: "Lecture-free periods, where breakHours apply instead of hours"},
+			},
+		},
+		reflect.TypeFor[apiv2.Address](): {
+			Description: "Postal address of a location",
+			Fields: map[string]openapi.Field{
+				"street": {Example: "Erwin-Rommel-Straße"},
+				"number": {Example: "60"},
+				"zip":    {Example: "91058"},
+				"city":   {Example: "Erlangen"},
+			},
+		},
+		reflect.TypeFor[apiv2.Coordinates](): {
+			Description: "Approximate coordinates of a location",
+			Fields: map[string]openapi.Field{
+				"latitude":  {Example: 49.5803},
+				"longitude": {Example: 11.029},
+			},
+		},
+		reflect.TypeFor[apiv2.Hours](): {
+			Name:        "Hours",
+			Description: "Opening hours per weekday, keyed by lowercase english weekday name. Closed days are omitted.",
+			Example:     map[string]any{"monday": []any{map[string]any{"open": "11:00", "close": "14:00"}}},
+		},
+		reflect.TypeFor[apiv2.TimeRange](): {
+			Description: "A range of time within a day",
+			Fields: map[string]openapi.Field{
+				"open":  {Example: "11:00"},
+				"close": {Example: "14:00"},
+			},
+		},
+		reflect.TypeFor[apiv2.Period](): {
+			Description: "A period of days, including both ends",
+			Fields: map[string]openapi.Field{
+				"from": {Schema: apiV2DateSchema, Example: "2026-07-25"},
+				"to":   {Schema: apiV2DateSchema, Example: "2026-10-11"},
+			},
+		},
+		reflect.TypeFor[apiv2.Closure](): {
+			Description: "A period of days (including both ends) where a location is closed",
+			Fields: map[string]openapi.Field{
+				"from":   {Schema: apiV2DateSchema, Example: "2026-12-24"},
+				"to":     {Schema: apiV2DateSchema, Example: "2027-01-06"},
+				"reason": {Description: "Reason for the closure, empty if unknown"},
+			},
+		},
+		reflect.TypeFor[apiv2.NearbyLocation](): {
+			Description: "A location along with its distance and its menu",
+			Fields: map[string]openapi.Field{
+				"distance": {Description: "Distance to the requested coordinates in meters", Example: 1234.5},
+			},
+		},
+
+		reflect.TypeFor[apiv2.Days](): {
+			Description: "Days a location has a menu for",
+			Fields: map[string]openapi.Field{
+				"location": {Description: "ID of the location", Example: "mensa-sued"},
+				"days":     {Description: "Days with a menu, newest first", Schema: &openapi.Schema{Type: "array", Items: apiV2DateSchema}},
+			},
+		},
+		reflect.TypeFor[apiv2.Menu](): {
+			Description: "The menu of a location on a single day",
+			Fields: map[string]openapi.Field{
+				"location": {Description: "ID of the location", Example: "mensa-sued"},
+				"date":     date,
+				"items":    {Description: "Items on the menu, empty if there is no menu"},
+			},
+		},
+		reflect.TypeFor[apiv2.MenuItem](): {
+			Description: "A single item on a menu",
+			Fields: map[string]openapi.Field{
+				"category":    {Description: "Line within the location where the item is available. The english version is translated automatically."},
+				"title":       {Description: "Title of the item, including annotation references"},
+				"description": {Description: "Description of the item"},
+				"sides":       {Description: "Side dishes of the item"},
+				"allergens":   {Description: "Allergens declared for the item"},
+				"euAllergens": {Description: "EU allergen groups of the allergens, in the order of Annex II"},
+				"additives":   {Description: "Additives declared for the item"},
+				"ingredients": {Description: "Ingredients declared for the item, typically displayed as pictograms"},
+				"edited":      {Description: "Has the item been manually added or changed by an administrator"},
+			},
+		},
+		reflect.TypeFor[apiv2.Prices](): {
+			Description: "Prices of an item in euros",
+			Fields: map[string]openapi.Field{
+				"student":  {Example: 2.28},
+				"employee": {Example: 3.8},
+				"guest":    {Example: 4.56},
+			},
+		},
+		reflect.TypeFor[apiv2.Nutrition](): {
+			Description: "Nutritional values of an item. Amounts are in grams.",
+			Fields: map[string]openapi.Field{
+				"energyKJ":   {Description: "Energy in kilo joules", Example: 3690},
+				"energyKcal": {Description: "Energy in kilo calories", Example: 881},
+			},
+		},
+		reflect.TypeFor[apiv2.Diet](): {
+			Description: "Dietary properties of an item",
+			Fields: map[string]openapi.Field{
+				"category":        {Schema: &openapi.Schema{Type: "string", Enum: apiEnum(DietaryCategories()...)}, Example: "meat"},
+				"unknown":         {Description: "True if no pictograms are known for the item, or some of its annotations are unknown. In this case halalCompatible and the free-from properties are false, and meat is only set if pictograms are known."},
+				"meat":            {Description: "Kinds of meat according to the pictograms", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: apiEnum(apiv2.MeatPork, apiv2.MeatBeef, apiv2.MeatPoultry, apiv2.MeatLamb, apiv2.MeatGame)}}},
+				"glutenFree":      {Description: "No gluten containing cereals are declared"},
+				"lactoseFree":     {Description: "Allergens are declared, all annotations are known and no milk allergen is declared"},
+				"eggFree":         {Description: "Allergens are declared, all annotations are known and no egg allergen is declared"},
+				"nutFree":         {Description: "Allergens are declared, all annotations are known and no nut or peanut allergens are declared"},
+				"halalCompatible": {Description: "Pictograms and all annotations are known and neither pork nor alcohol is declared"},
+			},
+		},
+		reflect.TypeFor[apiv2.Allergen](): {
+			Description: "An allergen along with its meaning",
+			Fields: map[string]openapi.Field{
+				"code": {Schema: &openapi.Schema{Type: "string", Enum: allergens}, Example: "Wz"},
+				"eu":   {Schema: &openapi.Schema{Type: "string", Enum: euAllergens}, Description: "ID of the EU allergen group", Example: "gluten"},
+			},
+		},
+		reflect.TypeFor[apiv2.EUAllergen](): {
+			Description: "One of the 14 allergen groups that must be declared according to Annex II of Regulation (EU) No 1169/2011",
+			Fields: map[string]openapi.Field{
+				"id":     {Schema: &openapi.Schema{Type: "string", Enum: euAllergens}, Example: "gluten"},
+				"number": {Description: "Number of the group in Annex II", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Number(1), Maximum: openapi.Number(14)}},
+			},
+		},
+		reflect.TypeFor[apiv2.Additive](): {
+			Description: "An additive along with its meaning",
+			Fields: map[string]openapi.Field{
+				"code":     {Schema: &openapi.Schema{Type: "string", Enum: additives}, Example: "2"},
+				"eNumbers": {Description: "E-number ranges of the declared substances. Empty for additives not declared by E-numbers, such as caffeine."},
+			},
+		},
+		reflect.TypeFor[apiv2.ENumberRange](): {
+			Description: "An inclusive range of E-numbers. A single E-number has equal from and to.",
+			Fields: map[string]openapi.Field{
+				"from": {Description: "First E-number of the range, without the E prefix", Example: 220},
+				"to":   {Description: "Last E-number of the range, without the E prefix", Example: 228},
+			},
+		},
+		reflect.TypeFor[apiv2.Ingredient](): {
+			Description: "An ingredient along with its meaning",
+			Fields: map[string]openapi.Field{
+				"code": {Schema: &openapi.Schema{Type: "string", Enum: ingredients}, Example: "V"},
+			},
+		},
+
+		reflect.TypeFor[apiv2.SyncEvent](): {
+			Description: "A synchronization with the upstream server",
+			Fields: map[string]openapi.Field{
+				"start":    dateTime,
+				"stop":     dateTime,
+				"warnings": {Description: "Problems found during the synchronization. Events stored before warnings were recorded have no warnings."},
+			},
+		},
+		reflect.TypeFor[apiv2.SyncWarning](): {
+			Description: "A problem with the upstream data found during synchronization. Data affected by an `unpaired-item` warning is stored with only one language, other affected data is not stored.",
+			Fields: map[string]openapi.Field{
+				"kind":     {Schema: &openapi.Schema{Type: "string", Enum: apiEnum(WarningUnknownLocation, WarningLocationMismatch, WarningMissingDay, WarningDuplicateDay, WarningUnpairedItem)}},
+				"location": {Description: "ID of the affected location, if known", Example: "mensa-sued"},
+				"feedID":   {Description: "Location id of the german plan", Example: 1},
+				"feedIDEN": {Description: "Location id of the english plan, if different from the german one"},
+				"date":     {Description: "Affected day, if any", Schema: apiV2DateSchema},
+				"category": {Description: "Category of the affected item, if any", Example: "Suppe"},
+				"title":    {Description: "Title of the affected item, if any"},
+				"missing":  {Description: "For `missing-day` and `unpaired-item`, the language the day or item is missing from", Schema: &openapi.Schema{Type: "string", Enum: apiEnum("de", "en")}},
+				"message":  {Description: "Human-readable description of the problem"},
+			},
+		},
+	}
+}
+
+func (server *Server) handleAPIv2Sync(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "APIv2.Sync").Logger()
+
+	sync, err := server.API.LastSync(r.Context())
+	logger.Trace().Err(err).Msg("API.Sync")
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		server.handleNotFound(w)
+		return
+	}
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v2SyncEvent(sync))
+}
+
+func (server *Server) handleAPIv2Locations(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "APIv2.Locations").Logger()
+
+	locations, err := server.API.Locations()
+	logger.Trace().Err(err).Msg("API.Locations")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	results := make([]apiv2.Location, len(locations))
+	for i, loc := range locations {
+		results[i] = v2Location(loc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (server *Server) handleAPIv2Nearby(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "APIv2.Nearby").Logger()
+
+	query := r.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+	if errLat != nil || errLon != nil || !location.ValidCoordinates(lat, lon) {
+		server.handleBadRequest(w)
+		return
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 0 {
+		limit = 0 // no limit
+	}
+
+	today := ltime.Today()
+	nearby, err := server.API.nearby(lat, lon, today, limit)
+	logger.Trace().Err(err).Msg("API.Nearby")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	results := make([]apiv2.NearbyLocation, len(nearby))
+	for i, n := range nearby {
+		items, err := server.API.MenuItems(n.Location, today)
+		logger.Trace().Err(err).Msg("API.MenuItems")
+		if err != nil {
+			server.handleInternalServerError(w)
+			return
+		}
+
+		results[i] = apiv2.NearbyLocation{
+			Location: v2Location(n.Location),
+			Distance: n.Distance,
+			Menu:     v2Menu(n.Location, today, items),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (server *Server) handleAPIv2Location(w http.ResponseWriter, r *http.Request) {
+	location := location.Location(r.PathValue("location"))
+
+	logger := server.Logger.With().Str("route", "APIv2.Location").Str("location", string(location)).Logger()
+
+	exists, err := server.API.KnowsLocation(location)
+	logger.Trace().Err(err).Msg("API.KnowsLocation")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+	if !exists {
+		server.handleNotFound(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v2Location(location))
+}
+
+func (server *Server) handleAPIv2MenuDays(w http.ResponseWriter, r *http.Request) {
+	location := location.Location(r.PathValue("location"))
+
+	logger := server.Logger.With().Str("route", "APIv2.MenuDays").Str("location", string(location)).Logger()
+
+	// parse the range, defaulting to the same range as version 1
+	query := r.URL.Query()
+	from := ltime.Today().Add(-21)
+	if value := query.Get("from"); value != "" {
+		var err error
+		if from, err = ltime.ParseDate(value); err != nil {
+			server.handleBadRequest(w)
+			return
+		}
+	}
+	to := from.Add(27)
+	if value := query.Get("to"); value != "" {
+		var err error
+		if to, err = ltime.ParseDate(value); err != nil {
+			server.handleBadRequest(w)
+			return
+		}
+	}
+
+	count := from.DaysUntil(to) + 1
+	if count < 1 || count > apiMaxDays {
+		server.handleBadRequest(w)
+		return
+	}
+
+	days, err := server.API.Days(location, from, count)
+	logger.Trace().Err(err).Msg("API.Days")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	// check if the location exists
+	if len(days) == 0 {
+		exists, err := server.API.KnowsLocation(location)
+		if err != nil {
+			server.handleInternalServerError(w)
+			return
+		}
+
+		if !exists {
+			server.handleNotFound(w)
+			return
+		}
+	}
+
+	results := apiv2.Days{Location: string(location), Days: make([]string, len(days))}
+	for i, day := range days {
+		results.Days[i] = day.Date()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (server *Server) handleAPIv2Menu(w http.ResponseWriter, r *http.Request) {
+	location := location.Location(r.PathValue("location"))
+	day, err := ltime.ParseDate(r.PathValue("date"))
+	if err != nil {
+		server.handleBadRequest(w)
+		return
+	}
+
+	logger := server.Logger.With().Str("route", "APIv2.Menu").Str("location", string(location)).Stringer("day", day).Logger()
+
+	items, err := server.API.MenuItems(location, day)
+	logger.Trace().Err(err).Msg("API.MenuItems")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	// an empty menu is only valid for known locations
+	if len(items) == 0 {
+		exists, err := server.API.KnowsLocation(location)
+		if err != nil {
+			server.handleInternalServerError(w)
+			return
+		}
+
+		if !exists {
+			server.handleNotFound(w)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v2Menu(location, day, items))
+}
+
+// v2Location converts a location to version 2 of the api.
+func v2Location(loc location.Location) apiv2.Location {
+	desc := loc.Description()
+
+	result := apiv2.Location{
+		ID:   string(loc),
+		Name: desc.Name,
+		Kind: strings.TrimPrefix(desc.TypeKey(), "location."),
+
+		Address: apiv2.Address{
+			Street: desc.Street,
+			Number: desc.StreetNo,
+			ZIP:    desc.ZIP,
+			City:   desc.City,
+		},
+
+		Hours:          v2Hours(desc.Hours),
+		BreakHours:     v2Hours(desc.BreakHours),
+		Closures:       make([]apiv2.Closure, len(desc.Closures)),
+		SemesterBreaks: []apiv2.Period{},
+	}
+	if desc.HasCoordinates() {
+		result.Coordinates = &apiv2.Coordinates{Latitude: desc.Latitude, Longitude: desc.Longitude}
+	}
+	for i, c := range desc.Closures {
+		result.Closures[i] = apiv2.Closure{
+			From:   c.From,
+			To:     c.To,
+			Reason: apiv2.Localized{DE: c.ReasonDE, EN: c.ReasonEN},
+		}
+	}
+	for _, p := range location.SemesterBreaks() {
+		result.SemesterBreaks = append(result.SemesterBreaks, apiv2.Period{From: p.From, To: p.To})
+	}
+	return result
+}
+
+// v2Hours converts weekly opening hours to version 2 of the api.
+func v2Hours(weekly location.Weekly) apiv2.Hours {
+	hours := make(apiv2.Hours)
+	for day, ranges := range weekly.ByName() {
+		hours[day] = make([]apiv2.TimeRange, len(ranges))
+		for i, tr := range ranges {
+			hours[day][i] = apiv2.TimeRange{Open: tr.Open, Close: tr.Close}
+		}
+	}
+	return hours
+}
+
+// v2Menu converts the menu of the given location and day to version 2 of the api.
+func v2Menu(loc location.Location, day ltime.Day, items []MenuItem) apiv2.Menu {
+	menu := apiv2.Menu{
+		Location: string(loc),
+		Date:     day.Date(),
+		Items:    make([]apiv2.MenuItem, len(items)),
+	}
+	for i, item := range items {
+		menu.Items[i] = v2MenuItem(item)
+	}
+	return menu
+}
+
+// v2MenuItem converts a menu item to version 2 of the api.
+func v2MenuItem(m MenuItem) apiv2.MenuItem {
+	item := apiv2.MenuItem{
+		Category:    apiv2.Localized{DE: m.Category, EN: m.CategoryEN},
+		Title:       apiv2.Localized{DE: m.TitleDE, EN: m.TitleEN},
+		Description: apiv2.Localized{DE: m.DescriptionDE, EN: m.DescriptionEN},
+		Sides:       apiv2.Localized{DE: m.BeilagenDE, EN: m.BeilagenEN},
+
+		Prices: apiv2.Prices{
+			Student:  float64(m.Preis1),
+			Employee: float64(m.Preis2),
+			Guest:    float64(m.Preis3),
+		},
+		Nutrition: apiv2.Nutrition{
+			EnergyKJ:      float64(m.Kj),
+			EnergyKcal:    float64(m.Kcal),
+			Fat:           float64(m.Fett),
+			SaturatedFat:  float64(m.Gesfett),
+			Carbohydrates: float64(m.Kh),
+			Sugar:         float64(m.Zucker),
+			Fiber:         float64(m.Ballaststoffe),
+			Protein:       float64(m.Eiweiss),
+			Salt:          float64(m.Salz),
+		},
+		Diet: apiv2.Diet{
+			Category:        string(m.DietaryCategory),
+			Unknown:         m.DietaryUnknown,
+			Meat:            []string{},
+			GlutenFree:      m.GlutenFree,
+			LactoseFree:     m.LactoseFree,
+			EggFree:         m.EggFree,
+			NutFree:         m.NutFree,
+			HalalCompatible: m.HalalCompatible,
+		},
+
+		Allergens:   []apiv2.Allergen{},
+		EUAllergens: []apiv2.EUAllergen{},
+		Additives:   []apiv2.Additive{},
+		Ingredients: []apiv2.Ingredient{},
+
+		Edited: m.Edited,
+	}
+
+	for _, meat := range []struct {
+		contains bool
+		name     string
+	}{
+		{m.ContainsPork, apiv2.MeatPork},
+		{m.ContainsBeef, apiv2.MeatBeef},
+		{m.ContainsPoultry, apiv2.MeatPoultry},
+		{m.ContainsLamb, apiv2.MeatLamb},
+		{m.ContainsGame, apiv2.MeatGame},
+	} {
+		if meat.contains {
+			item.Diet.Meat = append(item.Diet.Meat, meat.name)
+		}
+	}
+
+	for _, a := range m.AllergenAnnotations.Data() {
+		item.Allergens = append(item.Allergens, v2Allergen(a))
+	}
+	for _, e := range m.EUAllergens.Data() {
+		item.EUAllergens = append(item.EUAllergens, v2EUAllergen(e))
+	}
+	for _, a := range m.AdditiveAnnotations.Data() {
+		item.Additives = append(item.Additives, v2Additive(a))
+	}
+	for _, i := range m.IngredientAnnotations.Data() {
+		item.Ingredients = append(item.Ingredients, v2Ingredient(i))
+	}
+
+	return item
+}
+
+// v2Allergen converts an allergen to version 2 of the api.
+func v2Allergen(a annotations.Allergen) apiv2.Allergen {
+	return apiv2.Allergen{
+		Code: string(a),
+		Name: apiv2.Localized{DE: a.DEString(), EN: a.ENString()},
+		EU:   string(a.EU()),
+	}
+}
+
+// v2EUAllergen converts an EU allergen group to version 2 of the api.
+func v2EUAllergen(e annotations.EUAllergen) apiv2.EUAllergen {
+	return apiv2.EUAllergen{
+		ID:     string(e),
+		Number: e.Number(),
+		Name:   apiv2.Localized{DE: e.DEString(), EN: e.ENString()},
+	}
+}
+
+// v2Additive converts an additive to version 2 of the api.
+func v2Additive(a annotations.Additive) apiv2.Additive {
+	additive := apiv2.Additive{
+		Code:     string(a),
+		Name:     apiv2.Localized{DE: a.DEString(), EN: a.ENString()},
+		ENumbers: []apiv2.ENumberRange{},
+	}
+	for _, r := range a.ENumbers() {
+		additive.ENumbers = append(additive.ENumbers, apiv2.ENumberRange{From: r.From, To: r.To})
+	}
+	return additive
+}
+
+// v2Ingredient converts an ingredient to version 2 of the api.
+func v2Ingredient(i annotations.Ingredient) apiv2.Ingredient {
+	return apiv2.Ingredient{
+		Code: string(i),
+		Name: apiv2.Localized{DE: i.DEString(), EN: i.ENString()},
+	}
+}
+
+// v2SyncEvent converts a sync event to version 2 of the api.
+func v2SyncEvent(se SyncEvent) apiv2.SyncEvent {
+	event := apiv2.SyncEvent{
+		Start:    time.Unix(se.Start, 0).UTC().Format(time.RFC3339),
+		Stop:     time.Unix(se.Stop, 0).UTC().Format(time.RFC3339),
+		Warnings: make([]apiv2.SyncWarning, len(se.Report.Warnings)),
+	}
+	for i, sw := range se.Report.Warnings {
+		warning := apiv2.SyncWarning{
+			Kind:     string(sw.Kind),
+			Location: sw.Location,
+			FeedID:   sw.FeedID,
+			FeedIDEN: sw.FeedIDEN,
+			Category: sw.Category,
+			Title:    sw.Title,
+			Message:  sw.Message,
+		}
+		if sw.Day != 0 {
+			warning.Date = sw.Day.Date()
+		}
+		if sw.Kind == WarningMissingDay || sw.Kind == WarningUnpairedItem {
+			warning.Missing = "de"
+			if sw.MissingEN {
+				warning.Missing = "en"
+			}
+		}
+		event.Warnings[i] = warning
+	}
+	return event
+}
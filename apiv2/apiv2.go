@@ -0,0 +1,213 @@
+// Package apiv2 defines the json schema of version 2 of the faulunch http api, served at /api/v2.
+//
+// Unlike version 1, it does not expose the database representation of menus.
+// All dates are ISO 8601 dates such as "2023-05-10", all times are RFC 3339 timestamps.
+//
+//spellchecker:words apiv2
+package apiv2
+
+// Localized is a text in both german and english.
+type Localized struct {
+	DE string `json:"de"`
+	EN string `json:"en"`
+}
+
+// Location is a single location.
+type Location struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"` // one of "servery", "cafe", "internal" or "other"
+
+	Address     Address      `json:"address"`
+	Coordinates *Coordinates `json:"coordinates"` // nil if unknown
+
+	Hours          Hours     `json:"hours"`      // regular opening hours, empty if unknown
+	BreakHours     Hours     `json:"breakHours"` // opening hours during semester breaks, same as Hours if empty
+	Closures       []Closure `json:"closures"`
+	SemesterBreaks []Period  `json:"semesterBreaks"` // lecture-free periods, where BreakHours apply
+}
+
+// Location kinds.
+const (
+	KindServery  = "servery"
+	KindCafe     = "cafe"
+	KindInternal = "internal"
+	KindOther    = "other"
+)
+
+// Address is the postal address of a location.
+type Address struct {
+	Street string `json:"street"`
+	Number string `json:"number"`
+	ZIP    string `json:"zip"`
+	City   string `json:"city"`
+}
+
+// Coordinates are the approximate coordinates of a location.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Hours are opening hours per weekday, keyed by lowercase english weekday name.
+// Closed days are omitted.
+type Hours map[string][]TimeRange
+
+// TimeRange is a range of time within a day, formatted as "15:04".
+type TimeRange struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// Period is a period of days, including both ends.
+type Period struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Closure is a period of days where a location is closed, including both ends.
+type Closure struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason Localized `json:"reason"`
+}
+
+// NearbyLocation is a location along with its distance to a given point and its menu.
+type NearbyLocation struct {
+	Location Location `json:"location"`
+	Distance float64  `json:"distance"` // in meters
+	Menu     Menu     `json:"menu"`
+}
+
+// Days are the days a location has a menu for.
+type Days struct {
+	Location string   `json:"location"`
+	Days     []string `json:"days"` // newest first
+}
+
+// Menu is the menu of a location on a single day.
+type Menu struct {
+	Location string     `json:"location"`
+	Date     string     `json:"date"`
+	Items    []MenuItem `json:"items"`
+}
+
+// MenuItem is a single item on a menu.
+type MenuItem struct {
+	Category    Localized `json:"category"`
+	Title       Localized `json:"title"`
+	Description Localized `json:"description"`
+	Sides       Localized `json:"sides"`
+
+	Prices    Prices    `json:"prices"`
+	Nutrition Nutrition `json:"nutrition"`
+	Diet      Diet      `json:"diet"`
+
+	Allergens   []Allergen   `json:"allergens"`
+	EUAllergens []EUAllergen `json:"euAllergens"`
+	Additives   []Additive   `json:"additives"`
+	Ingredients []Ingredient `json:"ingredients"`
+
+	Edited bool `json:"edited"` // manually added or changed by an administrator
+}
+
+// Prices are the prices of a menu item in euros.
+type Prices struct {
+	Student  float64 `json:"student"`
+	Employee float64 `json:"employee"`
+	Guest    float64 `json:"guest"`
+}
+
+// Nutrition are the nutritional values of a menu item.
+// Amounts are in grams.
+type Nutrition struct {
+	EnergyKJ      float64 `json:"energyKJ"`
+	EnergyKcal    float64 `json:"energyKcal"`
+	Fat           float64 `json:"fat"`
+	SaturatedFat  float64 `json:"saturatedFat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugar         float64 `json:"sugar"`
+	Fiber         float64 `json:"fiber"`
+	Protein       float64 `json:"protein"`
+	Salt          float64 `json:"salt"`
+}
+
+// Diet describes the dietary properties of a menu item.
+type Diet struct {
+	Category string `json:"category"` // one of "meat", "fish", "vegetarian" or "vegan"
+
+	// Unknown indicates that no pictograms are known for the menu item, or that some of its annotations are unknown.
+	// In this case HalalCompatible and the free-from labels are false, and Meat is only set if pictograms are known.
+	Unknown bool     `json:"unknown"`
+	Meat    []string `json:"meat"` // kinds of meat according to the pictograms
+
+	GlutenFree      bool `json:"glutenFree"`
+	LactoseFree     bool `json:"lactoseFree"`
+	EggFree         bool `json:"eggFree"`
+	NutFree         bool `json:"nutFree"`
+	HalalCompatible bool `json:"halalCompatible"`
+}
+
+// Kinds of meat.
+const (
+	MeatPork    = "pork"
+	MeatBeef    = "beef"
+	MeatPoultry = "poultry"
+	MeatLamb    = "lamb"
+	MeatGame    = "game"
+)
+
+// Allergen is an allergen declared for a menu item.
+type Allergen struct {
+	Code string    `json:"code"`
+	Name Localized `json:"name"`
+	EU   string    `json:"eu"` // id of the EU allergen group
+}
+
+// EUAllergen is an allergen group according to Annex II of Regulation (EU) No 1169/2011.
+type EUAllergen struct {
+	ID     string    `json:"id"`
+	Number int       `json:"number"`
+	Name   Localized `json:"name"`
+}
+
+// Additive is an additive declared for a menu item.
+type Additive struct {
+	Code     string         `json:"code"`
+	Name     Localized      `json:"name"`
+	ENumbers []ENumberRange `json:"eNumbers"`
+}
+
+// ENumberRange is an inclusive range of E-numbers.
+type ENumberRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Ingredient is an ingredient declared for a menu item.
+type Ingredient struct {
+	Code string    `json:"code"`
+	Name Localized `json:"name"`
+}
+
+// SyncEvent is a synchronization with the upstream server.
+type SyncEvent struct {
+	Start    string        `json:"start"`
+	Stop     string        `json:"stop"`
+	Warnings []SyncWarning `json:"warnings"`
+}
+
+// SyncWarning is a problem with the upstream data found during synchronization.
+type SyncWarning struct {
+	Kind string `json:"kind"`
+
+	Location string `json:"location,omitempty"` // location slug, if known
+	FeedID   int    `json:"feedID,omitempty"`   // location id of the german plan
+	FeedIDEN int    `json:"feedIDEN,omitempty"` // location id of the english plan, if different
+	Date     string `json:"date,omitempty"`     // affected day, if any
+	Category string `json:"category,omitempty"` // category of the affected item, if any
+	Title    string `json:"title,omitempty"`    // title of the affected item, if any
+	Missing  string `json:"missing,omitempty"`  // language ("de" or "en") the affected day or item is missing from, if any
+
+	Message string `json:"message"`
+}
@@ -0,0 +1,152 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words context errors http slices strconv strings sync atomic time github gorm faulunch internal httpcache i18n ltime
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/httpcache"
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"gorm.io/gorm"
+)
+
+// serverCache holds the state of the response cache of a server.
+type serverCache struct {
+	responses httpcache.Cache
+
+	// generation is incremented whenever an administrator modifies the database,
+	// modified holds the unix time of the last such modification.
+	generation atomic.Uint64
+	modified   atomic.Int64
+}
+
+// cacheVersion identifies the state of the database responses are computed from.
+type cacheVersion struct {
+	Key      string    // changes whenever responses may change
+	Modified time.Time // time responses last changed
+}
+
+// cacheVersion returns the current version of cached responses.
+//
+// Responses change when a new sync event is stored, when an administrator edits the database, and when the day changes.
+func (server *Server) cacheVersion(ctx context.Context) (cacheVersion, error) {
+	var se SyncEvent
+	err := server.API.DB.WithContext(ctx).Select("ID", "Stop").Order("Stop DESC").Take(&se).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return cacheVersion{}, err
+	}
+
+	today := ltime.Today()
+	generation := server.cache.generation.Load()
+
+	modified := max(se.Stop, server.cache.modified.Load(), int64(today))
+	return cacheVersion{
+		Key:      strconv.FormatUint(uint64(se.ID), 10) + "-" + strconv.FormatInt(se.Stop, 10) + "-" + today.String() + "-" + strconv.FormatUint(generation, 10),
+		Modified: time.Unix(modified, 0),
+	}, nil
+}
+
+// InvalidateCache drops all cached responses.
+// It must be called when responses change without a new sync event, such as after the location registry was replaced.
+func (server *Server) InvalidateCache() {
+	server.cache.modified.Store(time.Now().Unix())
+	server.cache.generation.Add(1)
+}
+
+// isAdminPath checks if path belongs to an administrative route.
+func isAdminPath(path string) bool {
+	return strings.HasPrefix(path, "/admin/") || strings.HasPrefix(path, "/api/v1/admin/")
+}
+
+// cacheable checks if the response to r may be stored in the response cache.
+//
+// Only public GET and HEAD requests are cached.
+// Exports of the database, health checks and streamed responses are always computed freshly.
+func (server *Server) cacheable(r *http.Request) bool {
+	if server.CacheSize <= 0 || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	if isAdminPath(r.URL.Path) || r.URL.Path == "/api/v1/sqlite" || r.URL.Path == "/api/v1/healthcheck" {
+		return false
+	}
+	return !wantsJSONLines(r)
+}
+
+// cacheKey returns the key of the response to r in the response cache.
+// Responses depend on the url, the preferred language and the dietary profile.
+func cacheKey(r *http.Request) string {
+	var profile string
+	if cookie, err := r.Cookie(profileCookie); err == nil {
+		profile = cookie.Value
+	}
+	return i18n.Match(r.Header.Get("Accept-Language")).Code() + " " + profile + " " + r.URL.RequestURI()
+}
+
+// serveCached serves r from the response cache, computing and storing the response using the mux if needed.
+func (server *Server) serveCached(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", r.URL.Path).Logger()
+
+	version, err := server.cacheVersion(r.Context())
+	if err != nil {
+		logger.Trace().Err(err).Msg("Server.cacheVersion")
+		server.mux.ServeHTTP(w, r)
+		return
+	}
+
+	key := cacheKey(r)
+	entry, ok := server.cache.responses.Get(version.Key, key)
+	if !ok {
+		// HEAD requests do not have a body to store
+		if r.Method == http.MethodHead {
+			server.mux.ServeHTTP(w, r)
+			return
+		}
+
+		entry = httpcache.Record(&server.mux, r, w.Header())
+
+		// only store complete responses that do not change client state
+		if entry.Status != http.StatusOK || entry.Header.Get("Set-Cookie") != "" {
+			writeEntry(w, r, entry)
+			return
+		}
+
+		entry.ETag = httpcache.ETag(entry.Body)
+		entry.Header.Set("ETag", entry.ETag)
+		entry.Header.Set("Last-Modified", version.Modified.UTC().Format(http.TimeFormat))
+		server.cache.responses.Add(version.Key, key, entry)
+	}
+
+	if httpcache.NotModified(r, entry.ETag, version.Modified) {
+		header := w.Header()
+		for _, name := range []string{"Cache-Control", "ETag", "Last-Modified", "Vary"} {
+			if values := entry.Header.Values(name); len(values) > 0 {
+				header[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+			}
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeEntry(w, r, entry)
+}
+
+// writeEntry writes a recorded response to w.
+func writeEntry(w http.ResponseWriter, r *http.Request, entry *httpcache.Entry) {
+	header := w.Header()
+	for name, values := range entry.Header {
+		header[name] = slices.Clone(values)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	w.WriteHeader(entry.Status)
+
+	if r.Method != http.MethodHead {
+		w.Write(entry.Body)
+	}
+}
@@ -0,0 +1,156 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words http httptest strings testing github zerolog faulunch
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+)
+
+func TestServer_cache(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}, AdminToken: "secret", CacheSize: 1 << 20}
+
+	const (
+		monday  = "/api/v1/menu/mensa-sued/1760911200"
+		tuesday = "/api/v1/menu/mensa-sued/1760997600"
+	)
+
+	serve := func(t *testing.T, method, path string, header map[string]string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// removeItems removes the items of the given day from the database, without storing a sync event.
+	removeItems := func(t *testing.T, day string) {
+		t.Helper()
+
+		if err := db.Exec("DELETE FROM menu_items WHERE day = ?", day).Error; err != nil {
+			t.Fatalf("failed to delete items: %v", err)
+		}
+	}
+
+	first := serve(t, http.MethodGet, monday, nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("GET %s returned status %d", monday, first.Code)
+	}
+	etag, modified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("GET %s did not return ETag and Last-Modified headers: %v", monday, first.Header())
+	}
+	if got := first.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want %q", got, "no-cache")
+	}
+	if got := first.Header().Get("Vary"); !strings.Contains(got, "Accept-Language") || !strings.Contains(got, "Cookie") {
+		t.Errorf("Vary = %q, want it to contain Accept-Language and Cookie", got)
+	}
+
+	t.Run("conditional requests", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			header map[string]string
+			want   int
+		}{
+			{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+			{"one of several etags", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+			{"other etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+			{"not modified since", map[string]string{"If-Modified-Since": modified}, http.StatusNotModified},
+			{"modified since", map[string]string{"If-Modified-Since": "Mon, 01 Jan 2001 00:00:00 GMT"}, http.StatusOK},
+			{"etag takes precedence", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": modified}, http.StatusOK},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := serve(t, http.MethodGet, monday, tt.header)
+				if rec.Code != tt.want {
+					t.Fatalf("GET %s returned status %d, want %d", monday, rec.Code, tt.want)
+				}
+				if got := rec.Header().Get("ETag"); got != etag {
+					t.Errorf("ETag = %q, want %q", got, etag)
+				}
+				if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+					t.Errorf("304 response has a body: %q", rec.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("head", func(t *testing.T) {
+		rec := serve(t, http.MethodHead, monday, nil)
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+			t.Errorf("HEAD %s returned status %d with %d bytes", monday, rec.Code, rec.Body.Len())
+		}
+		if got, want := rec.Header().Get("Content-Length"), first.Header().Get("Content-Length"); got != want {
+			t.Errorf("Content-Length = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("streamed responses", func(t *testing.T) {
+		rec := serve(t, http.MethodGet, "/api/v1/menu/mensa-sued", map[string]string{"Accept": "application/jsonl"})
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" {
+			t.Errorf("JSON Lines response returned status %d with ETag %q", rec.Code, rec.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("invalidated by sync", func(t *testing.T) {
+		removeItems(t, "1760911200")
+
+		if rec := serve(t, http.MethodGet, monday, nil); rec.Code != http.StatusOK || rec.Body.String() != first.Body.String() {
+			t.Fatalf("GET %s was not served from the cache: status %d", monday, rec.Code)
+		}
+
+		event := faulunch.SyncEvent{Start: 1683790644, Stop: 1683790646}
+		if err := event.Store(t.Context(), db); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+
+		if rec := serve(t, http.MethodGet, monday, map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s after sync returned status %d, want %d", monday, rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("invalidated by admin", func(t *testing.T) {
+		if rec := serve(t, http.MethodGet, tuesday, nil); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s returned status %d", tuesday, rec.Code)
+		}
+		removeItems(t, "1760997600")
+
+		if rec := serve(t, http.MethodPut, "/api/v1/admin/categories/Grill", nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("unauthorized PUT returned status %d", rec.Code)
+		}
+		if rec := serve(t, http.MethodGet, tuesday, nil); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s after unauthorized request returned status %d, want %d", tuesday, rec.Code, http.StatusOK)
+		}
+
+		if rec := serve(t, http.MethodPut, "/api/v1/admin/categories/Grill", map[string]string{"Authorization": "Bearer secret"}); rec.Code != http.StatusBadRequest {
+			t.Fatalf("PUT without body returned status %d", rec.Code)
+		}
+		if rec := serve(t, http.MethodGet, tuesday, nil); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s after failed admin request returned status %d, want %d", tuesday, rec.Code, http.StatusOK)
+		}
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/categories/Grill", strings.NewReader(`{"translation":"Grill"}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("PUT returned status %d", rec.Code)
+		}
+
+		if rec := serve(t, http.MethodGet, tuesday, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s after admin request returned status %d, want %d", tuesday, rec.Code, http.StatusNotFound)
+		}
+	})
+}
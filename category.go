@@ -0,0 +1,235 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words context errors strings time github zerolog gorm clause datatypes
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/internal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// builtinCategoryTranslations holds translations of category names that are always known.
+// They can be overwritten by a [CategoryTranslation] in the database.
+var builtinCategoryTranslations = CategoryTranslations{
+	"Essen":             "Meal",
+	"Aktionsessen":      "Special Meal",
+	"Aktion":            "Special",
+	"Suppe":             "Soup",
+	"Suppen":            "Soups",
+	"SB-Theke":          "Self-Service Counter",
+	"Tagesangebot":      "Daily Special",
+	"Tipp des Tages":    "Tip Of The Day",
+	"Pizza":             "Pizza",
+	"vegetarisch/vegan": "Vegetarian/Vegan",
+	"Fleisch/Fisch":     "Meat/Fish",
+}
+
+// CategoryTranslations maps (german) category names, or single words of them, to their english translation.
+type CategoryTranslations map[string]string
+
+// Translate translates the given category name.
+// If there is no translation for the entire name, each word is translated individually.
+//
+// Returns the translation, and the words that could not be translated.
+// Words consisting only of digits never need a translation.
+func (translations CategoryTranslations) Translate(category string) (english string, missing []string) {
+	if complete, ok := translations[category]; ok {
+		return complete, nil
+	}
+
+	fields := strings.Fields(category)
+	for i, field := range fields {
+		trans, ok := translations[field]
+		if ok {
+			fields[i] = trans
+		} else if !isOnlyDigits(field) {
+			missing = append(missing, field)
+		}
+	}
+
+	return strings.Join(fields, " "), missing
+}
+
+// CategoryTranslation represents a category name, or a word of a category name, and its translation.
+//
+// Category names are recorded whenever computed fields are refreshed.
+// Translations are added by an administrator.
+type CategoryTranslation struct {
+	Name        string                       `gorm:"primaryKey" json:"name"`     // german name of the category, or a single word
+	Translation string                       `json:"translation"`                // english translation, empty if not set by an administrator
+	English     string                       `json:"english"`                    // english name as currently computed
+	Builtin     bool                         `gorm:"-" json:"builtin,omitempty"` // is there a built-in translation for this name?
+	Missing     datatypes.JSONType[[]string] `json:"missing"`                    // words of the name that could not be translated
+
+	Count     int64 `json:"count"`     // number of menu items currently in this category
+	FirstSeen int64 `json:"firstSeen"` // unix timestamp when this category was first found
+	LastSeen  int64 `json:"lastSeen"`  // unix timestamp when this category was last found
+	Updated   int64 `json:"updated"`   // unix timestamp of the last change of the translation
+}
+
+// loadCategoryTranslations loads the built-in translations, overwritten by those stored in the database.
+func loadCategoryTranslations(db *gorm.DB) (CategoryTranslations, error) {
+	var stored []CategoryTranslation
+	if err := db.Model(&CategoryTranslation{}).Where("translation <> ?", "").Find(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	translations := make(CategoryTranslations, len(builtinCategoryTranslations)+len(stored))
+	for name, translation := range builtinCategoryTranslations {
+		translations[name] = translation
+	}
+	for _, ct := range stored {
+		translations[ct.Name] = ct.Translation
+	}
+	return translations, nil
+}
+
+// categoryReport aggregates the categories of menu items.
+type categoryReport map[string]*CategoryTranslation
+
+// Add adds the category of the given item to the report.
+func (report categoryReport) Add(item *MenuItem, translations CategoryTranslations) {
+	report.add(item.Category, translations).Count++
+}
+
+// add adds the given category to the report, unless it already exists.
+// Returns the entry of the category.
+func (report categoryReport) add(category string, translations CategoryTranslations) *CategoryTranslation {
+	if ct, ok := report[category]; ok {
+		return ct
+	}
+
+	english, missing := translations.Translate(category)
+	if missing == nil {
+		missing = []string{}
+	}
+
+	ct := &CategoryTranslation{Name: category, English: english}
+	internal.SetJSONData(&ct.Missing, missing)
+	report[category] = ct
+	return ct
+}
+
+// Store stores the report in the database, seen at the given time.
+// Previously stored categories not contained in the report are kept with a count of 0.
+func (report categoryReport) Store(db *gorm.DB, now int64) error {
+	cts := make([]*CategoryTranslation, 0, len(report))
+	for _, ct := range report {
+		ct.FirstSeen = now
+		ct.LastSeen = now
+		cts = append(cts, ct)
+	}
+
+	if len(cts) > 0 {
+		res := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"english", "missing", "count", "last_seen"}),
+		}).Create(&cts)
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	return db.Model(&CategoryTranslation{}).Where("last_seen < ?", now).Update("count", 0).Error
+}
+
+// CategoryTranslations returns all known category names and their translations.
+// Categories currently on the menu come first.
+func (api *API) CategoryTranslations(ctx context.Context) ([]CategoryTranslation, error) {
+	cts, err := gorm.G[CategoryTranslation](api.DB).Order("count > 0 DESC, name ASC").Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cts {
+		_, cts[i].Builtin = builtinCategoryTranslations[cts[i].Name]
+	}
+	return cts, nil
+}
+
+var errInvalidCategoryName = errors.New("category name must not be empty")
+
+// SetCategoryTranslation sets the translation of the given category name, or word of a category name.
+// An empty translation removes a previously set translation.
+//
+// The english category names of all menu items are refreshed afterwards.
+func (api *API) SetCategoryTranslation(ctx context.Context, logger *zerolog.Logger, name, translation string) error {
+	if name == "" {
+		return errInvalidCategoryName
+	}
+
+	return api.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ct := CategoryTranslation{Name: name, Translation: translation, English: translation, Updated: time.Now().Unix()}
+		internal.SetJSONData(&ct.Missing, []string{})
+
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"translation", "english", "updated"}),
+		}).Create(&ct)
+		if res.Error != nil {
+			return fmt.Errorf("failed to store translation: %w", res.Error)
+		}
+
+		// recompute the english name of the entry, as names that are not categories on the menu are not refreshed below
+		if translation == "" {
+			translations, err := loadCategoryTranslations(tx)
+			if err != nil {
+				return err
+			}
+
+			english, missing := translations.Translate(name)
+			if missing == nil {
+				missing = []string{}
+			}
+			internal.SetJSONData(&ct.Missing, missing)
+
+			res := tx.Model(&CategoryTranslation{}).Where("name = ?", name).Updates(map[string]any{"english": english, "missing": ct.Missing})
+			if res.Error != nil {
+				return fmt.Errorf("failed to store translation: %w", res.Error)
+			}
+		}
+
+		return refreshCategoryTranslations(logger, tx)
+	})
+}
+
+// refreshCategoryTranslations refreshes the english category names of all menu items.
+// It also updates the recorded categories.
+// This is a cheaper variant of [RefreshComputedFields] that only updates category names.
+func refreshCategoryTranslations(logger *zerolog.Logger, tx *gorm.DB) error {
+	translations, err := loadCategoryTranslations(tx)
+	if err != nil {
+		return err
+	}
+
+	var categories []struct {
+		Category string
+		Count    int64
+	}
+	if err := tx.Model(&MenuItem{}).Select("category, count(*) AS count").Group("category").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	report := make(categoryReport, len(categories))
+	for _, category := range categories {
+		ct := report.add(category.Category, translations)
+		ct.Count = category.Count
+
+		res := tx.Model(&MenuItem{}).Where("category = ?", category.Category).Update("category_en", ct.English)
+		logger.Debug().Err(res.Error).Str("category", category.Category).Str("english", ct.English).Int64("count", res.RowsAffected).Msg("refreshing category name")
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	err = report.Store(tx, time.Now().Unix())
+	logger.Err(err).Msg("storing categories")
+	return err
+}
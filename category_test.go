@@ -0,0 +1,136 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words path filepath slices testing github glebarez sqlite zerolog faulunch internal location ltime gorm gormlogger
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestCategoryTranslations_Translate(t *testing.T) {
+	translations := faulunch.CategoryTranslations{
+		"Essen":          "Meal",
+		"Tipp des Tages": "Tip Of The Day",
+	}
+
+	tests := []struct {
+		category    string
+		wantEnglish string
+		wantMissing []string
+	}{
+		{"Tipp des Tages", "Tip Of The Day", nil},
+		{"Essen 1", "Meal 1", nil},
+		{"Essen Spezial 2", "Meal Spezial 2", []string{"Spezial"}},
+		{"Grill", "Grill", []string{"Grill"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			english, missing := translations.Translate(tt.category)
+			if english != tt.wantEnglish {
+				t.Errorf("Translate() english = %q, want %q", english, tt.wantEnglish)
+			}
+			if !slices.Equal(missing, tt.wantMissing) {
+				t.Errorf("Translate() missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestAPI_SetCategoryTranslation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "category.db")), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := faulunch.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	loc := location.Location("mensa-sued")
+	day := ltime.ParseDay("1760911200")
+	if err := db.Create(&faulunch.MenuItem{Location: loc, Day: day, Category: "Grill 1"}).Error; err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	logger := zerolog.Nop()
+	if err := faulunch.RefreshComputedFields(t.Context(), &logger, db); err != nil {
+		t.Fatalf("RefreshComputedFields() error = %v", err)
+	}
+
+	api := faulunch.API{DB: db}
+	assertCategory := func(wantEnglish string, wantMissing []string) {
+		t.Helper()
+
+		items, err := api.MenuItems(loc, day)
+		if err != nil || len(items) != 1 {
+			t.Fatalf("MenuItems() = %v, %v", items, err)
+		}
+		if items[0].CategoryEN != wantEnglish {
+			t.Errorf("CategoryEN = %q, want %q", items[0].CategoryEN, wantEnglish)
+		}
+
+		categories, err := api.CategoryTranslations(t.Context())
+		if err != nil {
+			t.Fatalf("CategoryTranslations() error = %v", err)
+		}
+		index := slices.IndexFunc(categories, func(ct faulunch.CategoryTranslation) bool { return ct.Name == "Grill 1" })
+		if index < 0 {
+			t.Fatalf("CategoryTranslations() = %v, want category %q", categories, "Grill 1")
+		}
+		if got := categories[index]; got.English != wantEnglish || got.Count != 1 || !slices.Equal(got.Missing.Data(), wantMissing) {
+			t.Errorf("CategoryTranslations() = %v, want english %q and missing %v", got, wantEnglish, wantMissing)
+		}
+	}
+
+	assertCategory("Grill 1", []string{"Grill"})
+
+	// translating a single word refreshes the category
+	if err := api.SetCategoryTranslation(t.Context(), &logger, "Grill", "Barbecue"); err != nil {
+		t.Fatalf("SetCategoryTranslation() error = %v", err)
+	}
+	assertCategory("Barbecue 1", []string{})
+
+	// removing the translation restores the original name
+	if err := api.SetCategoryTranslation(t.Context(), &logger, "Grill", ""); err != nil {
+		t.Fatalf("SetCategoryTranslation() error = %v", err)
+	}
+	assertCategory("Grill 1", []string{"Grill"})
+
+	// removing a translation of a name not on the menu restores the built-in or original name
+	for _, tt := range []struct {
+		name        string
+		wantEnglish string
+		wantMissing []string
+	}{
+		{"Suppe", "Soup", []string{}},
+		{"Grill", "Grill", []string{"Grill"}},
+	} {
+		for _, translation := range []string{"Potage", ""} {
+			if err := api.SetCategoryTranslation(t.Context(), &logger, tt.name, translation); err != nil {
+				t.Fatalf("SetCategoryTranslation() error = %v", err)
+			}
+		}
+
+		categories, err := api.CategoryTranslations(t.Context())
+		if err != nil {
+			t.Fatalf("CategoryTranslations() error = %v", err)
+		}
+		index := slices.IndexFunc(categories, func(ct faulunch.CategoryTranslation) bool { return ct.Name == tt.name })
+		if index < 0 {
+			t.Fatalf("CategoryTranslations() = %v, want name %q", categories, tt.name)
+		}
+		if got := categories[index]; got.Translation != "" || got.English != tt.wantEnglish || !slices.Equal(got.Missing.Data(), tt.wantMissing) {
+			t.Errorf("CategoryTranslations() = %v, want english %q and missing %v", got, tt.wantEnglish, tt.wantMissing)
+		}
+	}
+}
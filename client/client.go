@@ -0,0 +1,278 @@
+// Package client implements a client for the faulunch http api.
+//
+// The methods of [Client] correspond to the endpoints described in the openapi.json file served by [faulunch.Server].
+//
+//spellchecker:words client
+package client
+
+//spellchecker:words context encoding json errors http strconv github faulunch internal location ltime
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+// Client is a client for the api of a faulunch server.
+type Client struct {
+	// BaseURL is the url of the api, such as "https://example.com/api/v1".
+	BaseURL *url.URL
+
+	// HTTPClient is used to make requests.
+	// If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+}
+
+var errNotAbsolute = errors.New("base url must be absolute")
+
+// New creates a new client for the api at the given base url.
+func New(baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, errNotAbsolute
+	}
+	return &Client{BaseURL: u}, nil
+}
+
+// Location is a location as returned by the api.
+type Location struct {
+	ID location.Location `json:"id"`
+	location.LocationDescription
+}
+
+// Locations returns all locations with at least one menu.
+func (client *Client) Locations(ctx context.Context) (locations []Location, err error) {
+	err = client.getJSON(ctx, &locations, nil, "locations")
+	return
+}
+
+// Days returns the days with a menu at the given location, newest first.
+// At most count days starting at from are checked.
+// If from or count are zero, the defaults of the server are used.
+//
+// Deprecated: The route used by Days is deprecated, use [Client.MenuDays] instead.
+func (client *Client) Days(ctx context.Context, loc location.Location, from ltime.Day, count int) (days []ltime.Day, err error) {
+	query := make(url.Values)
+	if from != 0 {
+		query.Set("from", from.String())
+	}
+	if count != 0 {
+		query.Set("days", strconv.Itoa(count))
+	}
+
+	err = client.getJSON(ctx, &days, query, "menu", string(loc))
+	return
+}
+
+// DaysQuery are the parameters of [Client.MenuDays] and [Client.Menus].
+// Zero values use the defaults of the server.
+type DaysQuery struct {
+	From, To  ltime.Day // range of days, including both ends
+	Ascending bool      // return the oldest day first
+	Limit     int       // maximal number of days per page
+	After     string    // cursor of the page to continue after, see [faulunch.MenuDays.Next]
+	Items     bool      // include the items of each day
+}
+
+// values returns the query parameters of q.
+func (q DaysQuery) values() url.Values {
+	query := make(url.Values)
+	if q.From != 0 {
+		query.Set("from", q.From.String())
+	}
+	if q.To != 0 {
+		query.Set("to", q.To.String())
+	}
+	if q.Ascending {
+		query.Set("order", "asc")
+	}
+	if q.Limit != 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.After != "" {
+		query.Set("after", q.After)
+	}
+	if q.Items {
+		query.Set("include", "items")
+	}
+	return query
+}
+
+// MenuDays returns a page of days with a menu at the given location.
+func (client *Client) MenuDays(ctx context.Context, loc location.Location, q DaysQuery) (page faulunch.MenuDays, err error) {
+	if err := client.getJSON(ctx, &page, q.values(), "days", string(loc)); err != nil {
+		return page, err
+	}
+
+	// the location and day are not part of the items
+	for i, day := range page.Days {
+		for j := range day.Items {
+			page.Days[i].Items[j].Location = loc
+			page.Days[i].Items[j].Day = day.Day
+		}
+	}
+	return page, nil
+}
+
+// Menus calls yield for every day with a menu at any of the given locations in the range of q.
+// If locations is empty, all locations are used.
+// If q has a limit, at most that many days are streamed.
+//
+// Days are streamed from the server as JSON Lines, so that large ranges are not held in memory.
+// If yield returns an error, streaming stops and the error is returned.
+func (client *Client) Menus(ctx context.Context, locations []location.Location, q DaysQuery, yield func(menu faulunch.LocationMenuDay) error) error {
+	query := q.values()
+	for _, loc := range locations {
+		query.Add("location", string(loc))
+	}
+
+	res, err := client.get(ctx, jsonLines, query, "menu")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	for decoder.More() {
+		var menu faulunch.LocationMenuDay
+		if err := decoder.Decode(&menu); err != nil {
+			return fmt.Errorf("GET %s: failed to decode response: %w", res.Request.URL, err)
+		}
+
+		// the location and day are not part of the items
+		for i := range menu.Items {
+			menu.Items[i].Location = location.Location(menu.Location)
+			menu.Items[i].Day = menu.Day
+		}
+
+		if err := yield(menu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Menu returns the menu items of the given location on the given day.
+// If there is no menu, an error wrapping [ErrNotFound] is returned.
+func (client *Client) Menu(ctx context.Context, loc location.Location, day ltime.Day) (items []faulunch.MenuItem, err error) {
+	if err := client.getJSON(ctx, &items, nil, "menu", string(loc), day.String()); err != nil {
+		return nil, err
+	}
+
+	// the location and day are not part of the response
+	for i := range items {
+		items[i].Location = loc
+		items[i].Day = day
+	}
+	return items, nil
+}
+
+// LastSync returns the last synchronization event.
+func (client *Client) LastSync(ctx context.Context) (se faulunch.SyncEvent, err error) {
+	err = client.getJSON(ctx, &se, nil, "sync")
+	return
+}
+
+// DownloadSQLite writes a copy of the sqlite database of the server to w.
+// If the server does not allow exporting the database, an error wrapping [ErrNotFound] is returned.
+func (client *Client) DownloadSQLite(ctx context.Context, w io.Writer) error {
+	res, err := client.get(ctx, "", nil, "sqlite")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("GET %s: failed to read response: %w", res.Request.URL, err)
+	}
+	return nil
+}
+
+// getJSON makes a GET request to the endpoint at the given path, and decodes the json response into dest.
+func (client *Client) getJSON(ctx context.Context, dest any, query url.Values, path ...string) error {
+	res, err := client.get(ctx, "application/json", query, path...)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(dest); err != nil {
+		return fmt.Errorf("GET %s: failed to decode response: %w", res.Request.URL, err)
+	}
+	return nil
+}
+
+// jsonLines is the content type of JSON Lines responses.
+const jsonLines = "application/jsonl"
+
+// get makes a GET request to the endpoint at the given path, accepting the given content type if not empty.
+// If the response does not have status 200, it is closed and a [*StatusError] is returned.
+func (client *Client) get(ctx context.Context, accept string, query url.Values, path ...string) (*http.Response, error) {
+	u := client.BaseURL.JoinPath(path...)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, &StatusError{Method: req.Method, URL: u.String(), StatusCode: res.StatusCode, Status: res.Status}
+	}
+	return res, nil
+}
+
+var (
+	// ErrNotFound is matched by a [*StatusError] with status 404, see [errors.Is].
+	ErrNotFound = errors.New("not found")
+
+	// ErrInternalServerError is matched by a [*StatusError] with status 500, see [errors.Is].
+	ErrInternalServerError = errors.New("internal server error")
+)
+
+// StatusError is returned when the server responds with an unexpected status.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %q", se.Method, se.URL, se.Status)
+}
+
+// Is checks if target is [ErrNotFound] or [ErrInternalServerError] and matches the status code of this error.
+func (se *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return se.StatusCode == http.StatusNotFound
+	case ErrInternalServerError:
+		return se.StatusCode == http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+//spellchecker:words client
+package client_test
+
+//spellchecker:words bytes encoding json errors http httptest path filepath slices testing github glebarez sqlite zerolog faulunch client internal export location ltime plan gorm gormlogger
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/client"
+	"github.com/tkw1536/faulunch/internal/export"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/plan"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var (
+	monday  = ltime.ParseDay("1760911200")
+	tuesday = ltime.ParseDay("1760997600")
+)
+
+// newServer starts a server holding the recorded menus of mensa-sued and returns a client for it.
+func newServer(t *testing.T) (*client.Client, faulunch.API) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "client.db")), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := faulunch.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := zerolog.Nop()
+	corpus := filepath.Join("..", "testdata", "corpus")
+	if _, err := faulunch.Sync(&logger, db, readPlan(t, filepath.Join(corpus, "mensa-sued.de.xml")), readPlan(t, filepath.Join(corpus, "mensa-sued.en.xml"))); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if err := faulunch.RefreshComputedFields(t.Context(), &logger, db); err != nil {
+		t.Fatalf("failed to refresh computed fields: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	copier, closer := export.NewExporter(t.Context(), &logger, sqlDB, "SELECT COUNT(*) FROM menu_items")
+	t.Cleanup(func() { closer() })
+
+	api := faulunch.API{DB: db, Copier: copier}
+	server := httptest.NewServer(&faulunch.Server{API: api, Logger: &logger, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }})
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.URL + "/api/v1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c, api
+}
+
+func readPlan(t *testing.T, path string) (p plan.Plan) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read plan: %v", err)
+	}
+	if err := xml.Unmarshal(data, &p); err != nil {
+		t.Fatalf("failed to decode plan %q: %v", path, err)
+	}
+	return p
+}
+
+func TestNew(t *testing.T) {
+	if _, err := client.New("/api/v1"); err == nil {
+		t.Error("New() with relative url did not fail")
+	}
+}
+
+func TestClient_Locations(t *testing.T) {
+	c, _ := newServer(t)
+
+	locations, err := c.Locations(t.Context())
+	if err != nil {
+		t.Fatalf("Locations() error = %v", err)
+	}
+	if len(locations) != 1 || locations[0].ID != location.MensaSued || locations[0].Name != location.MensaSued.Description().Name {
+		t.Errorf("Locations() = %v, want only %q", locations, location.MensaSued)
+	}
+}
+
+func TestClient_Days(t *testing.T) {
+	c, _ := newServer(t)
+
+	tests := []struct {
+		name  string
+		from  ltime.Day
+		count int
+		want  []ltime.Day
+	}{
+		{"both days", monday.Add(-1), 7, []ltime.Day{tuesday, monday}},
+		{"first day only", monday, 1, []ltime.Day{monday}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := c.Days(t.Context(), location.MensaSued, tt.from, tt.count)
+			if err != nil {
+				t.Fatalf("Days() error = %v", err)
+			}
+			if !slices.Equal(days, tt.want) {
+				t.Errorf("Days() = %v, want %v", days, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.Days(t.Context(), "nowhere", monday, 7); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Days() of unknown location error = %v, want %v", err, client.ErrNotFound)
+	}
+}
+
+func TestClient_MenuDays(t *testing.T) {
+	c, api := newServer(t)
+
+	mondayItems, err := api.MenuItems(location.MensaSued, monday)
+	if err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query client.DaysQuery
+		want  []ltime.Day
+	}{
+		{"newest first", client.DaysQuery{From: monday.Add(-1), To: monday.Add(7)}, []ltime.Day{tuesday, monday}},
+		{"oldest first", client.DaysQuery{From: monday.Add(-1), To: monday.Add(7), Ascending: true}, []ltime.Day{monday, tuesday}},
+		{"range", client.DaysQuery{From: tuesday, To: tuesday}, []ltime.Day{tuesday}},
+		{"paginated", client.DaysQuery{From: monday.Add(-1), To: monday.Add(7), Limit: 1, Items: true}, []ltime.Day{tuesday, monday}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var days []ltime.Day
+			query := tt.query
+			for {
+				page, err := c.MenuDays(t.Context(), location.MensaSued, query)
+				if err != nil {
+					t.Fatalf("MenuDays() error = %v", err)
+				}
+				if tt.query.Limit != 0 && len(page.Days) > tt.query.Limit {
+					t.Fatalf("MenuDays() returned %d days, want at most %d", len(page.Days), tt.query.Limit)
+				}
+
+				for _, day := range page.Days {
+					days = append(days, day.Day)
+					if day.Count == 0 {
+						t.Errorf("MenuDays() returned day %v without items", day.Day)
+					}
+					if got, want := len(day.Items) > 0, tt.query.Items; got != want {
+						t.Errorf("MenuDays() included items = %v, want %v", got, want)
+					}
+					if day.Day == monday && tt.query.Items && len(day.Items) != len(mondayItems) {
+						t.Errorf("MenuDays() returned %d items on monday, want %d", len(day.Items), len(mondayItems))
+					}
+				}
+
+				if page.Next == "" {
+					break
+				}
+				query.After = page.Next
+			}
+
+			if !slices.Equal(days, tt.want) {
+				t.Errorf("MenuDays() = %v, want %v", days, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.MenuDays(t.Context(), location.MensaSued, client.DaysQuery{From: tuesday, To: monday}); err == nil {
+		t.Error("MenuDays() with empty range did not fail")
+	}
+}
+
+func TestClient_Menus(t *testing.T) {
+	c, api := newServer(t)
+
+	var got []faulunch.LocationMenuDay
+	err := c.Menus(t.Context(), nil, client.DaysQuery{From: monday.Add(-1), To: monday.Add(7), Ascending: true, Items: true}, func(menu faulunch.LocationMenuDay) error {
+		got = append(got, menu)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Menus() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].Day != monday || got[1].Day != tuesday {
+		t.Fatalf("Menus() = %v, want monday and tuesday", got)
+	}
+	for _, menu := range got {
+		want, err := api.MenuItems(location.MensaSued, menu.Day)
+		if err != nil {
+			t.Fatalf("MenuItems() error = %v", err)
+		}
+		if menu.Location != string(location.MensaSued) || menu.Count != len(want) || len(menu.Items) != len(want) {
+			t.Errorf("Menus() returned %d items at %q on %v, want %d at %q", len(menu.Items), menu.Location, menu.Day, len(want), location.MensaSued)
+		}
+		for _, item := range menu.Items {
+			if item.Location != location.MensaSued || item.Day != menu.Day {
+				t.Errorf("Menus() returned item at %q on %v, want %q on %v", item.Location, item.Day, location.MensaSued, menu.Day)
+			}
+		}
+	}
+
+	var limited []ltime.Day
+	err = c.Menus(t.Context(), nil, client.DaysQuery{From: monday.Add(-1), To: monday.Add(7), Limit: 1}, func(menu faulunch.LocationMenuDay) error {
+		limited = append(limited, menu.Day)
+		return nil
+	})
+	if err != nil || !slices.Equal(limited, []ltime.Day{tuesday}) {
+		t.Errorf("Menus() with limit = %v, %v, want tuesday", limited, err)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = c.Menus(t.Context(), []location.Location{location.MensaSued}, client.DaysQuery{From: monday.Add(-1), To: monday.Add(7)}, func(menu faulunch.LocationMenuDay) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("Menus() with failing yield = %v after %d calls, want %v after 1 call", err, calls, errStop)
+	}
+
+	if err := c.Menus(t.Context(), []location.Location{"nowhere"}, client.DaysQuery{}, func(faulunch.LocationMenuDay) error { return nil }); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Menus() of unknown location error = %v, want %v", err, client.ErrNotFound)
+	}
+}
+
+func TestClient_Menu(t *testing.T) {
+	c, api := newServer(t)
+
+	want, err := api.MenuItems(location.MensaSued, monday)
+	if err != nil {
+		t.Fatalf("MenuItems() error = %v", err)
+	}
+
+	got, err := c.Menu(t.Context(), location.MensaSued, monday)
+	if err != nil {
+		t.Fatalf("Menu() error = %v", err)
+	}
+
+	// compare the json encodings, as the database ids are not part of the response
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("Menu() = %s, want %s", gotJSON, wantJSON)
+	}
+	if len(got) == 0 || got[0].Location != location.MensaSued || got[0].Day != monday {
+		t.Errorf("Menu() did not set location and day")
+	}
+
+	if _, err := c.Menu(t.Context(), location.MensaSued, monday.Add(-1)); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Menu() of closed day error = %v, want %v", err, client.ErrNotFound)
+	}
+}
+
+func TestClient_LastSync(t *testing.T) {
+	c, api := newServer(t)
+
+	// the database has never been synced
+	if _, err := c.LastSync(t.Context()); !errors.Is(err, client.ErrInternalServerError) {
+		t.Errorf("LastSync() error = %v, want %v", err, client.ErrInternalServerError)
+	}
+
+	want := faulunch.SyncEvent{Start: 1, Stop: 2, Report: faulunch.SyncReport{Warnings: []faulunch.SyncWarning{{Kind: faulunch.WarningMissingDay, Message: "missing"}}}}
+	if err := want.Store(t.Context(), api.DB); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	got, err := c.LastSync(t.Context())
+	if err != nil {
+		t.Fatalf("LastSync() error = %v", err)
+	}
+	if got.Start != want.Start || got.Stop != want.Stop || len(got.Report.Warnings) != 1 || got.Report.Warnings[0] != want.Report.Warnings[0] {
+		t.Errorf("LastSync() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_DownloadSQLite(t *testing.T) {
+	c, _ := newServer(t)
+
+	var buffer bytes.Buffer
+	if err := c.DownloadSQLite(t.Context(), &buffer); err != nil {
+		t.Fatalf("DownloadSQLite() error = %v", err)
+	}
+	if !bytes.HasPrefix(buffer.Bytes(), []byte("SQLite format 3\x00")) {
+		t.Errorf("DownloadSQLite() did not return an sqlite database")
+	}
+}
+
+// TestOpenAPI checks that all endpoints used by the client are described in the openapi specification.
+func TestOpenAPI(t *testing.T) {
+	c, _ := newServer(t)
+
+	res, err := http.Get(c.BaseURL.JoinPath("..", "openapi.json").String())
+	if err != nil {
+		t.Fatalf("failed to get openapi.json: %v", err)
+	}
+	defer res.Body.Close()
+
+	var spec struct {
+		Paths map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode openapi.json: %v", err)
+	}
+
+	for _, path := range []string{"/locations", "/menu/{location}", "/menu/{location}/{day}", "/sync", "/sqlite"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("openapi.json does not describe %q", path)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words github glebarez sqlite zerolog faulunch internal annotations location ordering gorm
+import (
+	"fmt"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ordering"
+	"gorm.io/gorm"
+)
+
+// openDatabase opens and migrates the database.
+// The returned function closes the database.
+func (env *env) openDatabase() (*gorm.DB, func() error, error) {
+	db, err := gorm.Open(sqlite.Open(env.Config.Database), &gorm.Config{})
+	env.Log.Debug().Err(err).Str("path", env.Config.Database).Msg("opening database")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	err = faulunch.Migrate(db)
+	env.Log.Debug().Err(err).Msg("migrating database")
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return db, sqlDB.Close, nil
+}
+
+// loadData loads the locations, typos and category ordering replacing the built-in ones.
+func (env *env) loadData() error {
+	return loadData(env.Log, env.Config.Data.Locations, env.Config.Data.Typos, env.Config.Data.Ordering)
+}
+
+// loadData replaces the location registry, typos and category ordering with the ones from the given paths.
+// Empty paths are skipped.
+func loadData(log *zerolog.Logger, locationsPath, typosPath, orderingPath string) error {
+	if locationsPath != "" {
+		registry, err := location.ReadRegistry(locationsPath)
+		log.Err(err).Str("path", locationsPath).Msg("loading locations")
+		if err != nil {
+			return err
+		}
+		location.Use(registry)
+	}
+
+	if typosPath != "" {
+		typos, err := annotations.ReadTypos(typosPath)
+		log.Err(err).Str("path", typosPath).Msg("loading typos")
+		if err != nil {
+			return err
+		}
+		annotations.UseTypos(typos)
+	}
+
+	if orderingPath != "" {
+		o, err := ordering.ReadOrdering(orderingPath)
+		log.Err(err).Str("path", orderingPath).Msg("loading category ordering")
+		if err != nil {
+			return err
+		}
+		ordering.Use(&o)
+	}
+
+	return nil
+}
+
+// parseLocations parses the given location arguments.
+// If there are no arguments, all locations are returned.
+func parseLocations(args []string) ([]location.Location, error) {
+	if len(args) == 0 {
+		return location.Locations(), nil
+	}
+
+	locations := make([]location.Location, len(args))
+	for i, arg := range args {
+		locations[i] = location.Location(arg)
+		if !locations[i].Valid() {
+			return nil, errUsage("unknown location %q", arg)
+		}
+	}
+	return locations, nil
+}
@@ -0,0 +1,46 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words errors github faulunch internal config export
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tkw1536/faulunch/internal/config"
+	"github.com/tkw1536/faulunch/internal/export"
+)
+
+var exportCommand = &command{
+	Name:        "export",
+	Args:        "<output>",
+	Description: "write a consistent copy of the database to a new sqlite file",
+	Options:     config.OptDatabase,
+	Run:         runExport,
+}
+
+func runExport(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage("expected exactly one output file")
+	}
+	output := args[0]
+
+	if _, err := os.Stat(output); !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("output %q already exists", output)
+	}
+
+	db, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	err = export.Vacuum(env.Context, sqlDB, output)
+	env.Log.Info().Err(err).Str("path", output).Msg("exporting database")
+	return err
+}
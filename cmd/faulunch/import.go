@@ -0,0 +1,93 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words encoding errors path filepath strings github faulunch internal config plan
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/config"
+	"github.com/tkw1536/faulunch/internal/plan"
+)
+
+var importCommand = &command{
+	Name:        "import",
+	Args:        "<directory>",
+	Description: "store recorded upstream menus (pairs of <location>.de.xml and <location>.en.xml files) in the database",
+	Options:     config.OptDatabase | config.OptData,
+	Run:         runImport,
+}
+
+var errNoPlans = errors.New("directory does not contain any plans")
+
+func runImport(env *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage("expected exactly one directory")
+	}
+	if err := env.loadData(); err != nil {
+		return err
+	}
+
+	germanFiles, err := filepath.Glob(filepath.Join(args[0], "*.de.xml"))
+	if err != nil {
+		return err
+	}
+	if len(germanFiles) == 0 {
+		return errNoPlans
+	}
+
+	db, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	// store a sync event even if the import fails part way, so that running servers drop their cached responses
+	var se faulunch.SyncEvent
+	se.Begin()
+	defer func() {
+		se.Finish()
+
+		err := se.Store(env.Context, db)
+		env.Log.Err(err).Msg("logging sync event")
+	}()
+
+	for _, germanFile := range germanFiles {
+		englishFile := strings.TrimSuffix(germanFile, ".de.xml") + ".en.xml"
+
+		german, err := readPlan(germanFile)
+		if err != nil {
+			return err
+		}
+		english, err := readPlan(englishFile)
+		if err != nil {
+			return err
+		}
+
+		warnings, err := faulunch.Sync(env.Log, db, german, english)
+		se.Report.Warnings = append(se.Report.Warnings, warnings...)
+		env.Log.Info().Err(err).Str("path", germanFile).Msg("importing plan")
+		if err != nil {
+			return err
+		}
+	}
+
+	return faulunch.RefreshComputedFields(env.Context, env.Log, db)
+}
+
+// readPlan reads the plan stored at path.
+func readPlan(path string) (p plan.Plan, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := xml.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("failed to decode plan %q: %w", path, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,38 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words text tabwriter github faulunch internal config location
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/tkw1536/faulunch/internal/config"
+	"github.com/tkw1536/faulunch/internal/location"
+)
+
+var locationsCommand = &command{
+	Name:        "locations",
+	Description: "list all known locations",
+	Options:     config.OptData,
+	Quiet:       true,
+	Run:         runLocations,
+}
+
+func runLocations(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage("unexpected arguments")
+	}
+	if err := env.loadData(); err != nil {
+		return err
+	}
+
+	registry := location.Current()
+
+	tw := tabwriter.NewWriter(env.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFEED\tTYPE\tNAME")
+	for _, loc := range registry.Locations() {
+		desc := loc.Description()
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", loc, registry.ID(loc), desc.Type(true), desc.Name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,192 @@
+// Command faulunch serves and manages the faulunch menu database.
+//
+// Usage:
+//
+//	faulunch <command> [...flags] [...args]
+//
+// Run "faulunch help" for a list of commands.
+//
+//spellchecker:words main
+package main
+
+//spellchecker:words errors flag signal slices text tabwriter time github zerolog faulunch internal config
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/internal/config"
+)
+
+// Exit codes used by all commands.
+const (
+	exitOK    = 0 // the command succeeded
+	exitError = 1 // the command failed
+	exitUsage = 2 // the command was invoked incorrectly
+)
+
+// command is a subcommand of the faulunch binary.
+type command struct {
+	Name        string
+	Aliases     []string // alternative names of the command
+	Args        string   // description of the positional arguments
+	Description string
+
+	// Options are the groups of configuration options the command accepts as flags.
+	// If Options contains [config.OptDatabase] and Optional does not, the command requires a database.
+	Options config.Options
+
+	// Optional are the groups of Options that are accepted, but not required.
+	Optional config.Options
+
+	// Flags registers additional flags of the command, if not nil.
+	Flags func(flags *flag.FlagSet)
+
+	// Quiet indicates that the command prints to the terminal, so only warnings are logged.
+	Quiet bool
+
+	// Run runs the command with the given (validated) configuration and positional arguments.
+	Run func(env *env, args []string) error
+}
+
+// env is the environment a command runs in.
+type env struct {
+	Context context.Context
+	Config  config.Config
+	Log     *zerolog.Logger
+	Stdout  io.Writer
+}
+
+// commands are all commands, in the order shown in the help text.
+var commands = []*command{
+	serveCommand,
+	syncCommand,
+	refreshCommand,
+	migrateCommand,
+	exportCommand,
+	importCommand,
+	menuCommand,
+	locationsCommand,
+}
+
+// usageError indicates that a command was invoked incorrectly.
+type usageError struct{ msg string }
+
+func (err usageError) Error() string { return err.msg }
+
+// errUsage returns a new usage error with the given message.
+func errUsage(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+// run runs the command specified by args and returns the exit code.
+func run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printHelp(stderr)
+		return exitUsage
+	}
+
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-help", "--help", "-h":
+		if len(args) == 0 {
+			printHelp(stdout)
+			return exitOK
+		}
+		name, args = args[0], []string{"-help"}
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "faulunch: unknown command %q\n", name)
+		fmt.Fprintln(stderr, "Run 'faulunch help' for usage.")
+		return exitUsage
+	}
+	return cmd.run(ctx, args, getenv, stdout, stderr)
+}
+
+// findCommand returns the command with the given name, or nil.
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name || slices.Contains(cmd.Aliases, name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprintln(w, "Usage: faulunch <command> [...flags] [...args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.Name, cmd.Description)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'faulunch help <command>' for the flags of a command.")
+	fmt.Fprintln(w, "Flags may also be set using a configuration file or environment variables.")
+}
+
+// run loads the configuration and runs this command.
+func (cmd *command) run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	cfg, args, err := config.Load("faulunch "+cmd.Name, cmd.Args, cmd.Options, cmd.Flags, args, getenv, stderr)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, cmd.Description)
+		return exitOK
+	case errors.Is(err, config.ErrInvalidFlags):
+		return exitUsage
+	case err != nil:
+		fmt.Fprintf(stderr, "faulunch %s: %v\n", cmd.Name, err)
+		return exitUsage
+	}
+
+	if err := cfg.Validate(cmd.Options &^ cmd.Optional); err != nil {
+		fmt.Fprintf(stderr, "faulunch %s: invalid configuration: %v\n", cmd.Name, err)
+		return exitUsage
+	}
+
+	output := zerolog.ConsoleWriter{Out: stderr, TimeFormat: time.Stamp}
+	log := zerolog.New(output).With().Timestamp().Logger()
+	switch {
+	case cfg.Debug:
+		log = log.Level(zerolog.DebugLevel)
+	case cmd.Quiet:
+		log = log.Level(zerolog.WarnLevel)
+	default:
+		log = log.Level(zerolog.InfoLevel)
+	}
+
+	err = cmd.Run(&env{Context: ctx, Config: cfg, Log: &log, Stdout: stdout}, args)
+	var ue usageError
+	switch {
+	case errors.As(err, &ue):
+		fmt.Fprintf(stderr, "faulunch %s: %v\n", cmd.Name, err)
+		fmt.Fprintf(stderr, "Run 'faulunch help %s' for usage.\n", cmd.Name)
+		return exitUsage
+	case err != nil:
+		fmt.Fprintf(stderr, "faulunch %s: %v\n", cmd.Name, err)
+		return exitError
+	}
+	return exitOK
+}
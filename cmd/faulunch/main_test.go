@@ -0,0 +1,154 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words bytes http httptest path filepath strings testing github glebarez sqlite zerolog faulunch gorm gormlogger
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestRun(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "test.db")
+	getenv := func(name string) string {
+		if name == "FAULUNCH_DATABASE" {
+			return database
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"no command", nil, exitUsage, "", "Commands:"},
+		{"help", []string{"help"}, exitOK, "Commands:", ""},
+		{"command help", []string{"help", "menu"}, exitOK, "", "Usage: faulunch menu"},
+		{"unknown command", []string{"bogus"}, exitUsage, "", `unknown command "bogus"`},
+		{"unknown flag", []string{"locations", "-addr", ":80"}, exitUsage, "", "flag provided but not defined: -addr"},
+		{"unexpected argument", []string{"locations", "extra"}, exitUsage, "", "unexpected arguments"},
+		{"unknown location", []string{"sync", "nowhere"}, exitUsage, "", `unknown location "nowhere"`},
+		{"invalid day", []string{"menu", "mensa-sued", "soon"}, exitUsage, "", `invalid day "soon"`},
+		{"locations", []string{"locations"}, exitOK, "mensa-sued", ""},
+		{"migrate", []string{"migrate"}, exitOK, "", ""},
+		{"export existing", []string{"export", database}, exitError, "", "already exists"},
+		{"closed", []string{"menu", "mensa-sued", "2025-10-20"}, exitOK, "is closed on Monday, 20th October 2025", ""},
+		{"alias", []string{"query", "mensa-sued", "2025-10-20"}, exitOK, "is closed on Monday, 20th October 2025", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(t.Context(), tt.args, getenv, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("run() stdout = %q, want to contain %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("run() stderr = %q, want to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_menu(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "menu.db")
+	getenv := func(name string) string {
+		if name == "FAULUNCH_DATABASE" {
+			return database
+		}
+		return ""
+	}
+
+	var stderr bytes.Buffer
+	if code := run(t.Context(), []string{"import", filepath.Join("..", "..", "testdata", "corpus")}, getenv, &bytes.Buffer{}, &stderr); code != exitOK {
+		t.Fatalf("import = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+
+	db, err := gorm.Open(sqlite.Open(database), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := (&faulunch.API{DB: db}).LastSync(t.Context()); err != nil {
+		t.Errorf("import did not store a sync event: %v", err)
+	}
+
+	logger := zerolog.Nop()
+	server := httptest.NewServer(&faulunch.Server{API: faulunch.API{DB: db}, Logger: &logger, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }})
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		dontWant []string
+	}{
+		{
+			"table",
+			[]string{"menu", "mensa-sued", "2025-10-20"},
+			[]string{"Menu for Südmensa on Monday, 20th October 2025", "Pork schnitzel (cereals containing gluten wheat (spelt, kamut), eggs, milk/lactose)", "3.40 €", "[Vegan] [Gluten-Free]"},
+			nil,
+		},
+		{
+			"german guest prices",
+			[]string{"menu", "-lang", "de", "-tier", "guest", "mensa-sued", "2025-10-20"},
+			[]string{"Menü für Südmensa", "Schweineschnitzel", "6,80 €", "[Glutenfrei]"},
+			nil,
+		},
+		{
+			"allergen filter",
+			[]string{"menu", "-allergens", "Mi", "mensa-sued", "2025-10-20"},
+			[]string{"Tomato soup"},
+			[]string{"Pork schnitzel"},
+		},
+		{
+			"json",
+			[]string{"menu", "-json", "-diet", "vegan", "mensa-sued", "2025-10-20"},
+			[]string{`"TitleEN": "Vegetable curry (So,Sel1) with basmati rice"`},
+			[]string{"Linsensuppe"},
+		},
+		{
+			"remote",
+			[]string{"menu", "-database", "", "-remote", server.URL + "/api/v1", "mensa-sued", "2025-10-20"},
+			[]string{"Menu for Südmensa on Monday, 20th October 2025", "Pork schnitzel", "3.40 €"},
+			nil,
+		},
+		{
+			"remote closed",
+			[]string{"menu", "-database", "", "-remote", server.URL + "/api/v1", "mensa-sued", "2025-10-19"},
+			[]string{"Südmensa is closed on Sunday, 19th October 2025"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(t.Context(), tt.args, getenv, &stdout, &stderr); code != exitOK {
+				t.Fatalf("run() = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("run() stdout = %q, want to contain %q", stdout.String(), want)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(stdout.String(), dontWant) {
+					t.Errorf("run() stdout = %q, want to not contain %q", stdout.String(), dontWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words context encoding json errors flag iter slices strings text tabwriter github faulunch client internal annotations config location ltime
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/client"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/config"
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+var menuCommand = &command{
+	Name:        "menu",
+	Aliases:     []string{"query"},
+	Args:        "<location> [day]",
+	Description: "print the menu of a location on a day (default today) from the database or a remote server",
+	Options:     config.OptDatabase | config.OptData,
+	Optional:    config.OptDatabase,
+	Quiet:       true,
+	Flags: func(flags *flag.FlagSet) {
+		flags.StringVar(&menuFlags.remote, "remote", "", "base `url` of the api of a remote faulunch server to use instead of the database, such as \"https://example.com/api/v1\"")
+		flags.StringVar(&menuFlags.lang, "lang", i18n.English.Code(), "`language` to print the menu in")
+		flags.StringVar(&menuFlags.tier, "tier", "student", "price `tier` to show, one of \"student\", \"employee\" or \"guest\"")
+		flags.BoolVar(&menuFlags.json, "json", false, "print the menu items as json instead of a table")
+		flags.StringVar(&menuFlags.diets, "diet", "", "comma-separated `diets` to show, such as \"vegetarian,vegan\"")
+		flags.StringVar(&menuFlags.allergens, "allergens", "", "comma-separated `codes` of allergens to hide items containing, such as \"Wz,Mi\"")
+		flags.StringVar(&menuFlags.profile, "profile", "", "dietary profile `token` to hide unsuitable items, as found in the shareable link of the profile page")
+	},
+	Run: runMenu,
+}
+
+var menuFlags struct {
+	remote    string
+	lang      string
+	tier      string
+	json      bool
+	diets     string
+	allergens string
+	profile   string
+}
+
+func runMenu(env *env, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errUsage("expected a location and an optional day")
+	}
+
+	day := ltime.Today()
+	if len(args) == 2 {
+		var err error
+		if day, err = parseDay(args[1]); err != nil {
+			return err
+		}
+	}
+	lang, ok := i18n.Lookup(menuFlags.lang)
+	if !ok {
+		return errUsage("unknown language %q", menuFlags.lang)
+	}
+	price, ok := priceTiers[menuFlags.tier]
+	if !ok {
+		return errUsage("unknown price tier %q", menuFlags.tier)
+	}
+	profile, err := menuProfile()
+	if err != nil {
+		return err
+	}
+
+	var source menuSource
+	switch {
+	case menuFlags.remote != "":
+		c, err := client.New(menuFlags.remote)
+		if err != nil {
+			return errUsage("invalid remote url %q", menuFlags.remote)
+		}
+		source = remoteSource{client: c}
+	case env.Config.Database != "":
+		if err := env.loadData(); err != nil {
+			return err
+		}
+		db, closeDB, err := env.openDatabase()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+		source = localSource{api: faulunch.API{DB: db}}
+	default:
+		return errUsage("either a database or a remote url is required")
+	}
+
+	loc := location.Location(args[0])
+	name, err := source.Name(env.Context, loc)
+	if err != nil {
+		return err
+	}
+	items, err := source.Menu(env.Context, loc, day)
+	if err != nil {
+		return err
+	}
+	items = slices.DeleteFunc(items, func(item faulunch.MenuItem) bool {
+		return profile.Check(item).Unsuitable()
+	})
+
+	if menuFlags.json {
+		encoder := json.NewEncoder(env.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(env.Stdout, lang.Sprintf("closed.description", name, lang.Date(day)))
+		return nil
+	}
+	fmt.Fprintln(env.Stdout, lang.Sprintf("menu.description", name, lang.Date(day)))
+	fmt.Fprintln(env.Stdout)
+
+	resolve := func(text string) string {
+		return annotations.Resolve(text, func(annot annotations.Annotation) string { return lang.Name(annot) })
+	}
+
+	tw := tabwriter.NewWriter(env.Stdout, 0, 4, 2, ' ', 0)
+	for _, item := range items {
+		category, title := item.CategoryEN, item.TitleEN
+		if lang.German() {
+			category, title = item.Category, item.TitleDE
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s €\t%s\n", category, resolve(title), lang.Number(price(item)), strings.Join(menuBadges(lang, item), " "))
+	}
+	return tw.Flush()
+}
+
+// priceTiers maps the names of price tiers to the corresponding price of an item.
+var priceTiers = map[string]func(item faulunch.MenuItem) types.LPrice{
+	"student":  func(item faulunch.MenuItem) types.LPrice { return item.Preis1 },
+	"employee": func(item faulunch.MenuItem) types.LPrice { return item.Preis2 },
+	"guest":    func(item faulunch.MenuItem) types.LPrice { return item.Preis3 },
+}
+
+// menuBadges returns the badges to show next to an item.
+func menuBadges(lang i18n.Language, item faulunch.MenuItem) (badges []string) {
+	if item.DietaryCategory.IsRestricted() {
+		badges = append(badges, "["+lang.Name(item.DietaryCategory)+"]")
+	}
+	if item.GlutenFree {
+		badges = append(badges, "["+lang.Sprintf("badge.gluten-free")+"]")
+	}
+	if item.Edited {
+		badges = append(badges, "["+lang.Sprintf("badge.edited")+"]")
+	}
+	return badges
+}
+
+// menuProfile builds the profile used to filter items from the flags.
+func menuProfile() (faulunch.Profile, error) {
+	profile, err := faulunch.ParseProfile(menuFlags.profile)
+	if err != nil {
+		return profile, errUsage("%v", err)
+	}
+
+	for diet := range splitList(menuFlags.diets) {
+		d := faulunch.DietaryCategory(diet)
+		if !slices.Contains(faulunch.DietaryCategories(), d) {
+			return profile, errUsage("unknown diet %q", diet)
+		}
+		profile.Diets = append(profile.Diets, d)
+	}
+	for code := range splitList(menuFlags.allergens) {
+		a, ok := annotations.Allergen(code).Normalize()
+		if !ok {
+			return profile, errUsage("unknown allergen %q", code)
+		}
+		profile.Allergens = append(profile.Allergens, a)
+	}
+	return profile, nil
+}
+
+// splitList iterates over the non-empty elements of a comma-separated list.
+func splitList(list string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for elem := range strings.SplitSeq(list, ",") {
+			elem = strings.TrimSpace(elem)
+			if elem != "" && !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// parseDay parses a day given on the command line.
+func parseDay(value string) (ltime.Day, error) {
+	switch value {
+	case "today":
+		return ltime.Today(), nil
+	case "tomorrow":
+		return ltime.Today().Add(1), nil
+	}
+
+	if day, err := ltime.ParseDate(value); err == nil {
+		return day, nil
+	}
+	if day := ltime.ParseDay(value); day > 0 {
+		return day.Normalize(), nil
+	}
+	return 0, errUsage("invalid day %q", value)
+}
+
+// menuSource provides the menus printed by the menu command.
+type menuSource interface {
+	// Name returns the name of the given location.
+	Name(ctx context.Context, loc location.Location) (string, error)
+
+	// Menu returns the menu items of the given location on the given day.
+	Menu(ctx context.Context, loc location.Location, day ltime.Day) ([]faulunch.MenuItem, error)
+}
+
+// localSource reads menus from the database.
+type localSource struct {
+	api faulunch.API
+}
+
+func (ls localSource) Name(ctx context.Context, loc location.Location) (string, error) {
+	if !loc.Valid() {
+		return "", errUsage("unknown location %q", loc)
+	}
+	return loc.Description().Name, nil
+}
+
+func (ls localSource) Menu(ctx context.Context, loc location.Location, day ltime.Day) ([]faulunch.MenuItem, error) {
+	return ls.api.MenuItems(loc, day)
+}
+
+// remoteSource reads menus from the api of a remote server.
+type remoteSource struct {
+	client *client.Client
+}
+
+func (rs remoteSource) Name(ctx context.Context, loc location.Location) (string, error) {
+	locations, err := rs.client.Locations(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	index := slices.IndexFunc(locations, func(l client.Location) bool { return l.ID == loc })
+	if index < 0 {
+		return "", errUsage("unknown location %q", loc)
+	}
+	return locations[index].Name, nil
+}
+
+func (rs remoteSource) Menu(ctx context.Context, loc location.Location, day ltime.Day) ([]faulunch.MenuItem, error) {
+	items, err := rs.client.Menu(ctx, loc, day)
+	if errors.Is(err, client.ErrNotFound) {
+		// the server responds with not found if there is no menu
+		return nil, nil
+	}
+	return items, err
+}
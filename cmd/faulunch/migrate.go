@@ -0,0 +1,28 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words github faulunch internal config
+import (
+	"github.com/tkw1536/faulunch/internal/config"
+)
+
+var migrateCommand = &command{
+	Name:        "migrate",
+	Description: "create or update the tables of the database",
+	Options:     config.OptDatabase,
+	Run:         runMigrate,
+}
+
+func runMigrate(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage("unexpected arguments")
+	}
+
+	// opening the database migrates it
+	_, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	env.Log.Info().Str("path", env.Config.Database).Msg("migrated database")
+	return closeDB()
+}
@@ -0,0 +1,39 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words github faulunch internal config
+import (
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/config"
+)
+
+var refreshCommand = &command{
+	Name:        "refresh",
+	Description: "recompute all computed fields of the menu items in the database",
+	Options:     config.OptDatabase | config.OptData,
+	Run:         runRefresh,
+}
+
+func runRefresh(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage("unexpected arguments")
+	}
+	if err := env.loadData(); err != nil {
+		return err
+	}
+
+	db, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	// store a sync event, so that running servers drop their cached responses
+	var se faulunch.SyncEvent
+	se.Begin()
+	if err := faulunch.RefreshComputedFields(env.Context, env.Log, db); err != nil {
+		return err
+	}
+	se.Finish()
+	return se.Store(env.Context, db)
+}
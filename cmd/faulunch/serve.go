@@ -0,0 +1,181 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words http signal regexp strings syscall time github tdewolff minify html faulunch internal config export
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tdewolff/minify"
+	"github.com/tdewolff/minify/css"
+	"github.com/tdewolff/minify/html"
+	"github.com/tdewolff/minify/js"
+	"github.com/tdewolff/minify/xml"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/config"
+	"github.com/tkw1536/faulunch/internal/export"
+)
+
+var serveCommand = &command{
+	Name:        "serve",
+	Description: "serve the website and api, optionally syncing menus periodically",
+	Options:     config.OptAll,
+	Run:         runServe,
+}
+
+func runServe(env *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage("unexpected arguments")
+	}
+	cfg, log := env.Config, env.Log
+
+	if err := env.loadData(); err != nil {
+		return err
+	}
+
+	db, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	// start automatically syncing if requested
+	if cfg.Sync.Interval > 0 {
+		client := cfg.Fetcher.Client()
+		go func() {
+			for {
+				// reload locations, typos and category ordering, keeping the previous ones on failure
+				env.loadData()
+
+				failed := faulunch.FetchAndSyncAll(env.Context, log, db, client)
+				if failed {
+					log.Error().Msg("failed to sync")
+				}
+
+				select {
+				case <-env.Context.Done():
+					return
+				case <-time.After(time.Duration(cfg.Sync.Interval)):
+				}
+			}
+		}()
+	} else {
+		faulunch.RefreshComputedFields(env.Context, log, db)
+	}
+
+	var copier func(w http.ResponseWriter, r *http.Request) error
+	if cfg.Export.Enabled {
+		log.Info().Msg("enabling sqlite database export")
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		var closer func() error
+		copier, closer = export.NewExporter(env.Context, log, sqlDB, "SELECT MAX(stop) FROM sync_events")
+		defer func() {
+			err := closer()
+			if err == nil {
+				return
+			}
+			log.Err(err).Msg("failed to remove temporary database export")
+		}()
+	}
+
+	// create a handler
+	lunch := &faulunch.Server{
+		API: faulunch.API{
+			DB:     db,
+			Copier: copier,
+		},
+		Logger: log,
+		Legal: faulunch.ServerLegal{
+			Link:     cfg.Legal.Link,
+			DEString: cfg.Legal.DE,
+			ENString: cfg.Legal.EN,
+		},
+		AdminToken:  cfg.Server.AdminToken,
+		CacheMaxAge: time.Duration(cfg.Cache.MaxAge),
+		CacheSize:   cfg.Cache.Size,
+	}
+	var handler http.Handler = lunch
+
+	// reload locations, typos and category ordering on SIGHUP, keeping the previous ones on failure
+	go func() {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+
+		for {
+			select {
+			case <-env.Context.Done():
+				return
+			case <-reload:
+			}
+
+			env.loadData()
+			lunch.InvalidateCache()
+		}
+	}()
+
+	if cfg.Server.Minify {
+		m := minify.New()
+		m.AddFunc("text/css", css.Minify)
+		m.AddFunc("text/html", html.Minify)
+		m.AddFuncRegexp(regexp.MustCompile("^(application|text)/(x-)?(java|ecma)script$"), js.Minify)
+		m.AddFuncRegexp(regexp.MustCompile("[/+]xml$"), xml.Minify) // for MathML
+
+		regular := handler
+		minify := m.Middleware(regular)
+
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// prevent api resources from being minified
+			if r.URL.Path != "/api/" && strings.HasPrefix(r.URL.Path, "/api/") {
+				regular.ServeHTTP(w, r)
+				return
+			}
+			minify.ServeHTTP(w, r)
+		})
+	}
+
+	// start listening
+	server := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
+	}
+
+	// allow graceful shutdown on interrupt
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		<-env.Context.Done()
+		log.Info().Str("addr", cfg.Server.Addr).Msg("server shutting down")
+
+		timeout, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+		defer cancel()
+		if err := server.Shutdown(timeout); err != nil {
+			log.Err(err).Str("addr", cfg.Server.Addr).Msg("server failed to shutdown")
+		}
+	}()
+
+	log.Info().Str("addr", cfg.Server.Addr).Bool("minify", cfg.Server.Minify).Msg("server listening")
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	<-done
+	log.Info().Msg("server shutdown complete")
+	return nil
+}
@@ -0,0 +1,42 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words errors github faulunch internal config
+import (
+	"errors"
+
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/config"
+)
+
+var syncCommand = &command{
+	Name:        "sync",
+	Args:        "[<location>...]",
+	Description: "fetch the menus of the given (or all) locations from upstream and store them in the database",
+	Options:     config.OptDatabase | config.OptFetcher | config.OptData,
+	Run:         runSync,
+}
+
+var errSyncFailed = errors.New("failed to sync at least one location")
+
+func runSync(env *env, args []string) error {
+	if err := env.loadData(); err != nil {
+		return err
+	}
+
+	locations, err := parseLocations(args)
+	if err != nil {
+		return err
+	}
+
+	db, closeDB, err := env.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if faulunch.FetchAndSyncLocations(env.Context, env.Log, db, env.Config.Fetcher.Client(), locations) {
+		return errSyncFailed
+	}
+	return nil
+}
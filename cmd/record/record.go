@@ -0,0 +1,87 @@
+//spellchecker:words main
+package main
+
+//spellchecker:words flag http path filepath signal time github zerolog faulunch internal location plan
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/plan"
+)
+
+var globalContext context.Context
+
+func init() {
+	globalContext, _ = signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// main records the upstream xml of the given (or all) locations into the corpus directory.
+// The corpus is used by the replay tests in the root package.
+func main() {
+	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.Stamp}
+	log := zerolog.New(output).With().Timestamp().Logger()
+
+	locations := location.Locations()
+	if args := flag.Args(); len(args) > 0 {
+		locations = make([]location.Location, len(args))
+		for i, arg := range args {
+			locations[i] = location.Location(arg)
+			if !locations[i].Valid() {
+				log.Fatal().Str("location", arg).Msg("unknown location")
+			}
+		}
+	}
+
+	if err := os.MkdirAll(flagOut, 0755); err != nil {
+		log.Fatal().Err(err).Str("out", flagOut).Msg("creating output directory")
+	}
+
+	failed := false
+	for _, loc := range locations {
+		for _, english := range []bool{false, true} {
+			if err := record(loc, english); err != nil {
+				log.Err(err).Str("location", string(loc)).Bool("english", english).Msg("recording plan")
+				failed = true
+				continue
+			}
+			log.Info().Str("location", string(loc)).Bool("english", english).Msg("recorded plan")
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// record records the plan for a single location and language.
+func record(loc location.Location, english bool) error {
+	data, err := plan.FetchRaw(globalContext, http.DefaultClient, loc, english)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(corpusPath(flagOut, loc, english), data, 0644)
+}
+
+// corpusPath returns the path of the xml for the given location and language inside the corpus directory.
+func corpusPath(dir string, loc location.Location, english bool) string {
+	lang := "de"
+	if english {
+		lang = "en"
+	}
+	return filepath.Join(dir, string(loc)+"."+lang+".xml")
+}
+
+var flagOut string = filepath.Join("testdata", "corpus")
+
+func init() {
+	defer flag.Parse()
+
+	flag.StringVar(&flagOut, "out", flagOut, "directory to write recorded xml files to")
+}
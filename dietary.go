@@ -1,6 +1,10 @@
 package faulunch
 
-import "github.com/tkw1536/faulunch/internal/annotations"
+import (
+	"slices"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
 
 func (m *MenuItem) getDietaryCategory() DietaryCategory {
 	ingredients := m.IngredientAnnotations.Data()
@@ -54,10 +58,31 @@ const (
 	DietaryCategoryVegan      DietaryCategory = "vegan"
 )
 
+// dietaryCategories holds all dietary categories in display order.
+var dietaryCategories = []DietaryCategory{
+	DietaryCategoryMeat, DietaryCategoryFish, DietaryCategoryVegetarian, DietaryCategoryVegan,
+}
+
+// DietaryCategories returns all dietary categories in display order.
+func DietaryCategories() []DietaryCategory {
+	return slices.Clone(dietaryCategories)
+}
+
+// Satisfies checks if an item of this category is suitable for someone preferring the given diet.
+// Vegan items are also vegetarian, every other category only satisfies itself.
+func (d DietaryCategory) Satisfies(diet DietaryCategory) bool {
+	return d == diet || (d == DietaryCategoryVegan && diet == DietaryCategoryVegetarian)
+}
+
 func (d DietaryCategory) IsRestricted() bool {
 	return d != "" && d != DietaryCategoryMeat
 }
 
+// MessageKey returns the key of the message translating this category.
+func (d DietaryCategory) MessageKey() string {
+	return "diet." + string(d)
+}
+
 func (d DietaryCategory) ENString() string {
 	switch d {
 	case DietaryCategoryMeat:
@@ -85,3 +110,174 @@ func (d DietaryCategory) DEString() string {
 	}
 	panic("unknown dietary category")
 }
+
+// DietaryLabels holds a multi-label dietary classification of a menu item.
+// Each label is stored in its own column, so that it can be queried directly.
+//
+// Free-from labels are only set if the item declares allergens and all of its annotations are known.
+// Missing or incomplete data never results in a free-from label.
+type DietaryLabels struct {
+	DietaryUnknown bool // pictograms are missing, or some annotations or pictograms are unknown
+
+	ContainsPork    bool // contains pork according to the pictograms
+	ContainsBeef    bool // contains beef according to the pictograms
+	ContainsPoultry bool // contains poultry according to the pictograms
+	ContainsLamb    bool // contains lamb according to the pictograms
+	ContainsGame    bool // contains game according to the pictograms
+
+	LactoseFree     bool // allergens are known and no milk allergen is declared
+	EggFree         bool // allergens are known and no egg allergen is declared
+	NutFree         bool // allergens are known and no nut or peanut allergen is declared
+	HalalCompatible bool // pictograms and annotations are known and neither pork nor alcohol is declared
+}
+
+func (m *MenuItem) extractDietaryLabels() {
+	m.DietaryLabels = m.getDietaryLabels()
+}
+
+func (m MenuItem) getDietaryLabels() (labels DietaryLabels) {
+	pictograms := len(m.Piktogramme.Data()) > 0
+	complete := len(m.UnknownTokens.Data()) == 0 && len(m.UnknownPictograms.Data()) == 0
+	labels.DietaryUnknown = !pictograms || !complete
+
+	// free-from labels require allergen data, and no annotation that might be a missing allergen
+	if allergens := m.AllergenAnnotations.Data(); len(allergens) > 0 && complete {
+		labels.LactoseFree, labels.EggFree, labels.NutFree = true, true, true
+		for _, allergen := range allergens {
+			switch allergen.EU() {
+			case annotations.EUMilk:
+				labels.LactoseFree = false
+			case annotations.EUEggs:
+				labels.EggFree = false
+			case annotations.EUNuts, annotations.EUPeanuts:
+				labels.NutFree = false
+			}
+		}
+	}
+
+	// meat labels require pictograms
+	if !pictograms {
+		return labels
+	}
+
+	alcohol := false
+	for _, ing := range m.IngredientAnnotations.Data() {
+		switch ing {
+		case annotations.Pork:
+			labels.ContainsPork = true
+		case annotations.Beef:
+			labels.ContainsBeef = true
+		case annotations.Poultry:
+			labels.ContainsPoultry = true
+		case annotations.Lamb:
+			labels.ContainsLamb = true
+		case annotations.Game:
+			labels.ContainsGame = true
+		case annotations.Alcohol:
+			alcohol = true
+		}
+	}
+	labels.HalalCompatible = complete && !labels.ContainsPork && !alcohol
+	return labels
+}
+
+// DietaryLabel is a single label of [DietaryLabels].
+type DietaryLabel string
+
+// Different dietary labels
+const (
+	DietaryLabelUnknown         DietaryLabel = "unknown"
+	DietaryLabelPork            DietaryLabel = "pork"
+	DietaryLabelBeef            DietaryLabel = "beef"
+	DietaryLabelPoultry         DietaryLabel = "poultry"
+	DietaryLabelLamb            DietaryLabel = "lamb"
+	DietaryLabelGame            DietaryLabel = "game"
+	DietaryLabelLactoseFree     DietaryLabel = "lactose-free"
+	DietaryLabelEggFree         DietaryLabel = "egg-free"
+	DietaryLabelNutFree         DietaryLabel = "nut-free"
+	DietaryLabelHalalCompatible DietaryLabel = "halal-compatible"
+)
+
+// Labels returns the labels that are set, in a consistent order.
+func (labels DietaryLabels) Labels() []DietaryLabel {
+	flags := []struct {
+		set   bool
+		label DietaryLabel
+	}{
+		{labels.DietaryUnknown, DietaryLabelUnknown},
+		{labels.ContainsPork, DietaryLabelPork},
+		{labels.ContainsBeef, DietaryLabelBeef},
+		{labels.ContainsPoultry, DietaryLabelPoultry},
+		{labels.ContainsLamb, DietaryLabelLamb},
+		{labels.ContainsGame, DietaryLabelGame},
+		{labels.LactoseFree, DietaryLabelLactoseFree},
+		{labels.EggFree, DietaryLabelEggFree},
+		{labels.NutFree, DietaryLabelNutFree},
+		{labels.HalalCompatible, DietaryLabelHalalCompatible},
+	}
+
+	var result []DietaryLabel
+	for _, flag := range flags {
+		if flag.set {
+			result = append(result, flag.label)
+		}
+	}
+	return result
+}
+
+// MessageKey returns the key of the message translating this label.
+func (l DietaryLabel) MessageKey() string {
+	return "label." + string(l)
+}
+
+func (l DietaryLabel) ENString() string {
+	switch l {
+	case DietaryLabelUnknown:
+		return "Unclassified"
+	case DietaryLabelPork:
+		return "Pork"
+	case DietaryLabelBeef:
+		return "Beef"
+	case DietaryLabelPoultry:
+		return "Poultry"
+	case DietaryLabelLamb:
+		return "Lamb"
+	case DietaryLabelGame:
+		return "Game"
+	case DietaryLabelLactoseFree:
+		return "Lactose-Free"
+	case DietaryLabelEggFree:
+		return "Egg-Free"
+	case DietaryLabelNutFree:
+		return "Nut-Free"
+	case DietaryLabelHalalCompatible:
+		return "Halal-Compatible"
+	}
+	panic("unknown dietary label")
+}
+
+func (l DietaryLabel) DEString() string {
+	switch l {
+	case DietaryLabelUnknown:
+		return "Nicht klassifiziert"
+	case DietaryLabelPork:
+		return "Schwein"
+	case DietaryLabelBeef:
+		return "Rind"
+	case DietaryLabelPoultry:
+		return "Geflügel"
+	case DietaryLabelLamb:
+		return "Lamm"
+	case DietaryLabelGame:
+		return "Wild"
+	case DietaryLabelLactoseFree:
+		return "Laktosefrei"
+	case DietaryLabelEggFree:
+		return "Eifrei"
+	case DietaryLabelNutFree:
+		return "Nussfrei"
+	case DietaryLabelHalalCompatible:
+		return "Halal-kompatibel"
+	}
+	panic("unknown dietary label")
+}
@@ -0,0 +1,78 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words testing github zerolog faulunch internal annotations gorm datatypes
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"gorm.io/datatypes"
+)
+
+func TestMenuItem_DietaryLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		pictograms []annotations.Ingredient
+		want       faulunch.DietaryLabels
+	}{
+		{
+			name:  "no annotations",
+			title: "Tomatensuppe",
+			want:  faulunch.DietaryLabels{DietaryUnknown: true},
+		},
+		{
+			name:  "ingredients without allergens",
+			title: "Tomatensuppe (veg)",
+			want:  faulunch.DietaryLabels{DietaryUnknown: true},
+		},
+		{
+			name:  "allergens without pictograms",
+			title: "Linsensuppe (Sel,2,4)",
+			want:  faulunch.DietaryLabels{DietaryUnknown: true, LactoseFree: true, EggFree: true, NutFree: true},
+		},
+		{
+			name:  "unknown token",
+			title: "Linsensuppe (Sel,Xy,2,4)",
+			want:  faulunch.DietaryLabels{DietaryUnknown: true},
+		},
+		{
+			name:       "unknown token with pictograms",
+			title:      "Schweinebraten (Sel,Xy)",
+			pictograms: []annotations.Ingredient{annotations.Pork},
+			want:       faulunch.DietaryLabels{DietaryUnknown: true, ContainsPork: true},
+		},
+		{
+			name:       "declared allergens",
+			title:      "Nudelauflauf (Wz,Mi,Ei,Hs)",
+			pictograms: []annotations.Ingredient{annotations.Vegetarian},
+			want:       faulunch.DietaryLabels{HalalCompatible: true},
+		},
+		{
+			name:       "peanuts",
+			title:      "Erdnusscurry (Er,So)",
+			pictograms: []annotations.Ingredient{annotations.Vegan},
+			want:       faulunch.DietaryLabels{LactoseFree: true, EggFree: true, HalalCompatible: true},
+		},
+		{
+			name:       "meat and alcohol",
+			title:      "Rindergulasch mit Rotwein (Sel,Sen,A)",
+			pictograms: []annotations.Ingredient{annotations.Beef},
+			want:       faulunch.DietaryLabels{ContainsBeef: true, LactoseFree: true, EggFree: true, NutFree: true},
+		},
+	}
+
+	logger := zerolog.Nop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := faulunch.MenuItem{TitleDE: tt.title, TitleEN: tt.title, Piktogramme: datatypes.NewJSONType(tt.pictograms)}
+			item.UpdateComputedFields(&logger, nil)
+
+			if item.DietaryLabels != tt.want {
+				t.Errorf("DietaryLabels = %+v, want %+v", item.DietaryLabels, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ go 1.25.5
 
 require (
 	github.com/glebarez/sqlite v1.11.0
+	github.com/graphql-go/graphql v0.8.1
 	github.com/rs/zerolog v1.34.0
 	github.com/swaggest/swgui v1.8.5
 	github.com/tdewolff/minify v2.3.6+incompatible
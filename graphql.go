@@ -0,0 +1,893 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words encoding json errors http slices strconv strings sync github graphql gqlerrors language parser source zerolog faulunch apiv2 internal annotations location ltime gorm
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/apiv2"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"gorm.io/gorm"
+)
+
+const (
+	// graphqlMaxCost is the maximal estimated cost of a single graphql query, see [graphqlCost].
+	graphqlMaxCost = 100_000
+
+	// graphqlListSize is the assumed length of lists without a "first" argument, see [graphqlCost].
+	graphqlListSize = 20
+
+	// graphqlMaxBody is the maximal size of a graphql request body in bytes.
+	graphqlMaxBody = 1 << 16
+)
+
+var (
+	errGraphQLInternal = errors.New("internal server error")
+	errGraphQLCursor   = errors.New("invalid cursor")
+)
+
+// graphqlRoot is the root value passed to all graphql resolvers.
+type graphqlRoot struct {
+	API    *API
+	Logger *zerolog.Logger
+}
+
+// graphqlRootOf returns the root value of the query being resolved.
+func graphqlRootOf(p graphql.ResolveParams) *graphqlRoot {
+	return p.Info.RootValue.(*graphqlRoot)
+}
+
+// internal logs err and returns the error to report to the client instead.
+func (root *graphqlRoot) internal(msg string, err error) error {
+	root.Logger.Error().Err(err).Msg(msg)
+	return errGraphQLInternal
+}
+
+// graphqlDay is the source value of the Day type.
+type graphqlDay struct {
+	Location location.Location
+	Day      ltime.Day
+}
+
+// graphqlConnection is the source value of all connection types.
+type graphqlConnection struct {
+	Nodes    any             `json:"nodes"`
+	PageInfo graphqlPageInfo `json:"pageInfo"`
+}
+
+type graphqlPageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+// graphqlPaginate returns the page of at most first values following the value with the given cursor.
+// If after is empty, the page starts with the first value.
+func graphqlPaginate[T any](values []T, cursor func(T) string, first int, after string) ([]T, graphqlPageInfo, error) {
+	if after != "" {
+		index := slices.IndexFunc(values, func(value T) bool { return cursor(value) == after })
+		if index < 0 {
+			return nil, graphqlPageInfo{}, errGraphQLCursor
+		}
+		values = values[index+1:]
+	}
+
+	var info graphqlPageInfo
+	if len(values) > first {
+		values = values[:first]
+		info.HasNextPage = true
+	}
+	if len(values) > 0 {
+		end := cursor(values[len(values)-1])
+		info.EndCursor = &end
+	}
+	return values, info, nil
+}
+
+// graphqlFirst returns the "first" argument of a field, ensuring it is between 1 and limit.
+func graphqlFirst(p graphql.ResolveParams, limit int) (int, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > limit {
+		return 0, fmt.Errorf("first must be between 1 and %d", limit)
+	}
+	return first, nil
+}
+
+// graphqlDate is a scalar representing a day as an ISO 8601 date.
+var graphqlDate = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Date",
+	Description: `An ISO 8601 date, such as "2023-05-10"`,
+	Serialize: func(value any) any {
+		if day, ok := value.(ltime.Day); ok {
+			return day.Date()
+		}
+		return nil
+	},
+	ParseValue: func(value any) any {
+		if s, ok := value.(string); ok {
+			if day, err := ltime.ParseDate(s); err == nil {
+				return day
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) any {
+		if s, ok := value.(*ast.StringValue); ok {
+			if day, err := ltime.ParseDate(s.Value); err == nil {
+				return day
+			}
+		}
+		return nil
+	},
+})
+
+// graphqlEnum creates an enum type whose values are the given strings.
+// Names of values are the upper case strings, with dashes replaced by underscores.
+func graphqlEnum(name, description string, values ...string) *graphql.Enum {
+	config := make(graphql.EnumValueConfigMap, len(values))
+	for _, value := range values {
+		config[strings.ToUpper(strings.ReplaceAll(value, "-", "_"))] = &graphql.EnumValueConfig{Value: value}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{Name: name, Description: description, Values: config})
+}
+
+// graphqlFields creates fields of the given type, resolved by the default resolver.
+// Nullable fields are marked with a trailing "?", all others are non-null.
+func graphqlFields(fields map[string]graphql.Output) graphql.Fields {
+	result := make(graphql.Fields, len(fields))
+	for name, typ := range fields {
+		if nullable, ok := strings.CutSuffix(name, "?"); ok {
+			result[nullable] = &graphql.Field{Type: typ}
+			continue
+		}
+		result[name] = &graphql.Field{Type: graphql.NewNonNull(typ)}
+	}
+	return result
+}
+
+// graphqlListOf returns the type of a non-null list of non-null elements of typ.
+func graphqlListOf(typ graphql.Type) graphql.Output {
+	return graphql.NewList(graphql.NewNonNull(typ))
+}
+
+// graphqlConnectionOf creates a connection type with nodes of the given type.
+func graphqlConnectionOf(name string, node graphql.Type, pageInfo *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        name,
+		Description: "A page of results",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"nodes":    graphqlListOf(node),
+			"pageInfo": pageInfo,
+		}),
+	})
+}
+
+// graphqlSchema returns the graphql schema, see [newGraphQLSchema].
+var graphqlSchema = sync.OnceValues(newGraphQLSchema)
+
+// newGraphQLSchema creates the schema served at /api/graphql.
+// Objects are backed by the types of the [apiv2] package where possible.
+func newGraphQLSchema() (graphql.Schema, error) {
+	localized := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Localized",
+		Description: "A text in german and english",
+		Fields:      graphqlFields(map[string]graphql.Output{"de": graphql.String, "en": graphql.String}),
+	})
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PageInfo",
+		Description: "Information about a page of results. Pass endCursor as the after argument to get the next page.",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"hasNextPage": graphql.Boolean,
+			"endCursor?":  graphql.String,
+		}),
+	})
+
+	kind := graphqlEnum("LocationKind", "Kind of a location", apiv2.KindServery, apiv2.KindCafe, apiv2.KindInternal, apiv2.KindOther)
+
+	var dietaryCategories []string
+	for _, d := range DietaryCategories() {
+		dietaryCategories = append(dietaryCategories, string(d))
+	}
+	diet := graphqlEnum("DietaryCategory", "Dietary category of a menu item", dietaryCategories...)
+
+	catalogue := annotations.NewCatalogue()
+	var euAllergenIDs []string
+	for _, e := range catalogue.EUAllergens {
+		euAllergenIDs = append(euAllergenIDs, string(e.ID))
+	}
+	euAllergenID := graphqlEnum("EUAllergenID", "One of the 14 allergen groups according to Annex II of Regulation (EU) No 1169/2011", euAllergenIDs...)
+
+	allergen := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Allergen",
+		Description: "An allergen declared for menu items",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"code": graphql.String,
+			"name": localized,
+			"eu?":  euAllergenID,
+		}),
+	})
+	euAllergen := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "EUAllergen",
+		Description: "An allergen group according to Annex II of Regulation (EU) No 1169/2011",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"id":     euAllergenID,
+			"number": graphql.Int,
+			"name":   localized,
+		}),
+	})
+	additive := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Additive",
+		Description: "An additive declared for menu items",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"code": graphql.String,
+			"name": localized,
+			"eNumbers": graphqlListOf(graphql.NewObject(graphql.ObjectConfig{
+				Name:        "ENumberRange",
+				Description: "An inclusive range of E-numbers",
+				Fields:      graphqlFields(map[string]graphql.Output{"from": graphql.Int, "to": graphql.Int}),
+			})),
+		}),
+	})
+	ingredient := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Ingredient",
+		Description: "An ingredient declared for menu items",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"code": graphql.String,
+			"name": localized,
+		}),
+	})
+
+	menuItem := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "MenuItem",
+		Description: "A single item on a menu",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"category":    localized,
+			"title":       localized,
+			"description": localized,
+			"sides":       localized,
+			"prices": graphql.NewObject(graphql.ObjectConfig{
+				Name:        "Prices",
+				Description: "Prices of a menu item in euros",
+				Fields: graphqlFields(map[string]graphql.Output{
+					"student":  graphql.Float,
+					"employee": graphql.Float,
+					"guest":    graphql.Float,
+				}),
+			}),
+			"nutrition": graphql.NewObject(graphql.ObjectConfig{
+				Name:        "Nutrition",
+				Description: "Nutritional values of a menu item, amounts are in grams",
+				Fields: graphqlFields(map[string]graphql.Output{
+					"energyKJ":      graphql.Float,
+					"energyKcal":    graphql.Float,
+					"fat":           graphql.Float,
+					"saturatedFat":  graphql.Float,
+					"carbohydrates": graphql.Float,
+					"sugar":         graphql.Float,
+					"fiber":         graphql.Float,
+					"protein":       graphql.Float,
+					"salt":          graphql.Float,
+				}),
+			}),
+			"diet": graphql.NewObject(graphql.ObjectConfig{
+				Name:        "Diet",
+				Description: "Dietary properties of a menu item",
+				Fields: graphqlFields(map[string]graphql.Output{
+					"category":        diet,
+					"unknown":         graphql.Boolean,
+					"meat":            graphqlListOf(graphqlEnum("Meat", "Kind of meat", apiv2.MeatPork, apiv2.MeatBeef, apiv2.MeatPoultry, apiv2.MeatLamb, apiv2.MeatGame)),
+					"glutenFree":      graphql.Boolean,
+					"lactoseFree":     graphql.Boolean,
+					"eggFree":         graphql.Boolean,
+					"nutFree":         graphql.Boolean,
+					"halalCompatible": graphql.Boolean,
+				}),
+			}),
+			"allergens":   graphqlListOf(allergen),
+			"euAllergens": graphqlListOf(euAllergen),
+			"additives":   graphqlListOf(additive),
+			"ingredients": graphqlListOf(ingredient),
+			"edited":      graphql.Boolean,
+		}),
+	})
+
+	description := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "LocationDescription",
+		Description: "Static information about a location",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"name": graphql.String,
+			"kind": kind,
+			"address": graphql.NewObject(graphql.ObjectConfig{
+				Name:        "Address",
+				Description: "Postal address of a location",
+				Fields: graphqlFields(map[string]graphql.Output{
+					"street": graphql.String,
+					"number": graphql.String,
+					"zip":    graphql.String,
+					"city":   graphql.String,
+				}),
+			}),
+			"coordinates?": graphql.NewObject(graphql.ObjectConfig{
+				Name:        "Coordinates",
+				Description: "Approximate coordinates of a location",
+				Fields:      graphqlFields(map[string]graphql.Output{"latitude": graphql.Float, "longitude": graphql.Float}),
+			}),
+		}),
+	})
+
+	// Location and Day reference each other, so their fields are created lazily.
+	var locationType, dayType *graphql.Object
+	locationType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Location",
+		Description: "A location with menus",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.ID),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return string(p.Source.(location.Location)), nil
+					},
+				},
+				"description": &graphql.Field{
+					Type: graphql.NewNonNull(description),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return v2Location(p.Source.(location.Location)), nil
+					},
+				},
+				"days": &graphql.Field{
+					Type:        graphql.NewNonNull(graphqlConnectionOf("DayConnection", dayType, pageInfo)),
+					Description: "Days with a menu in the given range, oldest first. The range may contain at most 365 days.",
+					Args: graphql.FieldConfigArgument{
+						"from":  {Type: graphqlDate, Description: "First day of the range, defaults to today"},
+						"to":    {Type: graphqlDate, Description: "Last day of the range, defaults to 6 days after from"},
+						"first": {Type: graphql.Int, DefaultValue: 7, Description: "Maximal number of days to return, at most 31"},
+						"after": {Type: graphql.String, Description: "Cursor of the day to start after"},
+					},
+					Resolve: resolveGraphQLDays,
+				},
+				"day": &graphql.Field{
+					Type:        graphql.NewNonNull(dayType),
+					Description: "The given day, which may have an empty menu",
+					Args: graphql.FieldConfigArgument{
+						"date": {Type: graphql.NewNonNull(graphqlDate)},
+					},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						day, ok := p.Args["date"].(ltime.Day)
+						if !ok {
+							return nil, errors.New("invalid date")
+						}
+						return graphqlDay{Location: p.Source.(location.Location), Day: day}, nil
+					},
+				},
+			}
+		}),
+	})
+	dayType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Day",
+		Description: "The menu of a location on a single day",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"date": &graphql.Field{
+					Type: graphql.NewNonNull(graphqlDate),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(graphqlDay).Day, nil
+					},
+				},
+				"location": &graphql.Field{
+					Type: graphql.NewNonNull(locationType),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return p.Source.(graphqlDay).Location, nil
+					},
+				},
+				"items": &graphql.Field{
+					Type:        graphql.NewNonNull(graphqlListOf(menuItem)),
+					Description: "Items on the menu matching all given filters, sorted by category",
+					Args: graphql.FieldConfigArgument{
+						"diet":             {Type: diet, Description: "Only return items satisfying the given diet, vegan items satisfy a vegetarian diet"},
+						"glutenFree":       {Type: graphql.Boolean, Description: "Only return gluten-free items"},
+						"lactoseFree":      {Type: graphql.Boolean, Description: "Only return lactose-free items"},
+						"withoutAllergens": {Type: graphql.NewList(graphql.NewNonNull(euAllergenID)), Description: "Only return items containing none of the given allergen groups"},
+						"category":         {Type: graphql.String, Description: "Only return items whose german or english category contains the given text, ignoring case"},
+						"first":            {Type: graphql.Int, DefaultValue: 50, Description: "Maximal number of items to return, at most 100"},
+					},
+					Resolve: resolveGraphQLItems,
+				},
+			}
+		}),
+	})
+
+	syncEvent := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "SyncEvent",
+		Description: "A synchronization with the upstream server. Times are RFC 3339 timestamps.",
+		Fields: graphqlFields(map[string]graphql.Output{
+			"start": graphql.String,
+			"stop":  graphql.String,
+			"warnings": graphqlListOf(graphql.NewObject(graphql.ObjectConfig{
+				Name:        "SyncWarning",
+				Description: "A problem with the upstream data found during synchronization",
+				Fields: graphqlFields(map[string]graphql.Output{
+					"kind":      graphql.String,
+					"location?": graphql.String,
+					"date?":     graphql.String,
+					"category?": graphql.String,
+					"title?":    graphql.String,
+					"missing?":  graphql.String,
+					"message":   graphql.String,
+				}),
+			})),
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"locations": &graphql.Field{
+				Type:        graphql.NewNonNull(graphqlConnectionOf("LocationConnection", locationType, pageInfo)),
+				Description: "Locations with at least one menu matching all given filters, in consistent order",
+				Args: graphql.FieldConfigArgument{
+					"ids":   {Type: graphql.NewList(graphql.NewNonNull(graphql.ID)), Description: "Only return locations with the given ids"},
+					"city":  {Type: graphql.String, Description: "Only return locations in the given city, ignoring case"},
+					"kind":  {Type: kind, Description: "Only return locations of the given kind"},
+					"first": {Type: graphql.Int, DefaultValue: 20, Description: "Maximal number of locations to return, at most 100"},
+					"after": {Type: graphql.String, Description: "Cursor of the location to start after"},
+				},
+				Resolve: resolveGraphQLLocations,
+			},
+			"location": &graphql.Field{
+				Type:        locationType,
+				Description: "The location with the given id, if it has at least one menu",
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					root := graphqlRootOf(p)
+
+					loc := location.Location(p.Args["id"].(string))
+					exists, err := root.API.KnowsLocation(loc)
+					if err != nil {
+						return nil, root.internal("API.KnowsLocation", err)
+					}
+					if !exists {
+						return nil, nil
+					}
+					return loc, nil
+				},
+			},
+			"lastSync": &graphql.Field{
+				Type:        syncEvent,
+				Description: "The last synchronization with the upstream server, if any",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					root := graphqlRootOf(p)
+
+					sync, err := root.API.LastSync(p.Context)
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, root.internal("API.LastSync", err)
+					}
+					return v2SyncEvent(sync), nil
+				},
+			},
+			"allergens": &graphql.Field{
+				Type:        graphql.NewNonNull(graphqlListOf(allergen)),
+				Description: "All known allergens",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					results := make([]apiv2.Allergen, len(catalogue.Allergens))
+					for i, a := range catalogue.Allergens {
+						results[i] = v2Allergen(a.ID)
+					}
+					return results, nil
+				},
+			},
+			"euAllergens": &graphql.Field{
+				Type:        graphql.NewNonNull(graphqlListOf(euAllergen)),
+				Description: "All allergen groups, in the order of Annex II",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					results := make([]apiv2.EUAllergen, len(catalogue.EUAllergens))
+					for i, e := range catalogue.EUAllergens {
+						results[i] = v2EUAllergen(e.ID)
+					}
+					return results, nil
+				},
+			},
+			"additives": &graphql.Field{
+				Type:        graphql.NewNonNull(graphqlListOf(additive)),
+				Description: "All known additives",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					results := make([]apiv2.Additive, len(catalogue.Additives))
+					for i, a := range catalogue.Additives {
+						results[i] = v2Additive(a.ID)
+					}
+					return results, nil
+				},
+			},
+			"ingredients": &graphql.Field{
+				Type:        graphql.NewNonNull(graphqlListOf(ingredient)),
+				Description: "All known ingredients",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					results := make([]apiv2.Ingredient, len(catalogue.Ingredients))
+					for i, ing := range catalogue.Ingredients {
+						results[i] = v2Ingredient(ing.ID)
+					}
+					return results, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func resolveGraphQLLocations(p graphql.ResolveParams) (any, error) {
+	root := graphqlRootOf(p)
+
+	first, err := graphqlFirst(p, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := root.API.Locations()
+	if err != nil {
+		return nil, root.internal("API.Locations", err)
+	}
+
+	var ids []string
+	if values, ok := p.Args["ids"].([]any); ok {
+		for _, value := range values {
+			ids = append(ids, value.(string))
+		}
+	}
+	city, _ := p.Args["city"].(string)
+	kind, _ := p.Args["kind"].(string)
+
+	locations = slices.DeleteFunc(locations, func(loc location.Location) bool {
+		if ids != nil && !slices.Contains(ids, string(loc)) {
+			return true
+		}
+		desc := v2Location(loc)
+		return (city != "" && !strings.EqualFold(desc.Address.City, city)) ||
+			(kind != "" && desc.Kind != kind)
+	})
+
+	after, _ := p.Args["after"].(string)
+	page, info, err := graphqlPaginate(locations, func(loc location.Location) string { return string(loc) }, first, after)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlConnection{Nodes: page, PageInfo: info}, nil
+}
+
+func resolveGraphQLDays(p graphql.ResolveParams) (any, error) {
+	root := graphqlRootOf(p)
+	loc := p.Source.(location.Location)
+
+	first, err := graphqlFirst(p, 31)
+	if err != nil {
+		return nil, err
+	}
+
+	from, ok := p.Args["from"].(ltime.Day)
+	if !ok {
+		from = ltime.Today()
+	}
+	to, ok := p.Args["to"].(ltime.Day)
+	if !ok {
+		to = from.Add(6)
+	}
+
+	count := from.DaysUntil(to) + 1
+	if count < 1 || count > apiMaxDays {
+		return nil, fmt.Errorf("range must contain between 1 and %d days", apiMaxDays)
+	}
+
+	days, err := root.API.Days(loc, from, count)
+	if err != nil {
+		return nil, root.internal("API.Days", err)
+	}
+	reverse(days)
+
+	after, _ := p.Args["after"].(string)
+	page, info, err := graphqlPaginate(days, ltime.Day.Date, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]graphqlDay, len(page))
+	for i, day := range page {
+		nodes[i] = graphqlDay{Location: loc, Day: day}
+	}
+	return graphqlConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func resolveGraphQLItems(p graphql.ResolveParams) (any, error) {
+	root := graphqlRootOf(p)
+	day := p.Source.(graphqlDay)
+
+	first, err := graphqlFirst(p, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := root.API.MenuItems(day.Location, day.Day)
+	if err != nil {
+		return nil, root.internal("API.MenuItems", err)
+	}
+
+	diet, _ := p.Args["diet"].(string)
+	glutenFree, _ := p.Args["glutenFree"].(bool)
+	lactoseFree, _ := p.Args["lactoseFree"].(bool)
+	category, _ := p.Args["category"].(string)
+	category = strings.ToLower(category)
+	var without []annotations.EUAllergen
+	if values, ok := p.Args["withoutAllergens"].([]any); ok {
+		for _, value := range values {
+			without = append(without, annotations.EUAllergen(value.(string)))
+		}
+	}
+
+	results := make([]apiv2.MenuItem, 0, min(len(items), first))
+	for _, item := range items {
+		if len(results) == first {
+			break
+		}
+		if (diet != "" && !item.DietaryCategory.Satisfies(DietaryCategory(diet))) ||
+			(glutenFree && !item.GlutenFree) ||
+			(lactoseFree && !item.LactoseFree) ||
+			(category != "" && !strings.Contains(strings.ToLower(item.Category), category) && !strings.Contains(strings.ToLower(item.CategoryEN), category)) ||
+			slices.ContainsFunc(item.EUAllergens.Data(), func(e annotations.EUAllergen) bool { return slices.Contains(without, e) }) {
+			continue
+		}
+		results = append(results, v2MenuItem(item))
+	}
+	return results, nil
+}
+
+// graphqlCost estimates the cost of executing an operation of a document.
+//
+// Every field costs 1.
+// The cost of the selections of a field with a "first" argument is multiplied by its value,
+// the cost of the selections of other list fields by [graphqlListSize].
+// Lists directly selected from a field with a "first" argument, like the nodes of a connection, are not multiplied again.
+// The estimate is capped slightly above [graphqlMaxCost].
+func graphqlCost(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]any) int {
+	coster := graphqlCoster{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		costs:     make(map[graphqlFragmentUse]int),
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		case *ast.FragmentDefinition:
+			coster.fragments[def.Name.Value] = def
+		}
+	}
+
+	// only queries exist, so all operations are resolved against the query type
+	cost := 0
+	for _, op := range operations {
+		cost = coster.add(cost, coster.selectionSet(op.SelectionSet, schema.QueryType(), false))
+	}
+	return cost
+}
+
+// graphqlCoster implements [graphqlCost].
+type graphqlCoster struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+
+	visiting []string                   // fragments being visited, to prevent infinite recursion on invalid documents
+	costs    map[graphqlFragmentUse]int // cost of fragments already visited, so that each is walked only once
+}
+
+// graphqlFragmentUse is a use of a fragment, which determines its cost.
+type graphqlFragmentUse struct {
+	name      string
+	parent    *graphql.Object
+	paginated bool
+}
+
+func (c *graphqlCoster) add(a, b int) int {
+	return min(a+b, graphqlMaxCost+1)
+}
+
+func (c *graphqlCoster) mul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > (graphqlMaxCost+1)/b {
+		return graphqlMaxCost + 1
+	}
+	return a * b
+}
+
+// selectionSet returns the cost of a selection set of the parent type.
+// paginated indicates if the selection set belongs to a field with a "first" argument.
+func (c *graphqlCoster) selectionSet(set *ast.SelectionSet, parent *graphql.Object, paginated bool) int {
+	if set == nil || parent == nil {
+		return 0
+	}
+
+	cost := 0
+	for _, selection := range set.Selections {
+		if cost > graphqlMaxCost {
+			break
+		}
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost = c.add(cost, c.field(selection, parent, paginated))
+		case *ast.InlineFragment:
+			cost = c.add(cost, c.selectionSet(selection.SelectionSet, parent, paginated))
+		case *ast.FragmentSpread:
+			cost = c.add(cost, c.fragment(selection.Name.Value, parent, paginated))
+		}
+	}
+	return cost
+}
+
+// fragment returns the cost of spreading the named fragment into a selection set of the parent type.
+func (c *graphqlCoster) fragment(name string, parent *graphql.Object, paginated bool) int {
+	use := graphqlFragmentUse{name: name, parent: parent, paginated: paginated}
+	if cost, ok := c.costs[use]; ok {
+		return cost
+	}
+
+	fragment, ok := c.fragments[name]
+	if !ok || slices.Contains(c.visiting, name) {
+		return 0
+	}
+	c.visiting = append(c.visiting, name)
+	cost := c.selectionSet(fragment.SelectionSet, parent, paginated)
+	c.visiting = c.visiting[:len(c.visiting)-1]
+
+	c.costs[use] = cost
+	return cost
+}
+
+func (c *graphqlCoster) field(field *ast.Field, parent *graphql.Object, paginated bool) int {
+	def, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		// introspection fields, bounded by the size of the schema
+		return 1
+	}
+
+	object, _ := graphql.GetNamed(def.Type).(*graphql.Object)
+	first, ok := c.first(field, def)
+	children := c.selectionSet(field.SelectionSet, object, ok)
+
+	if ok {
+		children = c.mul(children, first)
+	} else if graphqlIsList(def.Type) && !paginated {
+		children = c.mul(children, graphqlListSize)
+	}
+	return c.add(1, children)
+}
+
+// first returns the value of the "first" argument of field, if any.
+func (c *graphqlCoster) first(field *ast.Field, def *graphql.FieldDefinition) (int, bool) {
+	index := slices.IndexFunc(def.Args, func(arg *graphql.Argument) bool { return arg.Name() == "first" })
+	if index < 0 {
+		return 0, false
+	}
+	first, _ := def.Args[index].DefaultValue.(int)
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			first, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch value := c.variables[value.Name.Value].(type) {
+			case float64:
+				first = int(value)
+			case int:
+				first = value
+			}
+		}
+	}
+	return max(first, 0), true
+}
+
+// graphqlIsList checks if typ is a (possibly non-null) list type.
+func graphqlIsList(typ graphql.Type) bool {
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.OfType
+	}
+	_, ok := typ.(*graphql.List)
+	return ok
+}
+
+// graphqlRequest is a request to the graphql endpoint.
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// executeGraphQL validates and executes a graphql request.
+func (server *Server) executeGraphQL(ctx context.Context, logger *zerolog.Logger, request graphqlRequest) *graphql.Result {
+	schema, err := graphqlSchema()
+	if err != nil {
+		logger.Error().Err(err).Msg("graphqlSchema")
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(errGraphQLInternal.Error())}}
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if validation := graphql.ValidateDocument(&schema, doc, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if cost := graphqlCost(schema, doc, request.OperationName, request.Variables); cost > graphqlMaxCost {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(fmt.Sprintf("query is too expensive: estimated cost exceeds %d", graphqlMaxCost))}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		Root:          &graphqlRoot{API: &server.API, Logger: logger},
+		AST:           doc,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
+
+func (server *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "GraphQL").Logger()
+
+	var request graphqlRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphqlMaxBody)).Decode(&request); err != nil {
+			server.handleBadRequest(w)
+			return
+		}
+	default:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				server.handleBadRequest(w)
+				return
+			}
+		}
+	}
+	if request.Query == "" {
+		server.handleBadRequest(w)
+		return
+	}
+
+	result := server.executeGraphQL(r.Context(), &logger, request)
+	logger.Trace().Int("errors", len(result.Errors)).Msg("executeGraphQL")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,199 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words encoding json fmt http httptest net path filepath strings testing github zerolog faulunch
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+)
+
+// graphqlResponse is a response of the graphql endpoint.
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphqlDashboardQuery is an example query of a dashboard.
+const graphqlDashboardQuery = `query Dashboard($from: Date!, $to: Date!) {
+	locations(city: "erlangen", first: 5) {
+		nodes {
+			id
+			description { name kind address { city } }
+			days(from: $from, to: $to) {
+				nodes {
+					date
+					items(diet: VEGETARIAN, withoutAllergens: [NUTS]) {
+						title { de en }
+						prices { student }
+						diet { category }
+					}
+				}
+			}
+		}
+		pageInfo { hasNextPage endCursor }
+	}
+}`
+
+func TestGraphQL(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}}
+
+	post := func(t *testing.T, query string, variables map[string]any) graphqlResponse {
+		t.Helper()
+
+		body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+		if err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /api/graphql returned status %d", rec.Code)
+		}
+
+		var res graphqlResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return res
+	}
+
+	t.Run("dashboard", func(t *testing.T) {
+		res := post(t, graphqlDashboardQuery, map[string]any{"from": "2025-10-20", "to": "2025-10-26"})
+		if len(res.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", res.Errors)
+		}
+
+		var data any
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatalf("failed to decode data: %v", err)
+		}
+		compareGolden(t, filepath.Join("testdata", "golden", "graphql", "dashboard.json"), encodeJSON(t, data))
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		const query = `query($after: String) {
+			location(id: "mensa-sued") {
+				days(from: "2025-10-01", to: "2025-10-31", first: 1, after: $after) {
+					nodes { date }
+					pageInfo { hasNextPage endCursor }
+				}
+			}
+		}`
+		type page struct {
+			Location struct {
+				Days struct {
+					Nodes []struct {
+						Date string `json:"date"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool    `json:"hasNextPage"`
+						EndCursor   *string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"days"`
+			} `json:"location"`
+		}
+
+		var dates []string
+		variables := map[string]any{}
+		for range 10 {
+			res := post(t, query, variables)
+			if len(res.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", res.Errors)
+			}
+			var data page
+			if err := json.Unmarshal(res.Data, &data); err != nil {
+				t.Fatalf("failed to decode data: %v", err)
+			}
+			for _, node := range data.Location.Days.Nodes {
+				dates = append(dates, node.Date)
+			}
+			if !data.Location.Days.PageInfo.HasNextPage {
+				break
+			}
+			variables["after"] = *data.Location.Days.PageInfo.EndCursor
+		}
+
+		if got, want := strings.Join(dates, ","), "2025-10-20,2025-10-21"; got != want {
+			t.Errorf("paginated dates = %s, want %s", got, want)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"syntax error", `{ locations {`, "Syntax Error"},
+		{"unknown field", `{ locations { nodes { unknown } } }`, "Cannot query field"},
+		{"first out of range", `{ locations(first: 1000) { nodes { id } } }`, "first must be between 1 and 100"},
+		{"invalid cursor", `{ locations(after: "does-not-exist") { nodes { id } } }`, "invalid cursor"},
+		{"range too large", `{ location(id: "mensa-sued") { days(from: "2020-01-01", to: "2026-01-01") { nodes { date } } } }`, "range must contain"},
+		{"too expensive", `{ locations(first: 100) { nodes { days(first: 31) { nodes { items(first: 100) { allergens { code } } } } } } }`, "too expensive"},
+		{
+			"too expensive via fragment",
+			`{ locations(first: 100) { nodes { ...days } } } fragment days on Location { days(first: 31) { nodes { items(first: 100) { title { de } euAllergens { id } } } } }`,
+			"too expensive",
+		},
+		{"too expensive via fragment chain", graphqlFragmentChain(30), "too expensive"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := post(t, tt.query, nil)
+			if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, tt.wantErr) {
+				t.Errorf("errors = %v, want error containing %q", res.Errors, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("get", func(t *testing.T) {
+		body := get(t, server, "/api/graphql?"+url.Values{"query": {`{ lastSync { start } euAllergens { id number } allergens { code eu } additives { code eNumbers { from to } } ingredients { code } }`}}.Encode())
+
+		var res graphqlResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(res.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", res.Errors)
+		}
+		if !strings.Contains(string(res.Data), `"start":"2023-05-10T07:37:24Z"`) || !strings.Contains(string(res.Data), `"id":"GLUTEN","number":1`) {
+			t.Errorf("unexpected data: %s", res.Data)
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader("not json"))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /api/graphql with invalid body returned status %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+// graphqlFragmentChain returns a query with a chain of n fragments, each spreading the next one twice.
+// The cost of the query doubles with every fragment.
+func graphqlFragmentChain(n int) string {
+	var query strings.Builder
+	query.WriteString("{ ...f0 }")
+	for i := range n {
+		fmt.Fprintf(&query, " fragment f%d on Query { ...f%d ...f%d }", i, i+1, i+1)
+	}
+	fmt.Fprintf(&query, " fragment f%d on Query { lastSync { start } }", n)
+	return query.String()
+}
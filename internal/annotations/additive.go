@@ -23,10 +23,13 @@ const (
 	Coating         Additive = "30"
 )
 
-var additiveOrder = fmap.Order(
+// additives holds all known additives in display order.
+var additives = []Additive{
 	Color, Caffeine, Preservatives, Sweeteners, Antioxidant, FlavorEnhancers, Sulphurated, Blackened, Waxed, Phosphate, Phenylalanine,
 	Coating,
-)
+}
+
+var additiveOrder = fmap.Order(additives...)
 
 func (a Additive) Cmp(other Additive) int {
 	return additiveOrder[a] - additiveOrder[other]
@@ -41,6 +44,11 @@ func (a Additive) Normalize() (Additive, bool) {
 	return key, ok
 }
 
+// MessageKey returns the key of the message translating this additive.
+func (a Additive) MessageKey() string {
+	return "additive." + string(a)
+}
+
 func (a Additive) ENString() string {
 	return additivesEN[a]
 }
@@ -21,6 +21,11 @@ func (a Allergen) Normalize() (Allergen, bool) {
 	return key, ok
 }
 
+// MessageKey returns the key of the message translating this allergen.
+func (a Allergen) MessageKey() string {
+	return "allergen." + string(a)
+}
+
 func (a Allergen) ENString() string {
 	return allergensEN[a]
 }
@@ -62,11 +67,14 @@ const (
 	Mollusca      Allergen = "We"
 )
 
-var allergenOrder = fmap.Order(
+// allergens holds all known allergens in display order.
+var allergens = []Allergen{
 	Wheat, Rye, Barley, Oats, Crustaceans, Eggs, Fish, Peanuts, Soybeans, Milk,
 	Almonds, HazelNuts, WalNuts, CashewNuts, PecanNuts, BrazilNuts, Pistachios, MacadamiaNuts, Celeriac, Mustard,
 	Sesame, Sulphur, Lupines, Mollusca,
-)
+}
+
+var allergenOrder = fmap.Order(allergens...)
 
 var allergensEN = map[Allergen]string{
 	Wheat:         "cereals containing gluten wheat (spelt, kamut)",
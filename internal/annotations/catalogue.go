@@ -0,0 +1,72 @@
+package annotations
+
+// Catalogue describes all known annotations.
+// It allows clients to render the legend without hard-coding it.
+type Catalogue struct {
+	Allergens   []AllergenEntry   `json:"allergens"`
+	EUAllergens []EUAllergenEntry `json:"euAllergens"`
+	Additives   []AdditiveEntry   `json:"additives"`
+	Ingredients []IngredientEntry `json:"ingredients"`
+}
+
+// AllergenEntry describes a single allergen in the catalogue.
+type AllergenEntry struct {
+	ID Allergen   `json:"id"`
+	DE string     `json:"de"`
+	EN string     `json:"en"`
+	EU EUAllergen `json:"eu"`
+}
+
+// EUAllergenEntry describes a single EU allergen group in the catalogue.
+type EUAllergenEntry struct {
+	ID     EUAllergen `json:"id"`
+	Number int        `json:"number"`
+	DE     string     `json:"de"`
+	EN     string     `json:"en"`
+}
+
+// AdditiveEntry describes a single additive in the catalogue.
+type AdditiveEntry struct {
+	ID       Additive       `json:"id"`
+	DE       string         `json:"de"`
+	EN       string         `json:"en"`
+	ENumbers []ENumberRange `json:"eNumbers"`
+}
+
+// IngredientEntry describes a single ingredient in the catalogue.
+type IngredientEntry struct {
+	ID Ingredient `json:"id"`
+	DE string     `json:"de"`
+	EN string     `json:"en"`
+}
+
+// NewCatalogue returns the catalogue of all known annotations, in display order.
+func NewCatalogue() Catalogue {
+	var catalogue Catalogue
+
+	catalogue.Allergens = make([]AllergenEntry, len(allergens))
+	for i, a := range allergens {
+		catalogue.Allergens[i] = AllergenEntry{ID: a, DE: a.DEString(), EN: a.ENString(), EU: a.EU()}
+	}
+
+	catalogue.EUAllergens = make([]EUAllergenEntry, len(euAllergens))
+	for i, e := range euAllergens {
+		catalogue.EUAllergens[i] = EUAllergenEntry{ID: e, Number: e.Number(), DE: e.DEString(), EN: e.ENString()}
+	}
+
+	catalogue.Additives = make([]AdditiveEntry, len(additives))
+	for i, a := range additives {
+		numbers := a.ENumbers()
+		if numbers == nil {
+			numbers = []ENumberRange{}
+		}
+		catalogue.Additives[i] = AdditiveEntry{ID: a, DE: a.DEString(), EN: a.ENString(), ENumbers: numbers}
+	}
+
+	catalogue.Ingredients = make([]IngredientEntry, len(ingredients))
+	for i, ing := range ingredients {
+		catalogue.Ingredients[i] = IngredientEntry{ID: ing, DE: ing.DEString(), EN: ing.ENString()}
+	}
+
+	return catalogue
+}
@@ -0,0 +1,42 @@
+package annotations
+
+import "strconv"
+
+// ENumberRange is an inclusive range of E-numbers, such as E200 to E299.
+// A single E-number is represented by a range with From equal to To.
+type ENumberRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// String formats the range, e.g. as "E200-E299" or "E951".
+func (r ENumberRange) String() string {
+	if r.From == r.To {
+		return "E" + strconv.Itoa(r.From)
+	}
+	return "E" + strconv.Itoa(r.From) + "-E" + strconv.Itoa(r.To)
+}
+
+// Contains checks if the given E-number is contained in this range.
+func (r ENumberRange) Contains(number int) bool {
+	return r.From <= number && number <= r.To
+}
+
+// ENumbers returns the E-number ranges the substances declared by this additive belong to.
+// Returns nil for additives that are not declared using E-numbers, such as caffeine.
+func (a Additive) ENumbers() []ENumberRange {
+	return additiveENumbers[a]
+}
+
+var additiveENumbers = map[Additive][]ENumberRange{
+	Color:           {{From: 100, To: 199}},
+	Preservatives:   {{From: 200, To: 299}},
+	Sweeteners:      {{From: 420, To: 421}, {From: 950, To: 969}},
+	Antioxidant:     {{From: 300, To: 321}},
+	FlavorEnhancers: {{From: 620, To: 650}},
+	Sulphurated:     {{From: 220, To: 228}},
+	Blackened:       {{From: 579, To: 579}, {From: 585, To: 585}},
+	Waxed:           {{From: 901, To: 914}},
+	Phosphate:       {{From: 338, To: 341}, {From: 450, To: 452}},
+	Phenylalanine:   {{From: 951, To: 951}, {From: 962, To: 962}},
+}
@@ -0,0 +1,46 @@
+package annotations_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestAdditive_ENumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		a    annotations.Additive
+		want []string
+	}{
+		{name: "Color", a: annotations.Color, want: []string{"E100-E199"}},
+		{name: "Caffeine", a: annotations.Caffeine, want: nil},
+		{name: "Preservatives", a: annotations.Preservatives, want: []string{"E200-E299"}},
+		{name: "Sulphurated", a: annotations.Sulphurated, want: []string{"E220-E228"}},
+		{name: "Blackened", a: annotations.Blackened, want: []string{"E579", "E585"}},
+		{name: "Phosphate", a: annotations.Phosphate, want: []string{"E338-E341", "E450-E452"}},
+		{name: "Phenylalanine", a: annotations.Phenylalanine, want: []string{"E951", "E962"}},
+		{name: "Coating", a: annotations.Coating, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range tt.a.ENumbers() {
+				got = append(got, r.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ENumbers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestENumberRange_Contains(t *testing.T) {
+	r := annotations.ENumberRange{From: 220, To: 228}
+	for number, want := range map[int]bool{219: false, 220: true, 224: true, 228: true, 229: false} {
+		if got := r.Contains(number); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", number, got, want)
+		}
+	}
+}
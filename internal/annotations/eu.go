@@ -0,0 +1,138 @@
+package annotations
+
+// EUAllergen is one of the 14 allergen groups that must be declared according to Annex II of Regulation (EU) No 1169/2011.
+type EUAllergen string
+
+const (
+	EUGluten      EUAllergen = "gluten"
+	EUCrustaceans EUAllergen = "crustaceans"
+	EUEggs        EUAllergen = "eggs"
+	EUFish        EUAllergen = "fish"
+	EUPeanuts     EUAllergen = "peanuts"
+	EUSoybeans    EUAllergen = "soybeans"
+	EUMilk        EUAllergen = "milk"
+	EUNuts        EUAllergen = "nuts"
+	EUCelery      EUAllergen = "celery"
+	EUMustard     EUAllergen = "mustard"
+	EUSesame      EUAllergen = "sesame"
+	EUSulphites   EUAllergen = "sulphites"
+	EULupin       EUAllergen = "lupin"
+	EUMolluscs    EUAllergen = "molluscs"
+)
+
+// euAllergens holds the EU allergen groups in the order of Annex II.
+var euAllergens = []EUAllergen{
+	EUGluten, EUCrustaceans, EUEggs, EUFish, EUPeanuts, EUSoybeans, EUMilk,
+	EUNuts, EUCelery, EUMustard, EUSesame, EUSulphites, EULupin, EUMolluscs,
+}
+
+// Number returns the number of this group in Annex II, ranging from 1 to 14.
+// Returns 0 for unknown groups.
+func (e EUAllergen) Number() int {
+	for i, group := range euAllergens {
+		if group == e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Cmp compares two groups by their number in Annex II.
+func (e EUAllergen) Cmp(other EUAllergen) int {
+	return e.Number() - other.Number()
+}
+
+// MessageKey returns the key of the message translating this group.
+func (e EUAllergen) MessageKey() string {
+	return "eu-allergen." + string(e)
+}
+
+func (e EUAllergen) ENString() string {
+	return euAllergensEN[e]
+}
+
+func (e EUAllergen) DEString() string {
+	return euAllergensDE[e]
+}
+
+var euAllergensEN = map[EUAllergen]string{
+	EUGluten:      "cereals containing gluten",
+	EUCrustaceans: "crustaceans",
+	EUEggs:        "eggs",
+	EUFish:        "fish",
+	EUPeanuts:     "peanuts",
+	EUSoybeans:    "soybeans",
+	EUMilk:        "milk",
+	EUNuts:        "nuts",
+	EUCelery:      "celery",
+	EUMustard:     "mustard",
+	EUSesame:      "sesame seeds",
+	EUSulphites:   "sulphur dioxide and sulphites",
+	EULupin:       "lupin",
+	EUMolluscs:    "molluscs",
+}
+
+var euAllergensDE = map[EUAllergen]string{
+	EUGluten:      "Glutenhaltiges Getreide",
+	EUCrustaceans: "Krebstiere",
+	EUEggs:        "Eier",
+	EUFish:        "Fisch",
+	EUPeanuts:     "Erdnüsse",
+	EUSoybeans:    "Sojabohnen",
+	EUMilk:        "Milch",
+	EUNuts:        "Schalenfrüchte",
+	EUCelery:      "Sellerie",
+	EUMustard:     "Senf",
+	EUSesame:      "Sesamsamen",
+	EUSulphites:   "Schwefeldioxid und Sulphite",
+	EULupin:       "Lupinen",
+	EUMolluscs:    "Weichtiere",
+}
+
+// EU returns the EU allergen group this allergen belongs to.
+// Returns the empty string for unknown allergens.
+func (a Allergen) EU() EUAllergen {
+	return allergenEU[a]
+}
+
+var allergenEU = map[Allergen]EUAllergen{
+	Wheat:         EUGluten,
+	Rye:           EUGluten,
+	Barley:        EUGluten,
+	Oats:          EUGluten,
+	Crustaceans:   EUCrustaceans,
+	Eggs:          EUEggs,
+	Fish:          EUFish,
+	Peanuts:       EUPeanuts,
+	Soybeans:      EUSoybeans,
+	Milk:          EUMilk,
+	Almonds:       EUNuts,
+	HazelNuts:     EUNuts,
+	WalNuts:       EUNuts,
+	CashewNuts:    EUNuts,
+	PecanNuts:     EUNuts,
+	BrazilNuts:    EUNuts,
+	Pistachios:    EUNuts,
+	MacadamiaNuts: EUNuts,
+	Celeriac:      EUCelery,
+	Mustard:       EUMustard,
+	Sesame:        EUSesame,
+	Sulphur:       EUSulphites,
+	Lupines:       EULupin,
+	Mollusca:      EUMolluscs,
+}
+
+// EUAllergens returns the sorted, deduplicated EU allergen groups of the given allergens.
+// Unknown allergens are skipped.
+func EUAllergens(allergens []Allergen) []EUAllergen {
+	groups := make([]EUAllergen, 0, len(allergens))
+	for _, group := range euAllergens {
+		for _, allergen := range allergens {
+			if allergen.EU() == group {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
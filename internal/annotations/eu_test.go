@@ -0,0 +1,90 @@
+package annotations_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestAllergen_EU(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      annotations.Allergen
+		want   annotations.EUAllergen
+		number int
+	}{
+		{name: "Wheat", a: annotations.Wheat, want: annotations.EUGluten, number: 1},
+		{name: "Oats", a: annotations.Oats, want: annotations.EUGluten, number: 1},
+		{name: "Crustaceans", a: annotations.Crustaceans, want: annotations.EUCrustaceans, number: 2},
+		{name: "Milk", a: annotations.Milk, want: annotations.EUMilk, number: 7},
+		{name: "Almonds", a: annotations.Almonds, want: annotations.EUNuts, number: 8},
+		{name: "MacadamiaNuts", a: annotations.MacadamiaNuts, want: annotations.EUNuts, number: 8},
+		{name: "Celeriac", a: annotations.Celeriac, want: annotations.EUCelery, number: 9},
+		{name: "Sulphur", a: annotations.Sulphur, want: annotations.EUSulphites, number: 12},
+		{name: "Mollusca", a: annotations.Mollusca, want: annotations.EUMolluscs, number: 14},
+		{name: "unknown", a: "Xy", want: "", number: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.EU()
+			if got != tt.want {
+				t.Errorf("EU() = %q, want %q", got, tt.want)
+			}
+			if number := got.Number(); number != tt.number {
+				t.Errorf("EU().Number() = %d, want %d", number, tt.number)
+			}
+		})
+	}
+}
+
+func TestEUAllergens(t *testing.T) {
+	tests := []struct {
+		name      string
+		allergens []annotations.Allergen
+		want      []annotations.EUAllergen
+	}{
+		{name: "empty", allergens: nil, want: []annotations.EUAllergen{}},
+		{name: "deduplicated", allergens: []annotations.Allergen{annotations.Wheat, annotations.Barley}, want: []annotations.EUAllergen{annotations.EUGluten}},
+		{name: "sorted", allergens: []annotations.Allergen{annotations.Sesame, annotations.HazelNuts, annotations.Eggs}, want: []annotations.EUAllergen{annotations.EUEggs, annotations.EUNuts, annotations.EUSesame}},
+		{name: "unknown skipped", allergens: []annotations.Allergen{"Xy", annotations.Milk}, want: []annotations.EUAllergen{annotations.EUMilk}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotations.EUAllergens(tt.allergens); !slices.Equal(got, tt.want) {
+				t.Errorf("EUAllergens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCatalogue(t *testing.T) {
+	catalogue := annotations.NewCatalogue()
+
+	if len(catalogue.EUAllergens) != 14 {
+		t.Errorf("got %d EU allergen groups, want 14", len(catalogue.EUAllergens))
+	}
+	for i, group := range catalogue.EUAllergens {
+		if group.Number != i+1 || group.DE == "" || group.EN == "" {
+			t.Errorf("EU allergen group %q is incomplete: %+v", group.ID, group)
+		}
+	}
+
+	for _, allergen := range catalogue.Allergens {
+		if allergen.EU == "" || allergen.DE == "" || allergen.EN == "" {
+			t.Errorf("allergen %q is incomplete: %+v", allergen.ID, allergen)
+		}
+	}
+	for _, additive := range catalogue.Additives {
+		if additive.ENumbers == nil || additive.DE == "" || additive.EN == "" {
+			t.Errorf("additive %q is incomplete: %+v", additive.ID, additive)
+		}
+	}
+	for _, ingredient := range catalogue.Ingredients {
+		if ingredient.DE == "" || ingredient.EN == "" {
+			t.Errorf("ingredient %q is incomplete: %+v", ingredient.ID, ingredient)
+		}
+	}
+}
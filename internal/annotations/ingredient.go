@@ -35,12 +35,15 @@ func (i *Ingredient) DoNormalize() {
 	}
 }
 
-var ingredientOrder = fmap.Order(
+// ingredients holds all known ingredients in display order.
+var ingredients = []Ingredient{
 	Vegetarian,
 	Beef, Poultry, Lamb, FishI, Pork, Game,
 	Vegan, MensaVital, Organic, FishMSC,
 	Alcohol, Glutenfree, CO2Neutral,
-)
+}
+
+var ingredientOrder = fmap.Order(ingredients...)
 
 var ingredientEN = map[Ingredient]string{
 	Vegetarian: "vegetarian",
@@ -95,6 +98,11 @@ func (i Ingredient) Normalize() (Ingredient, bool) {
 	return key, ok
 }
 
+// MessageKey returns the key of the message translating this ingredient.
+func (i Ingredient) MessageKey() string {
+	return "ingredient." + string(i)
+}
+
 func (i Ingredient) ENString() string {
 	return ingredientEN[i]
 }
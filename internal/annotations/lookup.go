@@ -0,0 +1,62 @@
+package annotations
+
+import "strings"
+
+// Annotation is a known additive, allergen or ingredient.
+type Annotation interface {
+	MessageKey() string
+	DEString() string
+	ENString() string
+}
+
+// Lookup returns the known annotation with the given code.
+// Additives take precedence over allergens, which take precedence over ingredients.
+func Lookup(code string) (Annotation, bool) {
+	if add, ok := Additive(code).Normalize(); ok {
+		return add, true
+	}
+	if all, ok := Allergen(code).Normalize(); ok {
+		return all, true
+	}
+	if ing, ok := Ingredient(code).Normalize(); ok {
+		return ing, true
+	}
+	return nil, false
+}
+
+// Resolve replaces the annotations in text with their names, as returned by name.
+//
+// Typos are corrected before looking up annotations, see [FixTypo].
+// Unknown annotations are kept as-is, and groups without any known annotation are not changed.
+func Resolve(text string, name func(Annotation) string) string {
+	var builder strings.Builder
+	for _, node := range Parse(text) {
+		switch node := node.(type) {
+		case Text:
+			builder.WriteString(node.Value)
+		case Group:
+			var names []string
+			known := false
+			for _, token := range node.Tokens {
+				for _, fixed := range FixTypo(token.Value) {
+					annot, ok := Lookup(fixed)
+					if !ok {
+						names = append(names, fixed)
+						continue
+					}
+					known = true
+					names = append(names, name(annot))
+				}
+			}
+
+			builder.WriteByte('(')
+			if known {
+				builder.WriteString(strings.Join(names, ", "))
+			} else {
+				builder.WriteString(node.Value)
+			}
+			builder.WriteByte(')')
+		}
+	}
+	return builder.String()
+}
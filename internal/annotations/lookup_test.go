@@ -0,0 +1,50 @@
+package annotations_test
+
+import (
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   annotations.Annotation
+		wantOK bool
+	}{
+		{"2", annotations.Additive("2"), true},
+		{"Wz", annotations.Wheat, true},
+		{"veg", annotations.Ingredient("veg"), true},
+		{"Xy", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := annotations.Lookup(tt.code)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Lookup() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	name := func(annot annotations.Annotation) string { return annot.ENString() }
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text", "Tomato soup", "Tomato soup"},
+		{"known annotations", "Schnitzel (Ei,Mi) with fries", "Schnitzel (eggs, milk/lactose) with fries"},
+		{"unknown annotations kept", "Soup (Sel,Xy)", "Soup (celeriac, Xy)"},
+		{"no known annotations", "Pasta (al dente)", "Pasta (al dente)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotations.Resolve(tt.text, name); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
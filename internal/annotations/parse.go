@@ -0,0 +1,127 @@
+package annotations
+
+import "strings"
+
+// Span represents the range of bytes [Start, End) within a parsed text.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Node is a node of a parsed text.
+// It is either a [Text] or a [Group].
+type Node interface {
+	Pos() Span
+}
+
+// Text is a node of plain text, not containing any annotations.
+type Text struct {
+	Span
+	Value string
+}
+
+func (t Text) Pos() Span { return t.Span }
+
+// Group is a node representing a group of annotations in parentheses, such as "(Wz,Mi)".
+// The span includes the parentheses.
+type Group struct {
+	Span
+	Value  string  // value between the parentheses
+	Tokens []Token // individual annotations within the group
+}
+
+func (g Group) Pos() Span { return g.Span }
+
+// Token is a single annotation within a group, such as "Wz".
+type Token struct {
+	Span
+	Value string
+}
+
+// Parse parses text into a sequence of text and annotation groups.
+//
+// A group is an opening parenthesis, followed by at least one character that is neither ascii whitespace nor a closing parenthesis, followed by a closing parenthesis.
+// Parentheses that do not form a group are kept as text.
+// Text nodes are never empty, and adjacent text is merged into a single node.
+func Parse(text string) (nodes []Node) {
+	textStart := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '(' {
+			continue
+		}
+
+		end, ok := groupEnd(text, i)
+		if !ok {
+			continue
+		}
+
+		if textStart < i {
+			nodes = append(nodes, Text{Span: Span{Start: textStart, End: i}, Value: text[textStart:i]})
+		}
+
+		value := text[i+1 : end-1]
+		nodes = append(nodes, Group{
+			Span:   Span{Start: i, End: end},
+			Value:  value,
+			Tokens: Tokenize(value, i+1),
+		})
+
+		textStart = end
+		i = end - 1
+	}
+
+	if textStart < len(text) {
+		nodes = append(nodes, Text{Span: Span{Start: textStart, End: len(text)}, Value: text[textStart:]})
+	}
+	return nodes
+}
+
+// groupEnd checks if a group starts at the opening parenthesis at start.
+// If so, returns the index after the closing parenthesis.
+func groupEnd(text string, start int) (end int, ok bool) {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case ')':
+			return i + 1, i > start+1
+		case ' ', '\t', '\n', '\f', '\r':
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// Tokenize splits the value of a group into individual annotations.
+// Annotations are separated by commas or dots, empty annotations are skipped.
+// Spans of the returned tokens are offset by offset.
+func Tokenize(value string, offset int) (tokens []Token) {
+	start := 0
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) && value[i] != ',' && value[i] != '.' {
+			continue
+		}
+		if start < i {
+			tokens = append(tokens, Token{
+				Span:  Span{Start: offset + start, End: offset + i},
+				Value: value[start:i],
+			})
+		}
+		start = i + 1
+	}
+	return tokens
+}
+
+// String returns the source text of the given nodes.
+func String(nodes []Node) string {
+	var builder strings.Builder
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case Text:
+			builder.WriteString(node.Value)
+		case Group:
+			builder.WriteByte('(')
+			builder.WriteString(node.Value)
+			builder.WriteByte(')')
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,89 @@
+package annotations_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []annotations.Node
+	}{
+		{
+			name: "empty",
+			text: "",
+			want: nil,
+		},
+		{
+			name: "plain text",
+			text: "Pommes frites",
+			want: []annotations.Node{
+				annotations.Text{Span: annotations.Span{Start: 0, End: 13}, Value: "Pommes frites"},
+			},
+		},
+		{
+			name: "single group",
+			text: "Schnitzel (Wz,Mi)",
+			want: []annotations.Node{
+				annotations.Text{Span: annotations.Span{Start: 0, End: 10}, Value: "Schnitzel "},
+				annotations.Group{Span: annotations.Span{Start: 10, End: 17}, Value: "Wz,Mi", Tokens: []annotations.Token{
+					{Span: annotations.Span{Start: 11, End: 13}, Value: "Wz"},
+					{Span: annotations.Span{Start: 14, End: 16}, Value: "Mi"},
+				}},
+			},
+		},
+		{
+			name: "dots and empty tokens",
+			text: "(1.,2)",
+			want: []annotations.Node{
+				annotations.Group{Span: annotations.Span{Start: 0, End: 6}, Value: "1.,2", Tokens: []annotations.Token{
+					{Span: annotations.Span{Start: 1, End: 2}, Value: "1"},
+					{Span: annotations.Span{Start: 4, End: 5}, Value: "2"},
+				}},
+			},
+		},
+		{
+			name: "parentheses with whitespace are text",
+			text: "Kaffee (mit Milch) (Mi)",
+			want: []annotations.Node{
+				annotations.Text{Span: annotations.Span{Start: 0, End: 19}, Value: "Kaffee (mit Milch) "},
+				annotations.Group{Span: annotations.Span{Start: 19, End: 23}, Value: "Mi", Tokens: []annotations.Token{
+					{Span: annotations.Span{Start: 20, End: 22}, Value: "Mi"},
+				}},
+			},
+		},
+		{
+			name: "empty and unclosed parentheses are text",
+			text: "() (Wz",
+			want: []annotations.Node{
+				annotations.Text{Span: annotations.Span{Start: 0, End: 6}, Value: "() (Wz"},
+			},
+		},
+		{
+			name: "positions are in bytes",
+			text: "Käse (Mi)",
+			want: []annotations.Node{
+				annotations.Text{Span: annotations.Span{Start: 0, End: 6}, Value: "Käse "},
+				annotations.Group{Span: annotations.Span{Start: 6, End: 10}, Value: "Mi", Tokens: []annotations.Token{
+					{Span: annotations.Span{Start: 7, End: 9}, Value: "Mi"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := annotations.Parse(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+			if s := annotations.String(got); s != tt.text {
+				t.Errorf("String(Parse()) = %q, want %q", s, tt.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package annotations
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Typos maps misspelled annotations found upstream to their correct replacements.
+// A token may be replaced by several annotations, or be dropped entirely by mapping it to an empty list.
+type Typos map[string][]string
+
+//go:embed typos.json
+var defaultTyposData []byte
+
+// DefaultTypos returns the typo table built into the binary.
+func DefaultTypos() Typos {
+	typos, err := ParseTypos(defaultTyposData)
+	if err != nil {
+		panic("annotations: invalid default typos: " + err.Error())
+	}
+	return typos
+}
+
+// ReadTypos reads a typo table from the json file at path.
+func ReadTypos(path string) (Typos, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTypos(data)
+}
+
+// ParseTypos parses a typo table encoded as a json object.
+func ParseTypos(data []byte) (Typos, error) {
+	var typos Typos
+	if err := json.Unmarshal(data, &typos); err != nil {
+		return nil, fmt.Errorf("failed to decode typos: %w", err)
+	}
+	for typo, replacements := range typos {
+		for _, r := range replacements {
+			if r == "" {
+				return nil, fmt.Errorf("typo %q: empty replacement", typo)
+			}
+		}
+	}
+	return typos, nil
+}
+
+// Fix returns the corrected annotations for the given token.
+// Tokens not in the table are returned as-is.
+func (typos Typos) Fix(token string) []string {
+	if replacements, ok := typos[token]; ok {
+		return replacements
+	}
+	return []string{token}
+}
+
+// currentTypos holds the typo table used by [FixTypo].
+var currentTypos atomic.Pointer[Typos]
+
+func init() {
+	UseTypos(nil)
+}
+
+// UseTypos replaces the typo table used by [FixTypo].
+// If typos is nil, the default table is used.
+// It is safe to call concurrently with any other function of this package.
+func UseTypos(typos Typos) {
+	if typos == nil {
+		typos = DefaultTypos()
+	}
+	currentTypos.Store(&typos)
+}
+
+// FixTypo corrects the given token using the current typo table.
+func FixTypo(token string) []string {
+	return (*currentTypos.Load()).Fix(token)
+}
@@ -0,0 +1,65 @@
+package annotations_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestTypos_Fix(t *testing.T) {
+	typos := annotations.DefaultTypos()
+
+	tests := []struct {
+		name  string
+		token string
+		want  []string
+	}{
+		{name: "unchanged", token: "Wz", want: []string{"Wz"}},
+		{name: "replaced", token: "Vegan", want: []string{"veg"}},
+		{name: "split", token: "Mi7", want: []string{"Mi", "7"}},
+		{name: "dropped", token: "cond", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := typos.Fix(tt.token); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Typos.Fix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTypos(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"Egg":["Ei"],"cond":[]}`, wantErr: false},
+		{name: "invalid json", data: `{"Egg":`, wantErr: true},
+		{name: "empty replacement", data: `{"Egg":[""]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := annotations.ParseTypos([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseTypos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUseTypos(t *testing.T) {
+	annotations.UseTypos(annotations.Typos{"Wz": []string{"Ro"}})
+	defer annotations.UseTypos(nil)
+
+	if got := annotations.FixTypo("Wz"); !reflect.DeepEqual(got, []string{"Ro"}) {
+		t.Errorf("FixTypo() = %v, want %v", got, []string{"Ro"})
+	}
+
+	annotations.UseTypos(nil)
+	if got := annotations.FixTypo("Wz"); !reflect.DeepEqual(got, []string{"Wz"}) {
+		t.Errorf("FixTypo() after reset = %v, want %v", got, []string{"Wz"})
+	}
+}
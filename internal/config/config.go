@@ -0,0 +1,247 @@
+// Package config implements the configuration of the faulunch server.
+//
+// A configuration is assembled from (in increasing order of precedence)
+// the built-in defaults, an optional json configuration file, environment variables and command line flags.
+package config
+
+//spellchecker:words encoding json errors flag http strings time
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config is the configuration of the server.
+type Config struct {
+	Database string `json:"database"` // path to the sqlite database
+	Debug    bool   `json:"debug"`    // enable debug logging
+
+	Server  Server  `json:"server"`
+	Sync    Sync    `json:"sync"`
+	Fetcher Fetcher `json:"fetcher"`
+	Data    Data    `json:"data"`
+	Legal   Legal   `json:"legal"`
+	Export  Export  `json:"export"`
+	Cache   Cache   `json:"cache"`
+}
+
+// Server configures the http server.
+type Server struct {
+	Addr       string `json:"addr"`       // address to bind to
+	Minify     bool   `json:"minify"`     // minify html, css and javascript
+	AdminToken string `json:"adminToken"` // token to access admin routes, disabled if empty
+
+	ReadTimeout     Duration `json:"readTimeout"`     // maximum duration for reading a request, 0 for no timeout
+	WriteTimeout    Duration `json:"writeTimeout"`    // maximum duration for writing a response, 0 for no timeout
+	IdleTimeout     Duration `json:"idleTimeout"`     // maximum duration to keep idle connections, 0 for no timeout
+	ShutdownTimeout Duration `json:"shutdownTimeout"` // maximum duration to wait for a graceful shutdown
+}
+
+// Sync configures automatic synchronization.
+type Sync struct {
+	Interval Duration `json:"interval"` // interval between syncs, 0 to disable
+}
+
+// Fetcher configures fetching menus from upstream.
+type Fetcher struct {
+	Timeout   Duration `json:"timeout"`   // timeout of a single request, 0 for no timeout
+	UserAgent string   `json:"userAgent"` // user agent to send, empty for the go default
+}
+
+// Data configures json files replacing the built-in data.
+// Empty paths use the built-in data, files are reloaded before every sync.
+type Data struct {
+	Locations string `json:"locations"` // location registry
+	Typos     string `json:"typos"`     // annotation typos
+	Ordering  string `json:"ordering"`  // category ordering
+}
+
+// Legal configures the legal link in the footer of every page.
+type Legal struct {
+	Link string `json:"link"` // url of the legal link, disabled if empty
+	DE   string `json:"de"`   // german text
+	EN   string `json:"en"`   // english text
+}
+
+// Export configures the export of the database.
+type Export struct {
+	Enabled bool `json:"enabled"` // enable the /api/v1/sqlite endpoint
+}
+
+// Cache configures http caching.
+type Cache struct {
+	MaxAge Duration `json:"maxAge"` // maximum age for clients to cache responses, 0 to disable
+	Size   int      `json:"size"`   // maximal size in bytes of responses cached in memory, 0 to disable
+}
+
+// Default returns the default configuration.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:            "127.0.0.1:3000",
+			Minify:          true,
+			ShutdownTimeout: Duration(10 * time.Second),
+		},
+		Fetcher: Fetcher{
+			Timeout: Duration(time.Minute),
+		},
+		Legal: Legal{
+			DE: "Keine offizielle Seite des Studentenwerks. Alle Angaben, insbesondere zu Speiseplänen und Preisen, sind ohne Gewähr. Siehe auch Impressum, Datenschutz und Barrierefreiheitserklärung. ",
+			EN: "Not an official page of Studentenwerk. All information subject to change. See also Imprint, Privacy Policy and Accessibility. ",
+		},
+		Export: Export{
+			Enabled: true,
+		},
+		Cache: Cache{
+			Size: 32 << 20,
+		},
+	}
+}
+
+// ReadFile reads the json configuration file at path into config.
+// Values not contained in the file are kept.
+func (config *Config) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("failed to decode config file %q: %w", path, err)
+	}
+	return nil
+}
+
+var (
+	errMissingDatabase   = errors.New("database: must not be empty")
+	errMissingLink       = errors.New("legal.link: must be an absolute http(s) url")
+	errNegativeCacheSize = errors.New("cache.size: must not be negative")
+)
+
+// Validate checks that this configuration is valid.
+// The database is only required if opts contains [OptDatabase].
+// All problems found are returned.
+func (config Config) Validate(opts Options) error {
+	var errs []error
+
+	if opts&OptDatabase != 0 && config.Database == "" {
+		errs = append(errs, errMissingDatabase)
+	}
+
+	if _, port, err := net.SplitHostPort(config.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr: %w", err))
+	} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr: invalid port %q", port))
+	}
+
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"server.readTimeout", config.Server.ReadTimeout},
+		{"server.writeTimeout", config.Server.WriteTimeout},
+		{"server.idleTimeout", config.Server.IdleTimeout},
+		{"server.shutdownTimeout", config.Server.ShutdownTimeout},
+		{"sync.interval", config.Sync.Interval},
+		{"fetcher.timeout", config.Fetcher.Timeout},
+		{"cache.maxAge", config.Cache.MaxAge},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative", d.name))
+		}
+	}
+
+	if config.Cache.Size < 0 {
+		errs = append(errs, errNegativeCacheSize)
+	}
+
+	for _, f := range []struct {
+		name string
+		path string
+	}{
+		{"data.locations", config.Data.Locations},
+		{"data.typos", config.Data.Typos},
+		{"data.ordering", config.Data.Ordering},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name, err))
+		}
+	}
+
+	if config.Legal.Link != "" {
+		u, err := url.Parse(config.Legal.Link)
+		if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, errMissingLink)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Duration is a [time.Duration] that is encoded as a string such as "12h" in json.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set parses the duration from a string, see [time.ParseDuration].
+func (d *Duration) Set(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"12h\": %w", err)
+	}
+	return d.Set(value)
+}
+
+var _ flag.Value = (*Duration)(nil)
+
+// Client returns an http client to fetch menus with.
+func (fetcher Fetcher) Client() *http.Client {
+	var transport http.RoundTripper = http.DefaultTransport
+	if fetcher.UserAgent != "" {
+		transport = userAgentTransport{userAgent: fetcher.UserAgent, next: transport}
+	}
+	return &http.Client{
+		Timeout:   time.Duration(fetcher.Timeout),
+		Transport: transport,
+	}
+}
+
+// userAgentTransport sets the user agent of all requests.
+type userAgentTransport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
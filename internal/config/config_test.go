@@ -0,0 +1,154 @@
+package config_test
+
+//spellchecker:words errors path filepath strings testing time github faulunch internal config
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/config"
+)
+
+// env returns a getenv function for the given environment.
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `{"database":"file.db","server":{"addr":"0.0.0.0:8080","readTimeout":"5s"},"sync":{"interval":"1h"},"export":{"enabled":false}}`)
+
+	tests := []struct {
+		name  string
+		args  []string
+		env   map[string]string
+		check func(t *testing.T, c config.Config)
+	}{
+		{
+			name: "defaults",
+			args: []string{"-database", "test.db", "extra"},
+			check: func(t *testing.T, c config.Config) {
+				if c.Database != "test.db" || c.Server.Addr != "127.0.0.1:3000" || !c.Export.Enabled || !c.Server.Minify {
+					t.Errorf("unexpected config %+v", c)
+				}
+			},
+		},
+		{
+			name: "file",
+			args: []string{"-config", path},
+			check: func(t *testing.T, c config.Config) {
+				if c.Database != "file.db" || c.Server.Addr != "0.0.0.0:8080" || c.Export.Enabled {
+					t.Errorf("unexpected config %+v", c)
+				}
+				if c.Server.ReadTimeout != config.Duration(5*time.Second) || c.Sync.Interval != config.Duration(time.Hour) {
+					t.Errorf("unexpected durations %+v", c)
+				}
+			},
+		},
+		{
+			name: "environment overrides file",
+			env:  map[string]string{config.EnvConfigFile: path, "FAULUNCH_ADDR": ":9000", "FAULUNCH_EXPORT": "true"},
+			check: func(t *testing.T, c config.Config) {
+				if c.Database != "file.db" || c.Server.Addr != ":9000" || !c.Export.Enabled {
+					t.Errorf("unexpected config %+v", c)
+				}
+			},
+		},
+		{
+			name: "flags override environment",
+			args: []string{"-config", path, "-addr", ":9001", "-no-minify", "-sync", "30m", "-database", "flag.db"},
+			env:  map[string]string{"FAULUNCH_ADDR": ":9000", "FAULUNCH_DATABASE": "env.db"},
+			check: func(t *testing.T, c config.Config) {
+				if c.Database != "flag.db" || c.Server.Addr != ":9001" || c.Server.Minify || c.Sync.Interval != config.Duration(30*time.Minute) {
+					t.Errorf("unexpected config %+v", c)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, err := config.Load("test", "", config.OptAll, nil, tt.args, env(tt.env), io.Discard)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if err := c.Validate(config.OptAll); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestLoad_args(t *testing.T) {
+	_, args, err := config.Load("test", "<location>", config.OptDatabase, nil, []string{"-debug", "mensa-sued", "extra"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(args) != 2 || args[0] != "mensa-sued" || args[1] != "extra" {
+		t.Errorf("Load() args = %v", args)
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		args    []string
+		env     map[string]string
+		opts    config.Options
+		wantErr []string
+	}{
+		{name: "missing database", wantErr: []string{"database"}},
+		{name: "unknown field", file: `{"database":"test.db","unknown":true}`, wantErr: []string{"unknown field"}},
+		{name: "invalid duration", file: `{"database":"test.db","sync":{"interval":12}}`, wantErr: []string{"duration"}},
+		{name: "invalid environment", env: map[string]string{"FAULUNCH_SYNC": "often"}, wantErr: []string{"$FAULUNCH_SYNC"}},
+		{name: "invalid cache size", args: []string{"-database", "test.db", "-cache-size", "big"}, wantErr: []string{"-cache-size"}},
+		{name: "negative cache size", env: map[string]string{"FAULUNCH_DATABASE": "test.db", "FAULUNCH_CACHE_SIZE": "-1"}, wantErr: []string{"cache.size"}},
+		{name: "flag of other group", args: []string{"-database", "test.db", "-addr", ":9000"}, opts: config.OptDatabase, wantErr: []string{"-addr"}},
+		{
+			name:    "several problems",
+			file:    `{"server":{"addr":"nope","readTimeout":"-1s"},"data":{"typos":"/does/not/exist.json"},"legal":{"link":"imprint"}}`,
+			wantErr: []string{"database", "server.addr", "server.readTimeout", "data.typos", "legal.link"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeConfig(t, tt.file)}, args...)
+			}
+
+			opts := tt.opts
+			if opts == 0 {
+				opts = config.OptAll
+			}
+
+			c, _, err := config.Load("test", "", opts, nil, args, env(tt.env), io.Discard)
+			if err == nil {
+				err = c.Validate(opts)
+			}
+			if err == nil {
+				t.Fatal("Load() error = nil, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
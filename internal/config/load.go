@@ -0,0 +1,212 @@
+package config
+
+//spellchecker:words flag strconv strings
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// EnvConfigFile is the environment variable holding the path to the configuration file.
+const EnvConfigFile = "FAULUNCH_CONFIG"
+
+// Options is a set of groups of options that can be set using command line flags.
+type Options uint
+
+const (
+	OptDatabase Options = 1 << iota // the database
+	OptServer                       // the http server
+	OptSync                         // automatic synchronization
+	OptFetcher                      // fetching menus from upstream
+	OptData                         // files replacing the built-in data
+	OptLegal                        // legal texts
+	OptExport                       // database export
+	OptCache                        // http caching
+
+	OptAll = OptDatabase | OptServer | OptSync | OptFetcher | OptData | OptLegal | OptExport | OptCache
+)
+
+// option is a configuration option that can be set using an environment variable and a command line flag.
+type option struct {
+	group Options
+	env   string // name of the environment variable
+	flag  string // name of the flag, empty if none
+	usage string
+	value func(config *Config) flag.Value
+}
+
+// options are all options that can be set using environment variables or flags.
+var options = []option{
+	{OptDatabase, "FAULUNCH_DATABASE", "database", "`path` to the sqlite database", func(c *Config) flag.Value { return (*stringValue)(&c.Database) }},
+	{0, "FAULUNCH_DEBUG", "debug", "set debug log level", func(c *Config) flag.Value { return (*boolValue)(&c.Debug) }},
+
+	{OptServer, "FAULUNCH_ADDR", "addr", "`address` to bind to", func(c *Config) flag.Value { return (*stringValue)(&c.Server.Addr) }},
+	{OptServer, "FAULUNCH_MINIFY", "", "minify sources", func(c *Config) flag.Value { return (*boolValue)(&c.Server.Minify) }},
+	{OptServer, "", "no-minify", "do not minify sources", func(c *Config) flag.Value { return (*notValue)(&c.Server.Minify) }},
+	{OptServer, "FAULUNCH_ADMIN_TOKEN", "admin-token", "`token` to access admin routes, disabled if empty", func(c *Config) flag.Value { return (*stringValue)(&c.Server.AdminToken) }},
+	{OptServer, "FAULUNCH_READ_TIMEOUT", "read-timeout", "maximum `duration` for reading a request", func(c *Config) flag.Value { return &c.Server.ReadTimeout }},
+	{OptServer, "FAULUNCH_WRITE_TIMEOUT", "write-timeout", "maximum `duration` for writing a response", func(c *Config) flag.Value { return &c.Server.WriteTimeout }},
+	{OptServer, "FAULUNCH_IDLE_TIMEOUT", "idle-timeout", "maximum `duration` to keep idle connections open", func(c *Config) flag.Value { return &c.Server.IdleTimeout }},
+	{OptServer, "FAULUNCH_SHUTDOWN_TIMEOUT", "shutdown-timeout", "maximum `duration` to wait for a graceful shutdown", func(c *Config) flag.Value { return &c.Server.ShutdownTimeout }},
+
+	{OptSync, "FAULUNCH_SYNC", "sync", "automatically sync every `interval`", func(c *Config) flag.Value { return &c.Sync.Interval }},
+
+	{OptFetcher, "FAULUNCH_FETCH_TIMEOUT", "fetch-timeout", "`timeout` for fetching a single menu from upstream", func(c *Config) flag.Value { return &c.Fetcher.Timeout }},
+	{OptFetcher, "FAULUNCH_USER_AGENT", "user-agent", "user `agent` to use when fetching menus from upstream", func(c *Config) flag.Value { return (*stringValue)(&c.Fetcher.UserAgent) }},
+
+	{OptData, "FAULUNCH_LOCATIONS", "locations", "json `file` with locations to use instead of the built-in ones, reloaded before every sync", func(c *Config) flag.Value { return (*stringValue)(&c.Data.Locations) }},
+	{OptData, "FAULUNCH_TYPOS", "typos", "json `file` with annotation typos to use instead of the built-in ones, reloaded before every sync", func(c *Config) flag.Value { return (*stringValue)(&c.Data.Typos) }},
+	{OptData, "FAULUNCH_ORDERING", "ordering", "json `file` with category ordering rules to use instead of the built-in ones, reloaded before every sync", func(c *Config) flag.Value { return (*stringValue)(&c.Data.Ordering) }},
+
+	{OptLegal, "FAULUNCH_LEGAL_LINK", "legal-link", "`url` for legal link", func(c *Config) flag.Value { return (*stringValue)(&c.Legal.Link) }},
+	{OptLegal, "FAULUNCH_LEGAL_DE", "legal-de", "`text` for german legal link", func(c *Config) flag.Value { return (*stringValue)(&c.Legal.DE) }},
+	{OptLegal, "FAULUNCH_LEGAL_EN", "legal-en", "`text` for english legal link", func(c *Config) flag.Value { return (*stringValue)(&c.Legal.EN) }},
+
+	{OptExport, "FAULUNCH_EXPORT", "", "enable the /api/v1/sqlite endpoint", func(c *Config) flag.Value { return (*boolValue)(&c.Export.Enabled) }},
+	{OptExport, "", "no-export", "disable the /api/v1/sqlite endpoint", func(c *Config) flag.Value { return (*notValue)(&c.Export.Enabled) }},
+
+	{OptCache, "FAULUNCH_CACHE_MAX_AGE", "cache-max-age", "maximum `age` for clients to cache responses, 0 to disable", func(c *Config) flag.Value { return &c.Cache.MaxAge }},
+	{OptCache, "FAULUNCH_CACHE_SIZE", "cache-size", "maximal `bytes` of responses to cache in memory, 0 to disable", func(c *Config) flag.Value { return (*intValue)(&c.Cache.Size) }},
+}
+
+// ErrInvalidFlags is returned by [Load] when the command line flags are invalid.
+// In this case, the error and usage have already been written to the output.
+var ErrInvalidFlags = errors.New("invalid flags")
+
+// Load loads the configuration from the given command line arguments and environment.
+//
+// Only options in opts can be set using flags, all options can be set using the environment and the configuration file.
+// The configuration file is taken from the "-config" flag, or the [EnvConfigFile] environment variable.
+// usage describes the positional arguments, which are returned.
+// If extra is not nil, it is called to register additional flags not belonging to the configuration.
+//
+// The returned configuration is not validated, see [Config.Validate].
+func Load(name, usage string, opts Options, extra func(flags *flag.FlagSet), args []string, getenv func(string) string, output io.Writer) (Config, []string, error) {
+	// parse the flags once to find the config file, and check that they are valid
+	var path string
+	{
+		config := Default()
+		flags := newFlagSet(name, usage, opts, extra, &config, &path, output)
+		if err := flags.Parse(args); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				err = fmt.Errorf("%w: %w", ErrInvalidFlags, err)
+			}
+			return Config{}, nil, err
+		}
+	}
+	if path == "" {
+		path = getenv(EnvConfigFile)
+	}
+
+	config := Default()
+	if path != "" {
+		if err := config.ReadFile(path); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	if err := config.applyEnv(getenv); err != nil {
+		return Config{}, nil, err
+	}
+
+	// parse the flags again, to override values from the file and environment.
+	// they were already validated above, so this can not fail.
+	flags := newFlagSet(name, usage, opts, extra, &config, &path, io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return Config{}, nil, fmt.Errorf("%w: %w", ErrInvalidFlags, err)
+	}
+	return config, flags.Args(), nil
+}
+
+// newFlagSet creates a new flag set setting the options of config in opts.
+func newFlagSet(name, usage string, opts Options, extra func(flags *flag.FlagSet), config *Config, path *string, output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: %s [...flags] %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(path, "config", *path, "json configuration `file`, see also $"+EnvConfigFile)
+	for _, opt := range options {
+		if opt.flag == "" || (opt.group != 0 && opt.group&opts == 0) {
+			continue
+		}
+		usage := opt.usage
+		if opt.env != "" {
+			usage += " (environment: $" + opt.env + ")"
+		}
+		flags.Var(opt.value(config), opt.flag, usage)
+	}
+	if extra != nil {
+		extra(flags)
+	}
+	return flags
+}
+
+// applyEnv applies all options set in the environment.
+func (config *Config) applyEnv(getenv func(string) string) error {
+	var errs []error
+	for _, opt := range options {
+		if opt.env == "" {
+			continue
+		}
+		value := getenv(opt.env)
+		if value == "" {
+			continue
+		}
+		if err := opt.value(config).Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("$%s: %w", opt.env, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stringValue implements [flag.Value] for a string.
+type stringValue string
+
+func (s *stringValue) String() string     { return string(*s) }
+func (s *stringValue) Set(v string) error { *s = stringValue(v); return nil }
+
+// intValue implements [flag.Value] for an integer.
+type intValue int
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+func (i *intValue) Set(v string) error {
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*i = intValue(parsed)
+	return nil
+}
+
+// boolValue implements [flag.Value] for a boolean.
+type boolValue bool
+
+func (b *boolValue) String() string   { return strconv.FormatBool(bool(*b)) }
+func (b *boolValue) IsBoolFlag() bool { return true }
+func (b *boolValue) Set(v string) error {
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(parsed)
+	return nil
+}
+
+// notValue implements [flag.Value] for a boolean that is set to the negated value.
+type notValue bool
+
+func (n *notValue) String() string   { return strconv.FormatBool(!bool(*n)) }
+func (n *notValue) IsBoolFlag() bool { return true }
+func (n *notValue) Set(v string) error {
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*n = notValue(!parsed)
+	return nil
+}
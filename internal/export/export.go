@@ -80,6 +80,13 @@ func (e *exporter) cleanup() error {
 	return nil
 }
 
+// Vacuum writes a consistent copy of the sqlite database db to a new file at path.
+// The file must not exist.
+func Vacuum(ctx context.Context, db *sql.DB, path string) error {
+	_, err := db.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
+
 // getCurrentID runs the query and returns the current identifier
 func (e *exporter) getCurrentID() (string, error) {
 	var id string
@@ -146,8 +153,7 @@ func (e *exporter) ensureExport() (string, time.Time, error) {
 	}
 
 	// Run VACUUM INTO
-	_, err = e.db.ExecContext(e.ctx, "VACUUM INTO ?", tmpPath)
-	if err != nil {
+	if err := Vacuum(e.ctx, e.db, tmpPath); err != nil {
 		os.Remove(tmpPath)
 		return "", time.Time{}, err
 	}
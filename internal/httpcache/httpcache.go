@@ -0,0 +1,170 @@
+// Package httpcache implements an in-memory cache of http responses.
+//
+//spellchecker:words httpcache
+package httpcache
+
+//spellchecker:words container list crypto sha256 encoding http strings sync time github faulunch internal
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	ETag   string // strong entity tag of the body, see [ETag]
+}
+
+// size returns the approximate number of bytes used by the entry.
+func (entry *Entry) size(key string) int {
+	size := len(key) + len(entry.Body) + len(entry.ETag)
+	for name, values := range entry.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	return size
+}
+
+// Cache holds responses for a single version of the underlying data.
+// When a different version is requested, all responses are dropped.
+//
+// If responses exceed MaxBytes, the least recently used ones are dropped.
+// A Cache may be used concurrently.
+type Cache struct {
+	MaxBytes int
+
+	m       sync.Mutex
+	version string
+	size    int
+	lru     list.List // of *element, most recently used first
+	entries map[string]*list.Element
+}
+
+type element struct {
+	key   string
+	entry *Entry
+	size  int
+}
+
+// Get returns the response cached under key for the given version.
+func (cache *Cache) Get(version, key string) (*Entry, bool) {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+
+	cache.setVersion(version)
+
+	elem, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	cache.lru.MoveToFront(elem)
+	return elem.Value.(*element).entry, true
+}
+
+// Add caches entry under key for the given version.
+// Entries larger than MaxBytes are not cached.
+// The entry must not be modified afterwards.
+func (cache *Cache) Add(version, key string, entry *Entry) {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+
+	cache.setVersion(version)
+
+	size := entry.size(key)
+	if size > cache.MaxBytes {
+		return
+	}
+	if elem, ok := cache.entries[key]; ok {
+		cache.remove(elem)
+	}
+
+	cache.entries[key] = cache.lru.PushFront(&element{key: key, entry: entry, size: size})
+	cache.size += size
+
+	for cache.size > cache.MaxBytes {
+		cache.remove(cache.lru.Back())
+	}
+}
+
+// Len returns the number of cached responses.
+func (cache *Cache) Len() int {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+
+	return len(cache.entries)
+}
+
+// setVersion drops all responses unless version is the current version.
+// The caller must hold the lock.
+func (cache *Cache) setVersion(version string) {
+	if cache.entries != nil && cache.version == version {
+		return
+	}
+
+	cache.version = version
+	cache.size = 0
+	cache.lru.Init()
+	cache.entries = make(map[string]*list.Element)
+}
+
+// remove removes the given element.
+// The caller must hold the lock.
+func (cache *Cache) remove(elem *list.Element) {
+	e := cache.lru.Remove(elem).(*element)
+	delete(cache.entries, e.key)
+	cache.size -= e.size
+}
+
+// ETag returns a strong entity tag for the given body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified checks if a conditional GET or HEAD request can be answered with status 304, as per RFC 9110, Section 13.2.2.
+// etag is the current entity tag of the response, modified the time it was last modified.
+//
+// If-Modified-Since is only evaluated if the request has no If-None-Match header.
+func NotModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if values := r.Header.Values("If-None-Match"); len(values) > 0 {
+		for _, value := range values {
+			for tag := range strings.SplitSeq(value, ",") {
+				tag = strings.TrimSpace(tag)
+				if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// Record calls handler and records its response.
+// The header of the response starts as a copy of header.
+func Record(handler http.Handler, r *http.Request, header http.Header) *Entry {
+	rec := &internal.ResponseRecorder{Headers: header.Clone()}
+	handler.ServeHTTP(rec, r)
+
+	return &Entry{Status: rec.StatusCode(), Header: rec.Header(), Body: rec.Body.Bytes()}
+}
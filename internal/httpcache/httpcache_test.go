@@ -0,0 +1,110 @@
+package httpcache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/httpcache"
+)
+
+func TestCache(t *testing.T) {
+	entry := func(body string) *httpcache.Entry {
+		return &httpcache.Entry{Status: http.StatusOK, Body: []byte(body)}
+	}
+
+	cache := &httpcache.Cache{MaxBytes: 20}
+
+	cache.Add("v1", "a", entry("aaaaaaa"))
+	cache.Add("v1", "b", entry("bbbbbbb"))
+	if got, ok := cache.Get("v1", "a"); !ok || string(got.Body) != "aaaaaaa" {
+		t.Fatalf("Get(a) = %v, %v, want cached entry", got, ok)
+	}
+
+	// "b" is the least recently used entry
+	cache.Add("v1", "c", entry("ccccccc"))
+	if _, ok := cache.Get("v1", "b"); ok {
+		t.Error("Get(b) found entry, want it to be evicted")
+	}
+	if _, ok := cache.Get("v1", "a"); !ok {
+		t.Error("Get(a) did not find entry")
+	}
+
+	// entries larger than the cache are not stored
+	cache.Add("v1", "d", entry("ddddddddddddddddddddd"))
+	if _, ok := cache.Get("v1", "d"); ok {
+		t.Error("Get(d) found entry larger than the cache")
+	}
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+
+	// a new version drops all entries
+	if _, ok := cache.Get("v2", "a"); ok {
+		t.Error("Get(v2, a) found entry of old version")
+	}
+	if got := cache.Len(); got != 0 {
+		t.Errorf("Len() = %d after version change, want 0", got)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	modified := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		want   bool
+	}{
+		{"unconditional", http.MethodGet, nil, false},
+		{"matching etag", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, true},
+		{"weak etag", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"list of etags", http.MethodHead, map[string]string{"If-None-Match": `"x", "abc"`}, true},
+		{"wildcard", http.MethodGet, map[string]string{"If-None-Match": "*"}, true},
+		{"other etag", http.MethodGet, map[string]string{"If-None-Match": `"x"`}, false},
+		{"not modified since", http.MethodGet, map[string]string{"If-Modified-Since": "Mon, 19 Oct 2026 12:00:00 GMT"}, true},
+		{"modified since", http.MethodGet, map[string]string{"If-Modified-Since": "Mon, 19 Oct 2026 11:59:59 GMT"}, false},
+		{"invalid date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{"etag takes precedence", http.MethodGet, map[string]string{"If-None-Match": `"x"`, "If-Modified-Since": "Mon, 19 Oct 2026 12:00:00 GMT"}, false},
+		{"post", http.MethodPost, map[string]string{"If-None-Match": `"abc"`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for name, value := range tt.header {
+				r.Header.Set(name, value)
+			}
+			if got := httpcache.NotModified(r, etag, modified); got != tt.want {
+				t.Errorf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})
+
+	header := http.Header{"Vary": {"Accept"}}
+	entry := httpcache.Record(handler, httptest.NewRequest(http.MethodGet, "/", nil), header)
+
+	if entry.Status != http.StatusTeapot || string(entry.Body) != "hello" {
+		t.Errorf("Record() = %d %q, want %d %q", entry.Status, entry.Body, http.StatusTeapot, "hello")
+	}
+	if entry.Header.Get("Content-Type") != "text/plain" || entry.Header.Get("Vary") != "Accept" {
+		t.Errorf("Record() header = %v", entry.Header)
+	}
+	if header.Get("Content-Type") != "" {
+		t.Error("Record() modified the initial header")
+	}
+
+	if a, b := httpcache.ETag([]byte("hello")), httpcache.ETag([]byte("world")); a == b || a[0] != '"' {
+		t.Errorf("ETag() = %s, %s, want distinct quoted tags", a, b)
+	}
+}
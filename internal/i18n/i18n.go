@@ -0,0 +1,168 @@
+// Package i18n implements translations of the user interface.
+//
+// Messages are stored in one json file per language inside the locales directory.
+// Each file maps message keys to format strings understood by [message.Printer].
+// Adding a language only requires adding a file; missing messages fall back to english.
+package i18n
+
+//spellchecker:words embed encoding json path slices strings golang text language message catalog
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// Language is a language of the user interface.
+type Language struct {
+	tag  language.Tag
+	code string // code used in urls, such as "en"
+}
+
+var (
+	// English is the english language, which is also used as a fallback.
+	English = Language{tag: language.English, code: "en"}
+
+	// German is the german language.
+	German = Language{tag: language.German, code: "de"}
+)
+
+var (
+	languages []Language                   // supported languages, english first
+	messages  map[string]map[string]string // messages indexed by language code and key
+	builder   = catalog.NewBuilder(catalog.Fallback(language.English))
+	matcher   language.Matcher
+)
+
+func init() {
+	if err := load(); err != nil {
+		panic(fmt.Sprintf("i18n: %v", err))
+	}
+}
+
+// load loads all locales from the embedded files.
+func load() error {
+	files, err := localesFS.ReadDir("locales")
+	if err != nil {
+		return err
+	}
+
+	messages = make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		code, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok {
+			continue
+		}
+
+		tag, err := language.Parse(code)
+		if err != nil {
+			return fmt.Errorf("locale %q: %w", file.Name(), err)
+		}
+
+		data, err := localesFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return err
+		}
+
+		var msgs map[string]string
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return fmt.Errorf("locale %q: %w", file.Name(), err)
+		}
+
+		for key, msg := range msgs {
+			if err := builder.SetString(tag, key, msg); err != nil {
+				return fmt.Errorf("locale %q: message %q: %w", file.Name(), key, err)
+			}
+		}
+
+		messages[code] = msgs
+		languages = append(languages, Language{tag: tag, code: code})
+	}
+
+	// english is the default language, so it must come first for the matcher
+	index := slices.IndexFunc(languages, func(l Language) bool { return l == English })
+	if index < 0 {
+		return fmt.Errorf("missing english locale")
+	}
+	languages[0], languages[index] = languages[index], languages[0]
+
+	tags := make([]language.Tag, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	matcher = language.NewMatcher(tags)
+	return nil
+}
+
+// Languages returns all supported languages, starting with english.
+func Languages() []Language {
+	return slices.Clone(languages)
+}
+
+// Lookup returns the supported language with the given code.
+func Lookup(code string) (Language, bool) {
+	index := slices.IndexFunc(languages, func(l Language) bool { return l.code == code })
+	if index < 0 {
+		return Language{}, false
+	}
+	return languages[index], true
+}
+
+// Match returns the supported language best matching the given Accept-Language header.
+// Falls back to english.
+func Match(acceptLanguage string) Language {
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	return languages[index]
+}
+
+// Code returns the code of this language, as used in urls and html lang attributes.
+func (l Language) Code() string {
+	return l.code
+}
+
+// String returns the code of this language.
+func (l Language) String() string {
+	return l.code
+}
+
+// German checks if this language is german.
+func (l Language) German() bool {
+	return l.code == German.code
+}
+
+// English checks if upstream texts should be shown in english for this language.
+// Upstream texts only exist in german and english, so this is true for every language except german.
+func (l Language) English() bool {
+	return !l.German()
+}
+
+// lookup returns the raw message with the given key in this language, without any fallback.
+func (l Language) lookup(key string) (string, bool) {
+	msg, ok := messages[l.code][key]
+	return msg, ok
+}
+
+// Has checks if this language has a message with the given key.
+func (l Language) Has(key string) bool {
+	_, ok := l.lookup(key)
+	return ok
+}
+
+// printer returns a printer for the message with the given key.
+// If this language does not define the message, an english printer is returned.
+func (l Language) printer(key string) *message.Printer {
+	tag := l.tag
+	if !l.Has(key) {
+		tag = English.tag
+	}
+	return message.NewPrinter(tag, message.Catalog(builder))
+}
@@ -0,0 +1,162 @@
+package i18n_test
+
+import (
+	"html/template"
+	"testing"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+func mustLookup(t *testing.T, code string) i18n.Language {
+	t.Helper()
+	lang, ok := i18n.Lookup(code)
+	if !ok {
+		t.Fatalf("Lookup(%q) failed", code)
+	}
+	return lang
+}
+
+func TestLanguages(t *testing.T) {
+	languages := i18n.Languages()
+	if len(languages) < 2 {
+		t.Fatalf("Languages() = %v, want at least english and german", languages)
+	}
+	if languages[0] != i18n.English {
+		t.Errorf("Languages()[0] = %v, want english", languages[0])
+	}
+	if _, ok := i18n.Lookup("de"); !ok {
+		t.Error("Lookup(de) failed")
+	}
+	if _, ok := i18n.Lookup("xx"); ok {
+		t.Error("Lookup(xx) succeeded")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"de-DE,de;q=0.9", "de"},
+		{"en-US,en;q=0.9,de;q=0.8", "en"},
+		{"fr-FR,fr;q=0.9", "fr"},
+		{"ja", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := i18n.Match(tt.header).Code(); got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguage_Sprintf(t *testing.T) {
+	fr := mustLookup(t, "fr")
+
+	if got := i18n.German.Sprintf("menu.this"); got != "Dieses Menü" {
+		t.Errorf("Sprintf(menu.this) = %q", got)
+	}
+	if got := i18n.English.Sprintf("index.menu-for", "Südmensa"); got != "Menu for Südmensa" {
+		t.Errorf("Sprintf(index.menu-for) = %q", got)
+	}
+
+	// german does not define this message, so it falls back to english
+	if got, want := i18n.German.Sprintf("menu.only-german"), i18n.English.Sprintf("menu.only-german"); got != want {
+		t.Errorf("Sprintf(menu.only-german) = %q, want %q", got, want)
+	}
+
+	if got := fr.Sprintf("menu.other"); got != "Autres menus" {
+		t.Errorf("Sprintf(menu.other) = %q", got)
+	}
+}
+
+func TestLanguage_HTML(t *testing.T) {
+	got := i18n.English.HTML("closed.today", "<b>", template.HTML("<i>here</i>"))
+	want := template.HTML("<em>&lt;b&gt;</em> (<i>here</i>) is closed today.")
+	if got != want {
+		t.Errorf("HTML() = %q, want %q", got, want)
+	}
+}
+
+// named implements [i18n.Named] and [i18n.Keyed].
+type named string
+
+func (n named) DEString() string   { return "de" }
+func (n named) ENString() string   { return "en" }
+func (n named) MessageKey() string { return string(n) }
+
+func TestLanguage_Name(t *testing.T) {
+	fr := mustLookup(t, "fr")
+
+	tests := []struct {
+		name  string
+		lang  i18n.Language
+		value i18n.Named
+		want  string
+	}{
+		{"german built-in", i18n.German, named("unknown.key"), "de"},
+		{"english built-in", i18n.English, named("unknown.key"), "en"},
+		{"french fallback", fr, named("unknown.key"), "en"},
+		{"french message", fr, named("diet.vegan"), "Végétalien"},
+		{"weekday", fr, i18n.Weekday(time.Monday), "lundi"},
+		{"weekday built-in", i18n.German, i18n.Weekday(time.Monday), "Montag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lang.Name(tt.value); got != tt.want {
+				t.Errorf("Name() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguage_Date(t *testing.T) {
+	fr := mustLookup(t, "fr")
+	day := ltime.Day(1760911200) // Monday, October 20th 2025
+
+	tests := []struct {
+		lang i18n.Language
+		want string
+	}{
+		{i18n.English, day.ENString()},
+		{i18n.German, day.DEString()},
+		{fr, "lundi 20 octobre 2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang.Code(), func(t *testing.T) {
+			if got := tt.lang.Date(day); got != tt.want {
+				t.Errorf("Date() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguage_Number(t *testing.T) {
+	fr := mustLookup(t, "fr")
+	price := types.LPrice(2.5)
+
+	if got := i18n.English.Number(price); got != "2.50" {
+		t.Errorf("Number() = %q, want %q", got, "2.50")
+	}
+	if got := i18n.German.Number(price); got != price.DEString() {
+		t.Errorf("Number() = %q, want %q", got, price.DEString())
+	}
+	if got := fr.Number(price); got != "2,50" {
+		t.Errorf("Number() = %q, want %q", got, "2,50")
+	}
+}
+
+func TestLanguage_English(t *testing.T) {
+	fr := mustLookup(t, "fr")
+	if i18n.German.English() {
+		t.Error("German.English() = true")
+	}
+	if !i18n.English.English() || !fr.English() {
+		t.Error("English() = false for a non-german language")
+	}
+}
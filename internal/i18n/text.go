@@ -0,0 +1,126 @@
+package i18n
+
+//spellchecker:words html template strconv strings time github faulunch internal ltime
+import (
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+// Sprintf formats the message with the given key in this language.
+func (l Language) Sprintf(key string, args ...any) string {
+	return l.printer(key).Sprintf(key, args...)
+}
+
+// HTML formats the message with the given key in this language.
+// The message itself may contain html, arguments are escaped unless they are of type [template.HTML].
+func (l Language) HTML(key string, args ...any) template.HTML {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case template.HTML:
+			escaped[i] = string(arg)
+		case string:
+			escaped[i] = template.HTMLEscapeString(arg)
+		default:
+			escaped[i] = arg
+		}
+	}
+	return template.HTML(l.Sprintf(key, escaped...))
+}
+
+// Named is implemented by values with built-in german and english names.
+type Named interface {
+	DEString() string
+	ENString() string
+}
+
+// Keyed is implemented by values whose names can be translated using a message in a locale file.
+type Keyed interface {
+	MessageKey() string
+}
+
+// Name returns the name of the given value in this language.
+//
+// If the value implements [Keyed] and the language has a message with its key, that message is used.
+// Otherwise the built-in german name is used for german, and the built-in english name for all other languages.
+func (l Language) Name(value Named) string {
+	if keyed, ok := value.(Keyed); ok {
+		if msg, ok := l.lookup(keyed.MessageKey()); ok {
+			return msg
+		}
+	}
+	if l.German() {
+		return value.DEString()
+	}
+	return value.ENString()
+}
+
+// decimalKey is the key of the message holding the decimal separator.
+const decimalKey = "number.decimal"
+
+// Number formats the given number in this language.
+// The english representation of the number is used, with the decimal separator taken from the catalogue.
+func (l Language) Number(value Named) string {
+	number := value.ENString()
+	if separator, ok := l.lookup(decimalKey); ok {
+		number = strings.Replace(number, ".", separator, 1)
+	}
+	return number
+}
+
+// Weekday is a day of the week that can be translated.
+type Weekday time.Weekday
+
+func (w Weekday) DEString() string {
+	return ltime.WeekdayDE(time.Weekday(w))
+}
+
+func (w Weekday) ENString() string {
+	return ltime.WeekdayEN(time.Weekday(w))
+}
+
+func (w Weekday) MessageKey() string {
+	return "weekday." + strconv.Itoa(int(w))
+}
+
+// Month is a month that can be translated.
+type Month time.Month
+
+func (m Month) DEString() string {
+	return ltime.MonthDE(time.Month(m))
+}
+
+func (m Month) ENString() string {
+	return ltime.MonthEN(time.Month(m))
+}
+
+func (m Month) MessageKey() string {
+	return "month." + strconv.Itoa(int(m))
+}
+
+// dateFormatKey is the key of the message used to format dates.
+// It receives the weekday, day of month, month and year as string arguments.
+const dateFormatKey = "date.format"
+
+// Date formats the given day in this language.
+// Languages without a date format use the built-in german format for german and the built-in english format otherwise.
+func (l Language) Date(d ltime.Day) string {
+	if !l.Has(dateFormatKey) {
+		if l.German() {
+			return d.DEString()
+		}
+		return d.ENString()
+	}
+
+	t := d.Time()
+	return l.Sprintf(dateFormatKey, l.Name(Weekday(t.Weekday())), strconv.Itoa(t.Day()), l.Name(Month(t.Month())), strconv.Itoa(t.Year()))
+}
+
+// DateHTML formats the given day in this language, wrapped in a time element.
+func (l Language) DateHTML(d ltime.Day) template.HTML {
+	return template.HTML("<time datetime='" + d.Time().Format(time.DateOnly) + "'>" + template.HTMLEscapeString(l.Date(d)) + "</time>")
+}
@@ -0,0 +1,38 @@
+//spellchecker:words location
+package location
+
+//spellchecker:words math
+import "math"
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371000
+
+// HasCoordinates checks if coordinates are known for this location.
+func (ld LocationDescription) HasCoordinates() bool {
+	return ld.Latitude != 0 || ld.Longitude != 0
+}
+
+// Distance returns the distance in meters between this location and the given coordinates.
+// It uses the haversine formula, treating the earth as a sphere.
+func (ld LocationDescription) Distance(lat, lon float64) float64 {
+	return Distance(ld.Latitude, ld.Longitude, lat, lon)
+}
+
+// Distance returns the great-circle distance in meters between two coordinates given in degrees.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1, φ2 := radians(lat1), radians(lat2)
+	Δφ := radians(lat2 - lat1)
+	Δλ := radians(lon2 - lon1)
+
+	a := math.Sin(Δφ/2)*math.Sin(Δφ/2) + math.Cos(φ1)*math.Cos(φ2)*math.Sin(Δλ/2)*math.Sin(Δλ/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// ValidCoordinates checks if the given latitude and longitude are within range.
+func ValidCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
@@ -0,0 +1,63 @@
+//spellchecker:words location
+package location_test
+
+//spellchecker:words math testing github faulunch internal location
+import (
+	"math"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/location"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64 // in meters
+		tolerance              float64 // in meters
+	}{
+		{name: "same point", lat1: 49.58, lon1: 11.03, lat2: 49.58, lon2: 11.03, want: 0, tolerance: 0.001},
+		{name: "erlangen to nuremberg", lat1: 49.5897, lon1: 11.0078, lat2: 49.4521, lon2: 11.0767, want: 16168, tolerance: 100},
+		{name: "one degree of latitude", lat1: 0, lon1: 0, lat2: 1, lon2: 0, want: 111195, tolerance: 10},
+		{name: "symmetric", lat1: 49.4521, lon1: 11.0767, lat2: 49.5897, lon2: 11.0078, want: 16168, tolerance: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := location.Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("Distance() = %v, want %v ± %v", got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestValidCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{name: "erlangen", lat: 49.58, lon: 11.03, want: true},
+		{name: "edges", lat: -90, lon: 180, want: true},
+		{name: "latitude too large", lat: 91, lon: 0, want: false},
+		{name: "longitude too small", lat: 0, lon: -181, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := location.ValidCoordinates(tt.lat, tt.lon); got != tt.want {
+				t.Errorf("ValidCoordinates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationDescription_HasCoordinates(t *testing.T) {
+	if (location.LocationDescription{}).HasCoordinates() {
+		t.Error("HasCoordinates() = true for empty description")
+	}
+	if !location.MensaSued.Description().HasCoordinates() {
+		t.Error("HasCoordinates() = false for MensaSued")
+	}
+}
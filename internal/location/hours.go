@@ -0,0 +1,246 @@
+//spellchecker:words location
+package location
+
+//spellchecker:words encoding json strings time github faulunch internal ltime
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+// TimeRange represents a range of time within a single day, such as "11:00" to "14:00".
+type TimeRange struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+const clockFormat = "15:04"
+
+// Validate checks that both ends of the range are valid clock times, and that open is before close.
+func (tr TimeRange) Validate() error {
+	open, err := time.Parse(clockFormat, tr.Open)
+	if err != nil {
+		return fmt.Errorf("invalid opening time %q: %w", tr.Open, err)
+	}
+	close, err := time.Parse(clockFormat, tr.Close)
+	if err != nil {
+		return fmt.Errorf("invalid closing time %q: %w", tr.Close, err)
+	}
+	if !open.Before(close) {
+		return fmt.Errorf("opening time %q is not before closing time %q", tr.Open, tr.Close)
+	}
+	return nil
+}
+
+func (tr TimeRange) String() string {
+	return tr.Open + "–" + tr.Close
+}
+
+// Weekly represents opening hours for each day of the week, indexed by [time.Weekday].
+// A day without any ranges is closed.
+type Weekly [7][]TimeRange
+
+var weekdayKeys = [...]string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// Known checks if any opening hours are set.
+func (w Weekly) Known() bool {
+	for _, ranges := range w {
+		if len(ranges) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Days returns the opening hours starting from monday, as is customary in germany.
+func (w Weekly) Days() []WeekdayHours {
+	days := make([]WeekdayHours, 0, len(w))
+	for i := range w {
+		day := time.Weekday((i + 1) % 7)
+		days = append(days, WeekdayHours{Weekday: day, Ranges: w[day]})
+	}
+	return days
+}
+
+// WeekdayHours represents the opening hours on a single weekday.
+type WeekdayHours struct {
+	Weekday time.Weekday
+	Ranges  []TimeRange
+}
+
+func (wh WeekdayHours) DEString() string {
+	return ltime.WeekdayDE(wh.Weekday)
+}
+
+func (wh WeekdayHours) ENString() string {
+	return ltime.WeekdayEN(wh.Weekday)
+}
+
+func (wh WeekdayHours) MessageKey() string {
+	return i18n.Weekday(wh.Weekday).MessageKey()
+}
+
+// Hours returns a human-readable version of the opening hours.
+func (wh WeekdayHours) Hours() string {
+	parts := make([]string, len(wh.Ranges))
+	for i, r := range wh.Ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ByName returns the hours keyed by lowercase english weekday names.
+// Closed days are omitted.
+func (w Weekly) ByName() map[string][]TimeRange {
+	m := make(map[string][]TimeRange, len(w))
+	for day, ranges := range w {
+		if len(ranges) == 0 {
+			continue
+		}
+		m[weekdayKeys[day]] = ranges
+	}
+	return m
+}
+
+// MarshalJSON marshals weekly hours as an object keyed by lowercase english weekday names, see [Weekly.ByName].
+func (w Weekly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.ByName())
+}
+
+func (w *Weekly) UnmarshalJSON(data []byte) error {
+	var m map[string][]TimeRange
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*w = Weekly{}
+outer:
+	for key, ranges := range m {
+		for day, name := range weekdayKeys {
+			if strings.EqualFold(key, name) {
+				w[day] = ranges
+				continue outer
+			}
+		}
+		return fmt.Errorf("unknown weekday %q", key)
+	}
+	return nil
+}
+
+// Validate checks that all contained ranges are valid.
+func (w Weekly) Validate() error {
+	for day, ranges := range w {
+		for _, r := range ranges {
+			if err := r.Validate(); err != nil {
+				return fmt.Errorf("%s: %w", weekdayKeys[day], err)
+			}
+		}
+	}
+	return nil
+}
+
+// Period represents a range of dates, including both ends.
+// Dates are formatted as "2006-01-02".
+type Period struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+const dateFormat = "2006-01-02"
+
+// Contains checks if the given day is contained in this period.
+func (p Period) Contains(day ltime.Day) bool {
+	date := day.Time().Format(dateFormat)
+	return p.From <= date && date <= p.To
+}
+
+// Validate checks that both ends of the period are valid dates, and that they are in order.
+func (p Period) Validate() error {
+	if _, err := time.Parse(dateFormat, p.From); err != nil {
+		return fmt.Errorf("invalid start date %q: %w", p.From, err)
+	}
+	if _, err := time.Parse(dateFormat, p.To); err != nil {
+		return fmt.Errorf("invalid end date %q: %w", p.To, err)
+	}
+	if p.From > p.To {
+		return fmt.Errorf("start date %q is after end date %q", p.From, p.To)
+	}
+	return nil
+}
+
+// Closure represents a period of time where a location is closed.
+type Closure struct {
+	Period
+	ReasonDE string `json:"reasonDE,omitempty"`
+	ReasonEN string `json:"reasonEN,omitempty"`
+}
+
+// InSemesterBreak checks if the given day is in a lecture-free period of the current registry.
+func InSemesterBreak(day ltime.Day) bool {
+	return Current().InSemesterBreak(day)
+}
+
+// SemesterBreaks returns the lecture-free periods of the current registry.
+func SemesterBreaks() []Period {
+	return Current().SemesterBreaks()
+}
+
+// KnowsHours checks if opening hours are known for this location.
+// Locations without known hours are assumed to be open.
+func (ld LocationDescription) KnowsHours() bool {
+	return ld.Hours.Known()
+}
+
+// ClosureOn returns the closure in effect on the given day, if any.
+func (ld LocationDescription) ClosureOn(day ltime.Day) (Closure, bool) {
+	for _, c := range ld.Closures {
+		if c.Contains(day) {
+			return c, true
+		}
+	}
+	return Closure{}, false
+}
+
+// HoursOn returns the opening hours on the given day.
+// Takes both closures and semester breaks into account.
+func (ld LocationDescription) HoursOn(day ltime.Day) []TimeRange {
+	if _, closed := ld.ClosureOn(day); closed {
+		return nil
+	}
+
+	hours := ld.Hours
+	if ld.BreakHours.Known() && InSemesterBreak(day) {
+		hours = ld.BreakHours
+	}
+	return hours[day.Time().Weekday()]
+}
+
+// OpenOn checks if the location is open on the given day.
+// If no opening hours are known, the location is assumed to be open.
+func (ld LocationDescription) OpenOn(day ltime.Day) bool {
+	if !ld.KnowsHours() {
+		_, closed := ld.ClosureOn(day)
+		return !closed
+	}
+	return len(ld.HoursOn(day)) > 0
+}
+
+// maxNextOpenDays is the maximal number of days NextOpen searches.
+const maxNextOpenDays = 366
+
+// NextOpen returns the first day strictly after day where this location is open.
+// ok is false if no such day exists within the next year.
+func (ld LocationDescription) NextOpen(day ltime.Day) (next ltime.Day, ok bool) {
+	next = day.Normalize()
+	for range maxNextOpenDays {
+		next = next.Add(1)
+		if ld.OpenOn(next) {
+			return next, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,212 @@
+//spellchecker:words location
+package location_test
+
+//spellchecker:words encoding json slices testing time github faulunch internal location ltime
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+// date returns the day for the given date in Berlin time
+func date(year int, month time.Month, day int) ltime.Day {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	return ltime.Day(time.Date(year, month, day, 0, 0, 0, 0, berlin).Unix())
+}
+
+var lunch = []location.TimeRange{{Open: "11:00", Close: "14:00"}}
+
+func TestTimeRange_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tr      location.TimeRange
+		wantErr bool
+	}{
+		{name: "valid", tr: location.TimeRange{Open: "11:00", Close: "14:00"}, wantErr: false},
+		{name: "invalid open", tr: location.TimeRange{Open: "11", Close: "14:00"}, wantErr: true},
+		{name: "invalid close", tr: location.TimeRange{Open: "11:00", Close: "25:00"}, wantErr: true},
+		{name: "reversed", tr: location.TimeRange{Open: "14:00", Close: "11:00"}, wantErr: true},
+		{name: "empty", tr: location.TimeRange{Open: "11:00", Close: "11:00"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tr.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("TimeRange.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWeekly_JSON(t *testing.T) {
+	var w location.Weekly
+	w[time.Monday] = lunch
+	w[time.Friday] = lunch
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `{"friday":[{"open":"11:00","close":"14:00"}],"monday":[{"open":"11:00","close":"14:00"}]}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var got location.Weekly
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(got[time.Monday]) != 1 || len(got[time.Friday]) != 1 || len(got[time.Tuesday]) != 0 {
+		t.Errorf("Unmarshal() = %v, want %v", got, w)
+	}
+
+	if err := json.Unmarshal([]byte(`{"caturday":[]}`), &got); err == nil {
+		t.Error("Unmarshal() of unknown weekday did not fail")
+	}
+}
+
+func TestWeekly_Days(t *testing.T) {
+	days := location.Weekly{}.Days()
+	if len(days) != 7 {
+		t.Fatalf("Days() returned %d days, want 7", len(days))
+	}
+	if days[0].Weekday != time.Monday || days[6].Weekday != time.Sunday {
+		t.Errorf("Days() does not start at monday and end at sunday: %v", days)
+	}
+}
+
+func TestPeriod_Contains(t *testing.T) {
+	p := location.Period{From: "2026-12-24", To: "2027-01-06"}
+
+	tests := []struct {
+		name string
+		day  ltime.Day
+		want bool
+	}{
+		{name: "before", day: date(2026, 12, 23), want: false},
+		{name: "first day", day: date(2026, 12, 24), want: true},
+		{name: "inside", day: date(2026, 12, 31), want: true},
+		{name: "last day", day: date(2027, 1, 6), want: true},
+		{name: "after", day: date(2027, 1, 7), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Contains(tt.day); got != tt.want {
+				t.Errorf("Period.Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationDescription_OpenOn(t *testing.T) {
+	var hours location.Weekly
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours[day] = lunch
+	}
+
+	christmas := location.Closure{Period: location.Period{From: "2026-12-24", To: "2027-01-06"}}
+
+	tests := []struct {
+		name string
+		desc location.LocationDescription
+		day  ltime.Day
+		want bool
+	}{
+		{name: "unknown hours are open", desc: location.LocationDescription{}, day: date(2026, 10, 17), want: true},
+		{name: "unknown hours with closure", desc: location.LocationDescription{Closures: []location.Closure{christmas}}, day: date(2026, 12, 28), want: false},
+		{name: "open on weekday", desc: location.LocationDescription{Hours: hours}, day: date(2026, 10, 19), want: true},
+		{name: "closed on saturday", desc: location.LocationDescription{Hours: hours}, day: date(2026, 10, 17), want: false},
+		{name: "closed during closure", desc: location.LocationDescription{Hours: hours, Closures: []location.Closure{christmas}}, day: date(2026, 12, 28), want: false},
+		{name: "closed during semester break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Saturday: lunch}}, day: date(2026, 8, 3), want: false},
+		{name: "break hours outside of break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Saturday: lunch}}, day: date(2026, 10, 19), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.desc.OpenOn(tt.day); got != tt.want {
+				t.Errorf("LocationDescription.OpenOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationDescription_HoursOn(t *testing.T) {
+	registry, err := location.ParseRegistry([]byte(`{"semesterBreaks":[{"from":"2026-07-25","to":"2026-10-11"}],"locations":[{"id":"test","feedID":1,"name":"Test","cafe":true}]}`))
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+	location.Use(registry)
+	defer location.Use(nil)
+
+	short := []location.TimeRange{{Open: "11:00", Close: "13:00"}}
+
+	var hours location.Weekly
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours[day] = lunch
+	}
+
+	tests := []struct {
+		name string
+		desc location.LocationDescription
+		day  ltime.Day
+		want []location.TimeRange
+	}{
+		{name: "lecture period", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Monday: short}}, day: date(2026, 10, 19), want: lunch},
+		{name: "first day of break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Monday: short}}, day: date(2026, 7, 27), want: short},
+		{name: "last day of break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Friday: short}}, day: date(2026, 10, 9), want: short},
+		{name: "closed weekday during break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Monday: short}}, day: date(2026, 8, 4), want: nil},
+		{name: "regular hours during break without break hours", desc: location.LocationDescription{Hours: hours}, day: date(2026, 8, 3), want: lunch},
+		{name: "closure during break", desc: location.LocationDescription{Hours: hours, BreakHours: location.Weekly{time.Monday: short}, Closures: []location.Closure{{Period: location.Period{From: "2026-08-03", To: "2026-08-03"}}}}, day: date(2026, 8, 3), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.desc.HoursOn(tt.day); !slices.Equal(got, tt.want) {
+				t.Errorf("LocationDescription.HoursOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationDescription_NextOpen(t *testing.T) {
+	var hours location.Weekly
+	hours[time.Monday] = lunch
+
+	desc := location.LocationDescription{
+		Hours:    hours,
+		Closures: []location.Closure{{Period: location.Period{From: "2026-10-26", To: "2026-10-26"}}},
+	}
+
+	next, ok := desc.NextOpen(date(2026, 10, 19))
+	if !ok || next != date(2026, 11, 2) {
+		t.Errorf("NextOpen() = %v, %v, want %v, true", next.Time(), ok, date(2026, 11, 2).Time())
+	}
+
+	never := location.LocationDescription{Hours: hours, Closures: []location.Closure{{Period: location.Period{From: "2000-01-01", To: "2100-01-01"}}}}
+	if _, ok := never.NextOpen(date(2026, 10, 19)); ok {
+		t.Error("NextOpen() found a day for a location that is never open")
+	}
+}
+
+func TestAllLocationHoursValid(t *testing.T) {
+	for _, loc := range location.Locations() {
+		desc := loc.Description()
+		if err := desc.Hours.Validate(); err != nil {
+			t.Errorf("Location %v has invalid Hours: %v", loc, err)
+		}
+		if err := desc.BreakHours.Validate(); err != nil {
+			t.Errorf("Location %v has invalid BreakHours: %v", loc, err)
+		}
+		for _, c := range desc.Closures {
+			if err := c.Validate(); err != nil {
+				t.Errorf("Location %v has invalid Closure: %v", loc, err)
+			}
+		}
+	}
+}
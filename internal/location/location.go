@@ -1,28 +1,57 @@
 //spellchecker:words faulunch
 package location
 
-//spellchecker:words encoding json html template github faulunch internal
+//spellchecker:words encoding json html template
 import (
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/url"
-
-	"github.com/tkw1536/faulunch/internal"
 )
 
 type Location string
 
 func (location *Location) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		LocationDescription `json:",inline"`
-		ID                  string `json:"id"`
-	}{
-		LocationDescription: location.Description(),
-		ID:                  string(*location),
+	desc := location.Description()
+	return json.Marshal(LocationJSON{
+		Name:           desc.Name,
+		Refactory:      desc.Refactory,
+		Cafe:           desc.Cafe,
+		Internal:       desc.Internal,
+		Street:         desc.Street,
+		StreetNo:       desc.StreetNo,
+		ZIP:            desc.ZIP,
+		City:           desc.City,
+		Latitude:       desc.Latitude,
+		Longitude:      desc.Longitude,
+		Hours:          desc.Hours,
+		BreakHours:     desc.BreakHours,
+		Closures:       desc.Closures,
+		SemesterBreaks: SemesterBreaks(),
+		ID:             string(*location),
 	})
 }
 
+// LocationJSON is the json encoding of a location in version 1 of the api.
+// It keeps the capitalized field names the api used before [LocationDescription] had json tags.
+type LocationJSON struct {
+	Name           string    `json:"Name"`
+	Refactory      bool      `json:"Refactory"`
+	Cafe           bool      `json:"Cafe"`
+	Internal       bool      `json:"Internal"`
+	Street         string    `json:"Street"`
+	StreetNo       string    `json:"StreetNo"`
+	ZIP            string    `json:"ZIP"`
+	City           string    `json:"City"`
+	Latitude       float64   `json:"Latitude"`
+	Longitude      float64   `json:"Longitude"`
+	Hours          Weekly    `json:"Hours"`
+	BreakHours     Weekly    `json:"BreakHours"`
+	Closures       []Closure `json:"Closures"`
+	SemesterBreaks []Period  `json:"SemesterBreaks"`
+	ID             string    `json:"id"`
+}
+
 const (
 	CafeteriaBaerenschanzstr Location = "cafeteria-baerenschanzstr"
 	CafeteriaBingstr         Location = "cafeteria-bingstr"
@@ -50,56 +79,34 @@ const (
 	WohnanlageStPeter        Location = "wohnanlage-st-peter"
 )
 
+// Locations returns all known locations, sorted by feed id.
 func Locations() []Location {
-	keys := internal.SortedKeysOf(locationIDs, func(a, b Location) int {
-		return locationIDs[a] - locationIDs[b]
-	})
-	return keys
+	return Current().Locations()
 }
 
+// Valid checks if this is a known location.
 func (Location Location) Valid() bool {
-	_, ok := locationIDs[Location]
-	return ok
-}
-
-var locationIDs = map[Location]int{
-	MensaSued:                1,
-	MensaInselschuett:        2,
-	MensaRegensburgerstr:     3,
-	MensaAnsbach:             4,
-	MensaEichstaett:          5,
-	MensateriaOhm:            6,
-	MensaIngolstadt:          7,
-	MensaLmp:                 8,
-	MensateriaStPaul:         9,
-	CafeteriaComeIn:          10,
-	CafeteriaBaerenschanzstr: 11,
-	MensateriaTriesdorf:      12,
-	CafeteriaBingstr:         13,
-	CafeteriaVeilhofstr:      14,
-	CafeteriaKochstr:         17,
-	WohnanlageErwinRommelStr: 18,
-	WohnanlageHartmannstr:    19,
-	CafeteriaSuedblick:       21,
-	WohnanlageStPeter:        20,
-	CafeteriaWiso:            25,
-	CafeteriaChemikum:        22,
-	CafeteriaNeuburg:         26,
-	CafeteriaUnibibliothek:   27,
-	MensaOic:                 28,
+	return Current().Valid(Location)
 }
 
 type LocationDescription struct {
-	Name string
+	Name string `json:"name"`
 
-	Refactory bool
-	Cafe      bool
-	Internal  bool
+	Refactory bool `json:"refactory,omitempty"`
+	Cafe      bool `json:"cafe,omitempty"`
+	Internal  bool `json:"internal,omitempty"`
 
-	Street   string
-	StreetNo string
-	ZIP      string
-	City     string
+	Street   string `json:"street,omitempty"`
+	StreetNo string `json:"streetNo,omitempty"`
+	ZIP      string `json:"zip,omitempty"`
+	City     string `json:"city,omitempty"`
+
+	Latitude  float64 `json:"latitude,omitempty"`  // approximate latitude of the address, 0 if unknown
+	Longitude float64 `json:"longitude,omitempty"` // approximate longitude of the address, 0 if unknown
+
+	Hours      Weekly    `json:"hours"`              // regular opening hours, unknown if empty
+	BreakHours Weekly    `json:"breakHours"`         // opening hours during semester breaks, same as Hours if empty
+	Closures   []Closure `json:"closures,omitempty"` // ad-hoc closures, such as holidays
 }
 
 func (ld LocationDescription) Type(english bool) string {
@@ -127,6 +134,19 @@ func (ld LocationDescription) Type(english bool) string {
 	return ""
 }
 
+// TypeKey returns the key of the message describing the type of this location.
+func (ld LocationDescription) TypeKey() string {
+	switch {
+	case ld.Refactory:
+		return "location.servery"
+	case ld.Cafe:
+		return "location.cafe"
+	case ld.Internal:
+		return "location.internal"
+	}
+	return "location.other"
+}
+
 func (ld LocationDescription) Address() template.HTML {
 	full := fmt.Sprintf("%s %s, %s %s", ld.Street, ld.StreetNo, ld.ZIP, ld.City)
 	url := "https://www.openstreetmap.org/search?query=" + url.QueryEscape(full)
@@ -179,242 +199,15 @@ func (ld LocationDescription) Cmp(other LocationDescription) int {
 	return 0
 }
 
-var locationDescriptions = map[Location]LocationDescription{
-	MensaSued: {
-		Name: "Südmensa",
-
-		Refactory: true,
-
-		Street:   "Erwin-Rommel-Straße",
-		StreetNo: "60",
-		ZIP:      "91058",
-		City:     "Erlangen",
-	},
-	MensaInselschuett: {
-		Name:      "Insel Schütt",
-		Refactory: true,
-
-		Street:   "Andreij-Sacharow-Platz",
-		StreetNo: "1",
-		ZIP:      "90403",
-		City:     "Nürnberg",
-	},
-	MensaRegensburgerstr: {
-		Name:      "Regensburger Straße",
-		Refactory: true,
-
-		Street:   "Regensburger Str.",
-		StreetNo: "160",
-		ZIP:      "90478",
-		City:     "Nürnberg",
-	},
-	MensaAnsbach: {
-		Name:      "Ansbach",
-		Refactory: true,
-
-		Street:   "Residenzstraße",
-		StreetNo: "8",
-		ZIP:      "91522",
-		City:     "Ansbach",
-	},
-	MensaEichstaett: {
-		Name:      "Eichstätt",
-		Refactory: true,
-
-		Street:   "Universitätsallee",
-		StreetNo: "2",
-		ZIP:      "85072",
-		City:     "Eichstätt",
-	},
-	MensateriaOhm: {
-		Name:      "Mensateria Ohm",
-		Refactory: true,
-
-		Street:   "Wollentorstr.",
-		StreetNo: "4",
-		ZIP:      "90489",
-		City:     "Nürnberg",
-	},
-	MensaIngolstadt: {
-		Name:      "Ingolstadt",
-		Refactory: true,
-
-		Street:   "Esplanade",
-		StreetNo: "10",
-		ZIP:      "85049",
-		City:     "Ingolstadt",
-	},
-	MensaLmp: {
-		Name:      "Mensa Langemarkplatz",
-		Refactory: true,
-
-		Street:   "Langemarckplatz",
-		StreetNo: "4",
-		ZIP:      "91054",
-		City:     "Erlangen",
-	},
-	MensateriaStPaul: {
-		Name:      "Ausgabemensa St. Paul",
-		Refactory: true,
-
-		Street:   "Dutzendteichstraße",
-		StreetNo: "24",
-		ZIP:      "90478",
-		City:     "Nürnberg",
-	},
-	CafeteriaComeIn: {
-		Name: "Cafeteria \"Come IN\" Hohfederstraße",
-		Cafe: true,
-
-		Street:   "Hohfederstraße",
-		StreetNo: "40",
-		ZIP:      "90489",
-		City:     "Nürnberg",
-	},
-	CafeteriaBaerenschanzstr: {
-		Name: "Cafeteria Bärenschanzstraße",
-		Cafe: true,
-
-		Street:   "Bärenschanzstr.",
-		StreetNo: "4",
-		ZIP:      "90429",
-		City:     "Nürnberg",
-	},
-	MensateriaTriesdorf: {
-		Name:      "Triesdorf",
-		Refactory: true,
-
-		Street:   "Markgrafenstraße",
-		StreetNo: "14",
-		ZIP:      "91746",
-		City:     "Weidenbach",
-	},
-	CafeteriaBingstr: {
-		Name: "Cafeteria Bingstraße",
-		Cafe: true,
-
-		Street:   "Bingstr.",
-		StreetNo: "60",
-		ZIP:      "90480",
-		City:     "Nürnberg",
-	},
-	CafeteriaVeilhofstr: {
-		Name: "Cafeteria Veilhofstraße",
-		Cafe: true,
-
-		Street:   "Veilhofstraße",
-		StreetNo: "34-40",
-		ZIP:      "90489",
-		City:     "Nürnberg",
-	},
-	CafeteriaKochstr: {
-		Name: "Cafeteria Kochstraße",
-		Cafe: true,
-
-		Street:   "Kochstr.",
-		StreetNo: "4",
-		ZIP:      "91054",
-		City:     "Erlangen",
-	},
-	WohnanlageErwinRommelStr: {
-		Name:     "Wohnanlage Erwin-Rommel-Straße",
-		Internal: true,
-
-		Street:   "Erwin-Rommel-Straße",
-		StreetNo: "51-59",
-		ZIP:      "91058",
-		City:     "Erlangen",
-	},
-	WohnanlageHartmannstr: {
-		Name:     "Wohnanlage Hartmannstraße",
-		Internal: true,
-
-		Street:   "Hartmannstraße",
-		StreetNo: "125/127/129",
-		ZIP:      "91052",
-		City:     "Erlangen",
-	},
-	CafeteriaSuedblick: {
-		Name: "Cafeteria SÜDBlick",
-		Cafe: true,
-
-		Street:   "Erwin-Rommel-Straße",
-		StreetNo: "51a",
-		ZIP:      "91058",
-		City:     "Erlangen",
-	},
-	WohnanlageStPeter: {
-		Name:     "Wohnanlage St. Peter",
-		Internal: true,
-
-		Street:   "Walter-Meckauer-Straße", // Sophienstraße
-		StreetNo: "12-28",                  // 12-16
-		ZIP:      "90478",
-		City:     "Nürnberg",
-	},
-	CafeteriaWiso: {
-		Name:     "Cafeteria Wiso",
-		Internal: true,
-
-		// not sure which one this is
-	},
-	CafeteriaChemikum: {
-		Name: "Cafeteria Chemikum",
-		Cafe: true,
-
-		Street:   "Nikolaus-Fiebiger Straße",
-		StreetNo: "10",
-		ZIP:      "91058",
-		City:     "Erlangen",
-	},
-	CafeteriaNeuburg: {
-		Name: "Mittagstheke Neuburg an der Donau",
-		Cafe: true,
-
-		Street:   "An der Hochschule",
-		StreetNo: "1",
-		ZIP:      "86633",
-		City:     "Neuburg an der Donau",
-	},
-	CafeteriaUnibibliothek: {
-		Name: "Universitätsbibliothek Erlangen",
-		Cafe: true,
-
-		Street:   "Universitätsstraße",
-		StreetNo: "4",
-		ZIP:      "91054",
-		City:     "Erlangen",
-	},
-	MensaOic: {
-		Name:      "Mensateria OIC Nürnberg",
-		Refactory: true,
-
-		Street:   "Brucknerstraße",
-		StreetNo: "11",
-		ZIP:      "90429",
-		City:     "Nürnberg",
-	},
-}
-
-// LocationOfID returns the id of the given location
+// LocationOfID returns the location with the given feed id
 func LocationOfID(id int) Location {
-	for loc, lid := range locationIDs {
-		if lid == id {
-			return loc
-		}
-	}
-	return ""
+	return Current().LocationOfID(id)
 }
 
 func (l Location) ID() int {
-	for loc, id := range locationIDs {
-		if loc == l {
-			return id
-		}
-	}
-	return 0
+	return Current().ID(l)
 }
 
 func (l Location) Description() LocationDescription {
-	return locationDescriptions[l]
+	return Current().Description(l)
 }
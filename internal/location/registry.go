@@ -0,0 +1,227 @@
+//spellchecker:words location
+package location
+
+//spellchecker:words embed encoding json errors regexp slices sync atomic github faulunch internal ltime
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sync/atomic"
+
+	"github.com/tkw1536/faulunch/internal"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+// Registry holds the set of known locations along with their descriptions.
+// A Registry is immutable once created, and safe for concurrent use.
+type Registry struct {
+	ids            map[Location]int
+	locations      map[int]Location
+	descriptions   map[Location]LocationDescription
+	semesterBreaks []Period
+}
+
+// RegistryFile is the on-disk format of a registry.
+type RegistryFile struct {
+	SemesterBreaks []Period        `json:"semesterBreaks"` // lecture-free periods, where BreakHours apply
+	Locations      []RegistryEntry `json:"locations"`
+}
+
+// RegistryEntry describes a single location inside a RegistryFile.
+type RegistryEntry struct {
+	ID     Location `json:"id"`     // slug used in urls, e.g. "mensa-sued"
+	FeedID int      `json:"feedID"` // numeric id used by the upstream feed
+	LocationDescription
+}
+
+//go:embed locations.json
+var defaultRegistryData []byte
+
+// DefaultRegistry returns the registry built into the binary.
+func DefaultRegistry() *Registry {
+	registry, err := ParseRegistry(defaultRegistryData)
+	if err != nil {
+		panic("location: invalid default registry: " + err.Error())
+	}
+	return registry
+}
+
+// ReadRegistry reads a registry from the json file at path.
+func ReadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRegistry(data)
+}
+
+// ParseRegistry parses and validates a registry encoded as json.
+func ParseRegistry(data []byte) (*Registry, error) {
+	var file RegistryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode registry: %w", err)
+	}
+	return NewRegistry(file)
+}
+
+var (
+	regexpValidSlug = regexp.MustCompile("^[a-zA-Z0-9-_]+$")
+
+	errEmptyRegistry = errors.New("registry does not contain any locations")
+)
+
+// reservedSlugs are slugs that may not be used by locations, as they conflict with routes.
+var reservedSlugs = map[Location]struct{}{
+	"de":     {},
+	"en":     {},
+	"api":    {},
+	"admin":  {},
+	"static": {},
+}
+
+// NewRegistry creates a new registry from the given file, validating all entries.
+func NewRegistry(file RegistryFile) (*Registry, error) {
+	if len(file.Locations) == 0 {
+		return nil, errEmptyRegistry
+	}
+
+	registry := &Registry{
+		ids:            make(map[Location]int, len(file.Locations)),
+		locations:      make(map[int]Location, len(file.Locations)),
+		descriptions:   make(map[Location]LocationDescription, len(file.Locations)),
+		semesterBreaks: file.SemesterBreaks,
+	}
+
+	for _, p := range file.SemesterBreaks {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid semester break: %w", err)
+		}
+	}
+
+	for _, entry := range file.Locations {
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("location %q: %w", entry.ID, err)
+		}
+		if _, ok := registry.ids[entry.ID]; ok {
+			return nil, fmt.Errorf("location %q: duplicate id", entry.ID)
+		}
+		if other, ok := registry.locations[entry.FeedID]; ok {
+			return nil, fmt.Errorf("location %q: feed id %d already used by %q", entry.ID, entry.FeedID, other)
+		}
+
+		registry.ids[entry.ID] = entry.FeedID
+		registry.locations[entry.FeedID] = entry.ID
+		registry.descriptions[entry.ID] = entry.LocationDescription
+	}
+
+	return registry, nil
+}
+
+func (entry RegistryEntry) validate() error {
+	if !regexpValidSlug.MatchString(string(entry.ID)) {
+		return errors.New("invalid id")
+	}
+	if _, ok := reservedSlugs[entry.ID]; ok {
+		return errors.New("reserved id")
+	}
+	if entry.FeedID <= 0 {
+		return fmt.Errorf("invalid feed id %d", entry.FeedID)
+	}
+	if entry.Name == "" {
+		return errors.New("missing name")
+	}
+
+	kinds := 0
+	for _, kind := range []bool{entry.Refactory, entry.Cafe, entry.Internal} {
+		if kind {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("must be exactly one of refactory, cafe or internal")
+	}
+
+	if !ValidCoordinates(entry.Latitude, entry.Longitude) {
+		return errors.New("invalid coordinates")
+	}
+	if err := entry.Hours.Validate(); err != nil {
+		return fmt.Errorf("invalid hours: %w", err)
+	}
+	if err := entry.BreakHours.Validate(); err != nil {
+		return fmt.Errorf("invalid break hours: %w", err)
+	}
+	for _, c := range entry.Closures {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("invalid closure: %w", err)
+		}
+	}
+	return nil
+}
+
+// Locations returns all locations in this registry, sorted by feed id.
+func (registry *Registry) Locations() []Location {
+	return internal.SortedKeysOf(registry.ids, func(a, b Location) int {
+		return registry.ids[a] - registry.ids[b]
+	})
+}
+
+// Valid checks if the given location is contained in this registry.
+func (registry *Registry) Valid(l Location) bool {
+	_, ok := registry.ids[l]
+	return ok
+}
+
+// ID returns the feed id of the given location, or 0 if it is unknown.
+func (registry *Registry) ID(l Location) int {
+	return registry.ids[l]
+}
+
+// LocationOfID returns the location with the given feed id, or "" if it is unknown.
+func (registry *Registry) LocationOfID(id int) Location {
+	return registry.locations[id]
+}
+
+// Description returns the description of the given location.
+func (registry *Registry) Description(l Location) LocationDescription {
+	return registry.descriptions[l]
+}
+
+// InSemesterBreak checks if the given day is in a lecture-free period.
+func (registry *Registry) InSemesterBreak(day ltime.Day) bool {
+	for _, p := range registry.semesterBreaks {
+		if p.Contains(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// SemesterBreaks returns the lecture-free periods of this registry.
+func (registry *Registry) SemesterBreaks() []Period {
+	return slices.Clone(registry.semesterBreaks)
+}
+
+// current holds the registry used by the package-level functions.
+var current atomic.Pointer[Registry]
+
+func init() {
+	current.Store(DefaultRegistry())
+}
+
+// Current returns the registry currently in use.
+func Current() *Registry {
+	return current.Load()
+}
+
+// Use replaces the registry used by the package-level functions.
+// It is safe to call concurrently with any other function of this package.
+func Use(registry *Registry) {
+	if registry == nil {
+		registry = DefaultRegistry()
+	}
+	current.Store(registry)
+}
@@ -0,0 +1,64 @@
+//spellchecker:words location
+package location_test
+
+//spellchecker:words testing github faulunch internal location
+import (
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/location"
+)
+
+func TestParseRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true}]}`, wantErr: false},
+		{name: "invalid json", data: `{"locations":`, wantErr: true},
+		{name: "no locations", data: `{"locations":[]}`, wantErr: true},
+		{name: "invalid id", data: `{"locations":[{"id":"a/b","feedID":1,"name":"Test","refactory":true}]}`, wantErr: true},
+		{name: "reserved id", data: `{"locations":[{"id":"en","feedID":1,"name":"Test","refactory":true}]}`, wantErr: true},
+		{name: "missing feed id", data: `{"locations":[{"id":"test","name":"Test","refactory":true}]}`, wantErr: true},
+		{name: "missing name", data: `{"locations":[{"id":"test","feedID":1,"refactory":true}]}`, wantErr: true},
+		{name: "no kind", data: `{"locations":[{"id":"test","feedID":1,"name":"Test"}]}`, wantErr: true},
+		{name: "two kinds", data: `{"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true,"cafe":true}]}`, wantErr: true},
+		{name: "invalid hours", data: `{"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true,"hours":{"monday":[{"open":"14:00","close":"11:00"}]}}]}`, wantErr: true},
+		{name: "invalid semester break", data: `{"semesterBreaks":[{"from":"2026-10-11","to":"2026-07-25"}],"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true}]}`, wantErr: true},
+		{name: "duplicate id", data: `{"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true},{"id":"test","feedID":2,"name":"Test","refactory":true}]}`, wantErr: true},
+		{name: "duplicate feed id", data: `{"locations":[{"id":"test","feedID":1,"name":"Test","refactory":true},{"id":"other","feedID":1,"name":"Test","refactory":true}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := location.ParseRegistry([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	registry, err := location.ParseRegistry([]byte(`{"locations":[{"id":"test","feedID":42,"name":"Test","cafe":true}]}`))
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+
+	location.Use(registry)
+	defer location.Use(nil)
+
+	if !location.Location("test").Valid() {
+		t.Error("Location from new registry is not valid")
+	}
+	if location.MensaSued.Valid() {
+		t.Error("Location from default registry is still valid")
+	}
+	if got := location.LocationOfID(42); got != "test" {
+		t.Errorf("LocationOfID(42) = %v, want %v", got, "test")
+	}
+
+	location.Use(nil)
+	if !location.MensaSued.Valid() {
+		t.Error("Use(nil) did not restore the default registry")
+	}
+}
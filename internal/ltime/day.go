@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"html/template"
+	"math"
 	"strconv"
 	"time"
 
@@ -102,10 +103,16 @@ func (d Day) Normalize() Day {
 // Add adds count number of days to the current day.
 // The result is normalized.
 func (d Day) Add(count int) Day {
-	t := d.Time().Add(time.Duration(count) * 24 * time.Hour)
+	t := d.Time().AddDate(0, 0, count)
 	return normalizeDay(t)
 }
 
+// DaysUntil returns the number of days from d to other, negative if other is before d.
+// Days with a daylight saving time change are counted as full days.
+func (d Day) DaysUntil(other Day) int {
+	return int(math.Round(other.Time().Sub(d.Time()).Hours() / 24))
+}
+
 // Time returns the time behind this day in the appropriate local timezone.
 func (d Day) Time() time.Time {
 	// TODO: Do we need this?
@@ -134,11 +141,22 @@ func (d Day) Value() (driver.Value, error) {
 var daysDE = [...]string{
 	"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag",
 }
+
+// WeekdayDE returns the german name of the given weekday.
+func WeekdayDE(day time.Weekday) string {
+	return daysDE[day]
+}
+
 var monthsDE = [...]string{
 	"Januar", "Februar", "März", "April", "Mai", "Juni",
 	"Juli", "August", "September", "Oktober", "November", "Dezember",
 }
 
+// MonthDE returns the german name of the given month.
+func MonthDE(month time.Month) string {
+	return monthsDE[month-1]
+}
+
 // String formats this Day as a number of seconds since the unix epoch.
 func (d Day) String() string {
 	return strconv.FormatInt(int64(d), 10)
@@ -154,6 +172,20 @@ func (d Day) DEString() string {
 
 const dateStamp = "2006-01-02"
 
+// Date formats this day as an ISO 8601 date, such as "2006-01-02".
+func (d Day) Date() string {
+	return d.Time().Format(dateStamp)
+}
+
+// ParseDate parses a day from an ISO 8601 date, such as "2006-01-02".
+func ParseDate(value string) (Day, error) {
+	t, err := time.ParseInLocation(dateStamp, value, europeBerlin)
+	if err != nil {
+		return 0, err
+	}
+	return normalizeDay(t), nil
+}
+
 func (d Day) DEHTML() template.HTML {
 	return template.HTML("<time datetime='" + d.Time().Format(dateStamp) + "'>" + d.DEString() + "</time>")
 }
@@ -161,11 +193,22 @@ func (d Day) DEHTML() template.HTML {
 var daysEN = [...]string{
 	"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
 }
+
+// WeekdayEN returns the english name of the given weekday.
+func WeekdayEN(day time.Weekday) string {
+	return daysEN[day]
+}
+
 var monthsEN = [...]string{
 	"January", "February", "March", "April", "May", "June",
 	"July", "August", "September", "October", "November", "December",
 }
 
+// MonthEN returns the english name of the given month.
+func MonthEN(month time.Month) string {
+	return monthsEN[month-1]
+}
+
 // ENString formats this day as an english localized string.
 func (d Day) ENString() string {
 	t := d.Time()
@@ -97,6 +97,44 @@ func TestDay_Add(t *testing.T) {
 	}
 }
 
+func TestDay_Add_dst(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	// 2021-10-31 has 25 hours in Berlin time
+	d := ltime.Day(time.Date(2021, 10, 31, 0, 0, 0, 0, berlin).Unix())
+	want := time.Date(2021, 11, 1, 0, 0, 0, 0, berlin)
+
+	if got := d.Add(1).Time(); !got.Equal(want) {
+		t.Errorf("Add(1) = %v, want %v", got, want)
+	}
+}
+
+func TestDay_DaysUntil(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	day := func(month time.Month, d int) ltime.Day {
+		return ltime.Day(time.Date(2023, month, d, 0, 0, 0, 0, berlin).Unix())
+	}
+
+	tests := []struct {
+		name     string
+		from, to ltime.Day
+		want     int
+	}{
+		{"same day", day(5, 10), day(5, 10), 0},
+		{"forward", day(5, 10), day(5, 17), 7},
+		{"backward", day(5, 17), day(5, 10), -7},
+		{"over dst start", day(3, 25), day(3, 27), 2},
+		{"over dst end", day(10, 28), day(10, 30), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.DaysUntil(tt.to); got != tt.want {
+				t.Errorf("DaysUntil() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDay_Equal(t *testing.T) {
 	d1 := ltime.Day(1609459200)
 	d2 := ltime.Day(1609459200)
@@ -151,6 +189,56 @@ func TestDay_String(t *testing.T) {
 	}
 }
 
+func TestDay_Date(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name string
+		day  ltime.Day
+		want string
+	}{
+		{name: "midnight", day: ltime.Day(time.Date(2023, 5, 10, 0, 0, 0, 0, berlin).Unix()), want: "2023-05-10"},
+		{name: "late evening", day: ltime.Day(time.Date(2023, 12, 31, 23, 30, 0, 0, berlin).Unix()), want: "2023-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.day.Date(); got != tt.want {
+				t.Errorf("Date() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    ltime.Day
+		wantErr bool
+	}{
+		{name: "winter", value: "2023-01-15", want: ltime.Day(time.Date(2023, 1, 15, 0, 0, 0, 0, berlin).Unix())},
+		{name: "summer", value: "2023-07-15", want: ltime.Day(time.Date(2023, 7, 15, 0, 0, 0, 0, berlin).Unix())},
+		{name: "timestamp", value: "1609459200", wantErr: true},
+		{name: "invalid date", value: "2023-02-30", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ltime.ParseDate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDay_LocalizedString(t *testing.T) {
 	berlin, _ := time.LoadLocation("Europe/Berlin")
 
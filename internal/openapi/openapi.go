@@ -0,0 +1,116 @@
+// Package openapi implements generating OpenAPI 3.0 documents from go types and validating responses against them.
+//
+//spellchecker:words openapi
+package openapi
+
+// Version is the version of the OpenAPI specification implemented by this package.
+const Version = "3.0.0"
+
+// Document is an OpenAPI document.
+// Only the parts of the specification needed by faulunch are implemented.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the api.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a server the api is available at.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag is a tag used to group operations.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations available on a single path, by lowercase http method.
+type PathItem map[string]*Operation
+
+// Operation describes a single api operation.
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	Responses   map[string]*Response `json:"responses"` // by status code
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a parameter of an operation.
+type Parameter struct {
+	In          string  `json:"in"` // "path" or "query"
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+	Example     any     `json:"example,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"` // by content type
+}
+
+// MediaType describes the body of a response of a specific content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the reusable parts of a document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema describes a json value.
+// The zero schema allows any value.
+type Schema struct {
+	Ref string `json:"$ref,omitempty"` // reference to a component, see [Ref]
+
+	Type        string   `json:"type,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Nullable    bool     `json:"nullable,omitempty"`
+	Enum        []any    `json:"enum,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"`
+	Maximum     *float64 `json:"maximum,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	Example     any      `json:"example,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+
+	// arrays
+	Items *Schema `json:"items,omitempty"`
+
+	// objects
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// componentPrefix is the prefix of references to schema components.
+const componentPrefix = "#/components/schemas/"
+
+// Ref returns a schema referencing the schema component with the given name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: componentPrefix + name}
+}
+
+// Number returns a pointer to the given number, for use as [Schema.Minimum] or [Schema.Maximum].
+func Number(value float64) *float64 {
+	return &value
+}
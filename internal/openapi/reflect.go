@@ -0,0 +1,276 @@
+package openapi
+
+//spellchecker:words encoding json errors reflect slices strings
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Type documents a go type for a [Reflector].
+type Type struct {
+	// Name is the name of the schema component of the type.
+	// It defaults to the name of the go type for named structs, other types without a name are inlined.
+	Name string
+
+	Description string
+	Example     any
+
+	// Fields documents the fields of a struct type, by json name.
+	Fields map[string]Field
+
+	// Schema, if not nil, is used instead of reflecting the type.
+	// As, if not nil, is reflected instead of the type.
+	// One of them must be set for types with a custom json encoding.
+	Schema *Schema
+	As     reflect.Type
+}
+
+// Field documents a single field of a struct type.
+type Field struct {
+	Description string
+	Example     any
+
+	// Schema, if not nil, is used instead of the reflected schema of the field.
+	Schema *Schema
+}
+
+// Reflector generates schemas from go types, following the rules of [encoding/json].
+//
+// Structs are reflected as objects, with fields not marked omitempty being required.
+// Slices, maps and pointers are nullable.
+// Types with a custom json encoding must be documented in Types.
+type Reflector struct {
+	Types map[reflect.Type]Type
+
+	components map[string]*Schema
+	names      map[reflect.Type]string // names of components generated from go types
+}
+
+// AddComponent adds a schema component that is not generated from a go type.
+func (r *Reflector) AddComponent(name string, schema *Schema) error {
+	if _, ok := r.components[name]; ok {
+		return fmt.Errorf("duplicate component %q", name)
+	}
+	if r.components == nil {
+		r.components = make(map[string]*Schema)
+	}
+	r.components[name] = schema
+	return nil
+}
+
+// Components returns all components added or generated so far.
+func (r *Reflector) Components() map[string]*Schema {
+	return maps.Clone(r.components)
+}
+
+// Schema returns the schema of the given type.
+// Components required by the type are generated, see [Reflector.Components].
+func (r *Reflector) Schema(t reflect.Type) (*Schema, error) {
+	doc := r.Types[t]
+
+	name := doc.Name
+	if name == "" && t.Kind() == reflect.Struct && doc.Schema == nil && doc.As == nil {
+		name = t.Name()
+	}
+	if name == "" {
+		return r.build(t, doc)
+	}
+
+	if existing, ok := r.names[t]; ok {
+		return Ref(existing), nil
+	}
+	if _, ok := r.components[name]; ok {
+		return nil, fmt.Errorf("%s: duplicate component %q", t, name)
+	}
+
+	// reserve the name before building, to allow recursive types
+	if r.names == nil {
+		r.names = make(map[reflect.Type]string)
+	}
+	r.names[t] = name
+	if err := r.AddComponent(name, nil); err != nil {
+		return nil, err
+	}
+
+	schema, err := r.build(t, doc)
+	if err != nil {
+		return nil, err
+	}
+	r.components[name] = schema
+	return Ref(name), nil
+}
+
+var (
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+var errCustomEncoding = errors.New("type has a custom json encoding, but no documented schema")
+
+// build builds the schema of the given type, without creating a component for it.
+func (r *Reflector) build(t reflect.Type, doc Type) (schema *Schema, err error) {
+	switch {
+	case doc.Schema != nil:
+		clone := *doc.Schema
+		schema = &clone
+	case doc.As != nil:
+		schema, err = r.build(doc.As, Type{Fields: doc.Fields})
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) || t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return nil, fmt.Errorf("%s: %w", t, errCustomEncoding)
+	default:
+		schema, err = r.reflect(t, doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Description != "" {
+		schema.Description = doc.Description
+	}
+	if doc.Example != nil {
+		schema.Example = doc.Example
+	}
+	return schema, nil
+}
+
+// reflect reflects the schema of the given type.
+func (r *Reflector) reflect(t reflect.Type, doc Type) (*Schema, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: Number(0)}, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		elem, err := r.Schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(elem), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}, nil
+		}
+		items, err := r.Schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items, Nullable: t.Kind() == reflect.Slice}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s: map keys must be strings", t)
+		}
+		elem, err := r.Schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: elem, Nullable: true}, nil
+	case reflect.Struct:
+		return r.reflectStruct(t, doc)
+	}
+	return nil, fmt.Errorf("%s: unsupported kind %s", t, t.Kind())
+}
+
+// reflectStruct reflects the schema of the given struct type.
+func (r *Reflector) reflectStruct(t reflect.Type, doc Type) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := r.addFields(schema, t); err != nil {
+		return nil, err
+	}
+
+	for name, field := range doc.Fields {
+		property, ok := schema.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: documented field %q does not exist", t, name)
+		}
+		if field.Schema != nil {
+			clone := *field.Schema
+			property = &clone
+		}
+		if property.Ref != "" {
+			// siblings of references are ignored, so wrap it
+			property = &Schema{AllOf: []*Schema{property}}
+		}
+		if field.Description != "" {
+			property.Description = field.Description
+		}
+		if field.Example != nil {
+			property.Example = field.Example
+		}
+		schema.Properties[name] = property
+	}
+
+	slices.Sort(schema.Required)
+	return schema, nil
+}
+
+// addFields adds the json fields of the given struct type to schema.
+func (r *Reflector) addFields(schema *Schema, t reflect.Type) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		optional := slices.Contains(strings.Split(options, ","), "omitempty") || slices.Contains(strings.Split(options, ","), "omitzero")
+
+		// embedded structs without a name are inlined
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := r.addFields(schema, ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := schema.Properties[name]; ok {
+			return fmt.Errorf("%s: duplicate field %q", t, name)
+		}
+
+		property, err := r.Schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		schema.Properties[name] = property
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// nullable returns a nullable version of schema.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	schema.Nullable = true
+	return schema
+}
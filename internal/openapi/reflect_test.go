@@ -0,0 +1,150 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/openapi"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testStruct struct {
+	testEmbedded
+	Name     string            `json:"name"`
+	Optional int               `json:"optional,omitempty"`
+	Hidden   string            `json:"-"`
+	Items    []testItem        `json:"items"`
+	Next     *testStruct       `json:"next"`
+	Labels   map[string]string `json:"labels"`
+	Plain    bool
+
+	unexported string
+}
+
+type testItem struct {
+	Value float64 `json:"value"`
+}
+
+type testMarshaler struct{}
+
+func (testMarshaler) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+func TestReflector_Schema(t *testing.T) {
+	reflector := &openapi.Reflector{
+		Types: map[reflect.Type]openapi.Type{
+			reflect.TypeFor[testStruct](): {
+				Name:        "Struct",
+				Description: "A test struct",
+				Fields: map[string]openapi.Field{
+					"name": {Description: "The name", Example: "example"},
+					"next": {Description: "The next struct"},
+				},
+			},
+		},
+	}
+
+	got, err := reflector.Schema(reflect.TypeFor[[]testStruct]())
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	assertJSON(t, "Schema()", got, `{"type":"array","nullable":true,"items":{"$ref":"#/components/schemas/Struct"}}`)
+
+	components := reflector.Components()
+	assertJSON(t, "Components()[Struct]", components["Struct"], `{
+		"type": "object",
+		"description": "A test struct",
+		"required": ["Plain", "embedded", "items", "labels", "name", "next"],
+		"properties": {
+			"embedded": {"type": "string"},
+			"name": {"type": "string", "description": "The name", "example": "example"},
+			"optional": {"type": "integer"},
+			"items": {"type": "array", "nullable": true, "items": {"$ref": "#/components/schemas/testItem"}},
+			"next": {"description": "The next struct", "nullable": true, "allOf": [{"$ref": "#/components/schemas/Struct"}]},
+			"labels": {"type": "object", "nullable": true, "additionalProperties": {"type": "string"}},
+			"Plain": {"type": "boolean"}
+		}
+	}`)
+	assertJSON(t, "Components()[testItem]", components["testItem"], `{
+		"type": "object",
+		"required": ["value"],
+		"properties": {"value": {"type": "number", "format": "double"}}
+	}`)
+}
+
+func TestReflector_Schema_documented(t *testing.T) {
+	reflector := &openapi.Reflector{
+		Types: map[reflect.Type]openapi.Type{
+			reflect.TypeFor[testMarshaler](): {Schema: &openapi.Schema{Type: "string", Enum: []any{"custom"}}},
+			reflect.TypeFor[testItem]():      {As: reflect.TypeFor[map[string]float64](), Description: "An item"},
+		},
+	}
+
+	marshaler, err := reflector.Schema(reflect.TypeFor[testMarshaler]())
+	if err != nil {
+		t.Fatalf("Schema(testMarshaler) error = %v", err)
+	}
+	assertJSON(t, "Schema(testMarshaler)", marshaler, `{"type":"string","enum":["custom"]}`)
+
+	item, err := reflector.Schema(reflect.TypeFor[testItem]())
+	if err != nil {
+		t.Fatalf("Schema(testItem) error = %v", err)
+	}
+	assertJSON(t, "Schema(testItem)", item, `{"type":"object","description":"An item","nullable":true,"additionalProperties":{"type":"number","format":"double"}}`)
+}
+
+func TestReflector_Schema_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		types map[reflect.Type]openapi.Type
+		t     reflect.Type
+	}{
+		{"undocumented custom encoding", nil, reflect.TypeFor[[]testMarshaler]()},
+		{"unknown field", map[reflect.Type]openapi.Type{reflect.TypeFor[testItem](): {Fields: map[string]openapi.Field{"missing": {}}}}, reflect.TypeFor[testItem]()},
+		{"non-string map key", nil, reflect.TypeFor[map[int]string]()},
+		{"unsupported kind", nil, reflect.TypeFor[chan int]()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reflector := &openapi.Reflector{Types: tt.types}
+			if _, err := reflector.Schema(tt.t); err == nil {
+				t.Error("Schema() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestReflector_AddComponent(t *testing.T) {
+	reflector := &openapi.Reflector{}
+	if err := reflector.AddComponent("testItem", &openapi.Schema{Type: "string"}); err != nil {
+		t.Fatalf("AddComponent() error = %v", err)
+	}
+	if err := reflector.AddComponent("testItem", &openapi.Schema{Type: "string"}); err == nil {
+		t.Error("AddComponent() of duplicate component error = nil, want error")
+	}
+	if _, err := reflector.Schema(reflect.TypeFor[testItem]()); err == nil {
+		t.Error("Schema() of type clashing with component error = nil, want error")
+	}
+}
+
+// assertJSON checks that the json encoding of got is equivalent to want.
+func assertJSON(t *testing.T, name string, got any, want string) {
+	t.Helper()
+
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("%s: failed to encode: %v", name, err)
+	}
+
+	var gotValue, wantValue any
+	if err := errors.Join(json.Unmarshal(gotJSON, &gotValue), json.Unmarshal([]byte(want), &wantValue)); err != nil {
+		t.Fatalf("%s: failed to decode: %v", name, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("%s = %s, want %s", name, gotJSON, want)
+	}
+}
@@ -0,0 +1,248 @@
+package openapi
+
+//spellchecker:words bytes encoding json errors mime http slices strconv strings github faulunch internal
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tkw1536/faulunch/internal"
+)
+
+// ValidateResponse checks that a response to the given operation matches the document.
+//
+// The status code and content type must be documented.
+// Json bodies are validated against the documented schema, other bodies are not checked.
+// JSON Lines bodies (see [JSONLines]) are validated line by line, their schema describes a single line.
+//
+// Validation is stricter than required by the specification:
+// Objects must not contain properties that are not documented, unless the schema explicitly allows additional properties.
+// This ensures that the document does not silently drift from the actual responses.
+func (doc *Document) ValidateResponse(op *Operation, status int, header http.Header, body []byte) error {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("undocumented status %d", status)
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) != 0 {
+			return fmt.Errorf("status %d: undocumented body", status)
+		}
+		return nil
+	}
+
+	contentType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("status %d: invalid content type: %w", status, err)
+	}
+	media, ok := response.Content[contentType]
+	if !ok {
+		return fmt.Errorf("status %d: undocumented content type %q", status, contentType)
+	}
+	if media.Schema == nil {
+		return nil
+	}
+
+	if contentType == JSONLines {
+		if len(body) == 0 {
+			return nil
+		}
+		for i, line := range bytes.Split(bytes.TrimSuffix(body, []byte("\n")), []byte("\n")) {
+			if err := doc.validateJSON(media.Schema, line); err != nil {
+				return fmt.Errorf("status %d: line %d: %w", status, i+1, err)
+			}
+		}
+		return nil
+	}
+
+	if contentType != "application/json" && !strings.HasSuffix(contentType, "+json") {
+		return nil
+	}
+	if err := doc.validateJSON(media.Schema, body); err != nil {
+		return fmt.Errorf("status %d: %w", status, err)
+	}
+	return nil
+}
+
+// JSONLines is the content type of a JSON Lines body, holding one json value per line.
+const JSONLines = "application/jsonl"
+
+// validateJSON checks that data holds a single json value matching the given schema.
+func (doc *Document) validateJSON(schema *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid json body: %w", err)
+	}
+	if decoder.More() {
+		return errors.New("invalid json body: trailing data")
+	}
+	return doc.Validate(schema, value)
+}
+
+// Validate checks that a json value decoded using [json.Decoder.UseNumber] matches the given schema.
+// All problems are returned, see [Document.ValidateResponse] for details.
+func (doc *Document) Validate(schema *Schema, value any) error {
+	var errs []error
+	doc.validate(schema, value, "", func(path, format string, args ...any) {
+		if path == "" {
+			path = "/"
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	})
+	return errors.Join(errs...)
+}
+
+// validate validates value against schema, calling report for every problem.
+// path is the json pointer to the value.
+func (doc *Document) validate(schema *Schema, value any, path string, report func(path, format string, args ...any)) {
+	if schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, componentPrefix)
+		target := doc.Components.Schemas[name]
+		if !ok || target == nil {
+			report(path, "unknown reference %q", schema.Ref)
+			return
+		}
+		doc.validate(target, value, path, report)
+		return
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, sub := range schema.OneOf {
+			valid := true
+			doc.validate(sub, value, path, func(string, string, ...any) { valid = false })
+			if valid {
+				matches++
+			}
+		}
+		if matches != 1 {
+			report(path, "must match exactly one of %d schemas, matches %d", len(schema.OneOf), matches)
+		}
+	}
+
+	if value == nil {
+		if !schema.Nullable && (schema.Type != "" || len(schema.AllOf) > 0) {
+			report(path, "must not be null")
+		}
+		return
+	}
+
+	for _, sub := range schema.AllOf {
+		doc.validate(sub, value, path, report)
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		report(path, "%v is not one of %v", value, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		// anything goes
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report(path, "must be a boolean, got %T", value)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			report(path, "must be a string, got %T", value)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			report(path, "must be of type %s, got %T", schema.Type, value)
+			return
+		}
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				report(path, "must be an integer, got %s", number)
+				return
+			}
+		}
+		f, err := number.Float64()
+		if err != nil {
+			report(path, "invalid number %s", number)
+			return
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			report(path, "must be at least %v, got %s", *schema.Minimum, number)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			report(path, "must be at most %v, got %s", *schema.Maximum, number)
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			report(path, "must be an array, got %T", value)
+			return
+		}
+		if schema.Items == nil {
+			return
+		}
+		for i, elem := range array {
+			doc.validate(schema.Items, elem, path+"/"+strconv.Itoa(i), report)
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			report(path, "must be an object, got %T", value)
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				report(path, "missing required property %q", name)
+			}
+		}
+		for name, elem := range object {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property == nil {
+				if len(schema.Properties) > 0 {
+					report(path, "undocumented property %q", name)
+				}
+				continue
+			}
+			doc.validate(property, elem, path+"/"+escapePointer(name), report)
+		}
+	default:
+		report(path, "unknown schema type %q", schema.Type)
+	}
+}
+
+// escapePointer escapes a property name for use in a json pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// Middleware returns a handler that validates all responses of next against op.
+// Problems are passed to report, the response itself is not changed.
+// Responses to HEAD requests are not validated, as they have no body.
+// It is intended to be used in tests.
+func (doc *Document) Middleware(op *Operation, next http.Handler, report func(r *http.Request, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &internal.ResponseRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		if err := doc.ValidateResponse(op, recorder.StatusCode(), w.Header(), recorder.Body.Bytes()); err != nil {
+			report(r, fmt.Errorf("%s %s: %w", r.Method, r.URL, err))
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/openapi"
+)
+
+var testDocument = &openapi.Document{
+	Components: openapi.Components{
+		Schemas: map[string]*openapi.Schema{
+			"Item": {
+				Type:     "object",
+				Required: []string{"id"},
+				Properties: map[string]*openapi.Schema{
+					"id":    {Type: "integer", Minimum: openapi.Number(1)},
+					"kind":  {Type: "string", Enum: []any{"a", "b"}},
+					"tags":  {Type: "array", Nullable: true, Items: &openapi.Schema{Type: "string"}},
+					"price": {Type: "number", Maximum: openapi.Number(10)},
+				},
+			},
+		},
+	},
+}
+
+var testOperation = &openapi.Operation{
+	Responses: map[string]*openapi.Response{
+		"200": {Content: map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "array", Items: openapi.Ref("Item")}}}},
+		"201": {Content: map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{OneOf: []*openapi.Schema{{Type: "array", Items: &openapi.Schema{Type: "string"}}, openapi.Ref("Item")}}}}},
+		"204": {},
+		"404": {Content: map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+		"206": {Content: map[string]openapi.MediaType{openapi.JSONLines: {Schema: openapi.Ref("Item")}}},
+	},
+}
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"valid", http.StatusOK, "application/json", `[{"id":1,"kind":"a","tags":null,"price":9.5}]`, false},
+		{"valid charset", http.StatusOK, "application/json; charset=utf-8", `[{"id":1}]`, false},
+		{"valid first alternative", http.StatusCreated, "application/json", `["a"]`, false},
+		{"valid second alternative", http.StatusCreated, "application/json", `{"id":1}`, false},
+		{"valid empty", http.StatusNoContent, "", ``, false},
+		{"valid non-json", http.StatusNotFound, "text/plain", `not found`, false},
+		{"valid json lines", http.StatusPartialContent, "application/jsonl", "{\"id\":1}\n{\"id\":2}\n", false},
+		{"valid empty json lines", http.StatusPartialContent, "application/jsonl", "", false},
+
+		{"undocumented status", http.StatusInternalServerError, "application/json", `{}`, true},
+		{"undocumented body", http.StatusNoContent, "application/json", `{}`, true},
+		{"undocumented content type", http.StatusOK, "text/plain", `[]`, true},
+		{"invalid json", http.StatusOK, "application/json", `[`, true},
+		{"null array", http.StatusOK, "application/json", `null`, true},
+		{"missing property", http.StatusOK, "application/json", `[{"kind":"a"}]`, true},
+		{"undocumented property", http.StatusOK, "application/json", `[{"id":1,"extra":true}]`, true},
+		{"not an integer", http.StatusOK, "application/json", `[{"id":1.5}]`, true},
+		{"below minimum", http.StatusOK, "application/json", `[{"id":0}]`, true},
+		{"above maximum", http.StatusOK, "application/json", `[{"id":1,"price":11}]`, true},
+		{"not in enum", http.StatusOK, "application/json", `[{"id":1,"kind":"c"}]`, true},
+		{"wrong item type", http.StatusOK, "application/json", `[{"id":1,"tags":[1]}]`, true},
+		{"no alternative", http.StatusCreated, "application/json", `[1]`, true},
+		{"null alternative", http.StatusCreated, "application/json", `null`, true},
+		{"invalid json line", http.StatusPartialContent, "application/jsonl", "{\"id\":1}\n{\"id\":0}\n", true},
+		{"several values on a json line", http.StatusPartialContent, "application/jsonl", "{\"id\":1} {\"id\":2}\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			err := testDocument.ValidateResponse(testOperation, tt.status, header, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocument_Middleware(t *testing.T) {
+	// handler responds with the body given in the query
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(r.URL.Query().Get("body")))
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		wantErr bool
+	}{
+		{"valid", http.MethodGet, `[{"id":1}]`, false},
+		{"invalid", http.MethodGet, `[{"id":"a"}]`, true},
+		{"head is not validated", http.MethodHead, `[{"id":"a"}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			middleware := testDocument.Middleware(testOperation, handler, func(r *http.Request, err error) { errs = append(errs, err) })
+
+			rec := httptest.NewRecorder()
+			middleware.ServeHTTP(rec, httptest.NewRequest(tt.method, "/?"+url.Values{"body": {tt.body}}.Encode(), nil))
+
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("reported errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("response body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+//spellchecker:words ordering
+package ordering
+
+//spellchecker:words embed encoding json strings sync atomic github faulunch internal location
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tkw1536/faulunch/internal/location"
+)
+
+// Rule determines the order of menu categories at a location.
+//
+// Categories listed in Ranks come first, in the given order.
+// They are followed by categories starting with one of the Prefixes, again in the given order.
+// All remaining categories come last.
+// Categories with the same rank are sorted alphabetically.
+type Rule struct {
+	Ranks    []string `json:"ranks,omitempty"`    // exact category names
+	Prefixes []string `json:"prefixes,omitempty"` // category name prefixes
+}
+
+// rank returns the rank of the given category under this rule.
+func (rule Rule) rank(category string) int {
+	for i, name := range rule.Ranks {
+		if category == name {
+			return i
+		}
+	}
+	for i, prefix := range rule.Prefixes {
+		if strings.HasPrefix(category, prefix) {
+			return len(rule.Ranks) + i
+		}
+	}
+	return len(rule.Ranks) + len(rule.Prefixes)
+}
+
+// Cmp compares two categories under this rule.
+func (rule Rule) Cmp(a, b string) int {
+	if ra, rb := rule.rank(a), rule.rank(b); ra != rb {
+		return ra - rb
+	}
+	return strings.Compare(a, b)
+}
+
+var errEmptyEntry = errors.New("empty entry")
+
+func (rule Rule) validate() error {
+	for _, name := range rule.Ranks {
+		if name == "" {
+			return fmt.Errorf("ranks: %w", errEmptyEntry)
+		}
+	}
+	for _, prefix := range rule.Prefixes {
+		if prefix == "" {
+			return fmt.Errorf("prefixes: %w", errEmptyEntry)
+		}
+	}
+	return nil
+}
+
+// Ordering holds the category ordering rules of all locations.
+type Ordering struct {
+	Default   Rule                       `json:"default"`   // rule for locations without a rule of their own
+	Locations map[location.Location]Rule `json:"locations"` // rules of specific locations
+}
+
+// Rule returns the rule used for the given location.
+func (ordering Ordering) Rule(loc location.Location) Rule {
+	if rule, ok := ordering.Locations[loc]; ok {
+		return rule
+	}
+	return ordering.Default
+}
+
+//go:embed ordering.json
+var defaultOrderingData []byte
+
+// DefaultOrdering returns the ordering built into the binary.
+func DefaultOrdering() Ordering {
+	ordering, err := ParseOrdering(defaultOrderingData)
+	if err != nil {
+		panic("ordering: invalid default ordering: " + err.Error())
+	}
+	return ordering
+}
+
+// ReadOrdering reads an ordering from the json file at path.
+func ReadOrdering(path string) (Ordering, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Ordering{}, err
+	}
+	return ParseOrdering(data)
+}
+
+// ParseOrdering parses and validates an ordering encoded as json.
+func ParseOrdering(data []byte) (Ordering, error) {
+	var ordering Ordering
+	if err := json.Unmarshal(data, &ordering); err != nil {
+		return Ordering{}, fmt.Errorf("failed to decode ordering: %w", err)
+	}
+
+	if err := ordering.Default.validate(); err != nil {
+		return Ordering{}, fmt.Errorf("default: %w", err)
+	}
+	for loc, rule := range ordering.Locations {
+		if err := rule.validate(); err != nil {
+			return Ordering{}, fmt.Errorf("location %q: %w", loc, err)
+		}
+	}
+	return ordering, nil
+}
+
+// current holds the ordering used by [Cmp].
+var current atomic.Pointer[Ordering]
+
+func init() {
+	Use(nil)
+}
+
+// Use replaces the ordering used by [Cmp].
+// If ordering is nil, the default ordering is used.
+// It is safe to call concurrently with any other function of this package.
+func Use(ordering *Ordering) {
+	if ordering == nil {
+		def := DefaultOrdering()
+		ordering = &def
+	}
+	current.Store(ordering)
+}
+
+// Cmp compares two categories at the given location using the current ordering.
+func Cmp(loc location.Location, a, b string) int {
+	return current.Load().Rule(loc).Cmp(a, b)
+}
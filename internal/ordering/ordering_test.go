@@ -0,0 +1,92 @@
+//spellchecker:words ordering
+package ordering_test
+
+//spellchecker:words slices testing github faulunch internal location ordering
+import (
+	"slices"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ordering"
+)
+
+func TestRule_Cmp(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       ordering.Rule
+		categories []string
+		want       []string
+	}{
+		{
+			name:       "alphabetical",
+			rule:       ordering.Rule{},
+			categories: []string{"Suppe", "Pizza", "Essen 2", "Essen 1"},
+			want:       []string{"Essen 1", "Essen 2", "Pizza", "Suppe"},
+		},
+		{
+			name:       "prefixes",
+			rule:       ordering.Rule{Prefixes: []string{"Suppe", "Essen "}},
+			categories: []string{"Essen 2", "Pizza", "Suppe", "Essen 1"},
+			want:       []string{"Suppe", "Essen 1", "Essen 2", "Pizza"},
+		},
+		{
+			name:       "ranks before prefixes",
+			rule:       ordering.Rule{Ranks: []string{"Pizza", "Tagesangebot"}, Prefixes: []string{"Essen "}},
+			categories: []string{"Aktion", "Essen 1", "Tagesangebot", "Pizza"},
+			want:       []string{"Pizza", "Tagesangebot", "Essen 1", "Aktion"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Clone(tt.categories)
+			slices.SortStableFunc(got, tt.rule.Cmp)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sorted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOrdering(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"default":{"prefixes":["Essen "]},"locations":{"mensa-sued":{"ranks":["Pizza"]}}}`, wantErr: false},
+		{name: "invalid json", data: `{"default":`, wantErr: true},
+		{name: "empty default prefix", data: `{"default":{"prefixes":[""]}}`, wantErr: true},
+		{name: "empty location rank", data: `{"locations":{"mensa-sued":{"ranks":[""]}}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ordering.ParseOrdering([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseOrdering() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	o, err := ordering.ParseOrdering([]byte(`{"locations":{"cafeteria-come-in":{"ranks":["Pizza"]}}}`))
+	if err != nil {
+		t.Fatalf("ParseOrdering() error = %v", err)
+	}
+
+	ordering.Use(&o)
+	defer ordering.Use(nil)
+
+	if got := ordering.Cmp(location.CafeteriaComeIn, "Pizza", "Aktion"); got >= 0 {
+		t.Errorf("Cmp() at location with rule = %d, want < 0", got)
+	}
+	if got := ordering.Cmp(location.MensaSued, "Pizza", "Aktion"); got <= 0 {
+		t.Errorf("Cmp() at location without rule = %d, want > 0", got)
+	}
+
+	ordering.Use(nil)
+	if got := ordering.Cmp(location.MensaSued, "Essen 1", "Aktion"); got >= 0 {
+		t.Errorf("Cmp() with default ordering = %d, want < 0", got)
+	}
+}
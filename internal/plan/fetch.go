@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/tkw1536/faulunch/internal/location"
@@ -24,20 +25,36 @@ type ClientLike interface {
 
 // Fetch fetches a plan for the given location and language.
 func Fetch(ctx context.Context, client ClientLike, loc location.Location, english bool) (plan Plan, err error) {
+	data, err := FetchRaw(ctx, client, loc, english)
+	if err != nil {
+		return plan, err
+	}
+
+	err = xml.Unmarshal(data, &plan)
+	return
+}
+
+// FetchRaw fetches the unparsed xml of a plan for the given location and language.
+func FetchRaw(ctx context.Context, client ClientLike, loc location.Location, english bool) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, PlanURL(loc, english), nil)
 	if err != nil {
-		return plan, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	res, err := client.Do(req)
 	if err != nil {
-		return plan, fmt.Errorf("failed to fetch plan: %w", err)
+		return nil, fmt.Errorf("failed to fetch plan: %w", err)
 	}
+	defer res.Body.Close()
+
 	if res.StatusCode != http.StatusOK {
-		return plan, fmt.Errorf("failed to fetch plan: %w", errInvalidStatusCode)
+		return nil, fmt.Errorf("failed to fetch plan: %w", errInvalidStatusCode)
 	}
 
-	err = xml.NewDecoder(res.Body).Decode(&plan)
-	return
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	return data, nil
 }
 
 // PlanURL returns the url of a given plan and language
@@ -0,0 +1,162 @@
+package plan
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+// ItemPair represents a german item along with its english counterpart.
+// Either item may be nil if no counterpart could be found.
+type ItemPair struct {
+	German  *Item
+	English *Item
+}
+
+// Paired checks if both items of this pair are set.
+func (pair ItemPair) Paired() bool {
+	return pair.German != nil && pair.English != nil
+}
+
+// Pair pairs the german and english items of a single day.
+//
+// Items are only paired within the same category.
+// If a category contains the same number of items in both languages, items are paired by position.
+// Otherwise items are paired greedily by the similarity of their language-independent fields,
+// such as prices, nutritional values and annotations.
+// Items without a counterpart are returned as unpaired.
+//
+// Pairs are returned in the order of the german items, english-only items are placed after the german items of their category.
+func Pair(german, english []Item) []ItemPair {
+	// group items by category, preserving the order of first appearance
+	var categories []string
+	germanByCat := make(map[string][]int)
+	englishByCat := make(map[string][]int)
+	for i, item := range german {
+		if _, ok := germanByCat[item.Category]; !ok {
+			categories = append(categories, item.Category)
+		}
+		germanByCat[item.Category] = append(germanByCat[item.Category], i)
+	}
+	for i, item := range english {
+		if _, ok := germanByCat[item.Category]; !ok {
+			if _, ok := englishByCat[item.Category]; !ok {
+				categories = append(categories, item.Category)
+			}
+		}
+		englishByCat[item.Category] = append(englishByCat[item.Category], i)
+	}
+
+	pairs := make([]ItemPair, 0, max(len(german), len(english)))
+	for _, cat := range categories {
+		gIdx, eIdx := germanByCat[cat], englishByCat[cat]
+		match := pairCategory(german, english, gIdx, eIdx)
+
+		used := make([]bool, len(eIdx))
+		for i, gi := range gIdx {
+			pair := ItemPair{German: &german[gi]}
+			if j := match[i]; j >= 0 {
+				pair.English = &english[eIdx[j]]
+				used[j] = true
+			}
+			pairs = append(pairs, pair)
+		}
+		for j, ei := range eIdx {
+			if !used[j] {
+				pairs = append(pairs, ItemPair{English: &english[ei]})
+			}
+		}
+	}
+	return pairs
+}
+
+// pairCategory pairs the german items with indexes gIdx to the english items with indexes eIdx.
+// It returns, for each german item, the position within eIdx of its counterpart or -1.
+func pairCategory(german, english []Item, gIdx, eIdx []int) []int {
+	match := make([]int, len(gIdx))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// same number of items => pair by position
+	if len(gIdx) == len(eIdx) {
+		for i := range match {
+			match[i] = i
+		}
+		return match
+	}
+
+	// find all the candidate pairs
+	type candidate struct{ i, j, score int }
+	var candidates []candidate
+	for i, gi := range gIdx {
+		for j, ei := range eIdx {
+			if score := MatchScore(german[gi], english[ei]); score > 0 {
+				candidates = append(candidates, candidate{i: i, j: j, score: score})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.i, b.i), cmp.Compare(a.j, b.j))
+	})
+
+	// and greedily pick the best ones
+	used := make([]bool, len(eIdx))
+	for _, c := range candidates {
+		if match[c.i] >= 0 || used[c.j] {
+			continue
+		}
+		match[c.i] = c.j
+		used[c.j] = true
+	}
+	return match
+}
+
+// MatchScore returns a score indicating how likely the given german and english items describe the same dish.
+// A score of 0 indicates no similarity.
+func MatchScore(german, english Item) (score int) {
+	for _, values := range [][2]types.SmartFloat64{
+		{german.Preis1, english.Preis1},
+		{german.Preis2, english.Preis2},
+		{german.Preis3, english.Preis3},
+		{german.Kj, english.Kj},
+		{german.Kcal, english.Kcal},
+		{german.Fett, english.Fett},
+		{german.Gesfett, english.Gesfett},
+		{german.Kh, english.Kh},
+		{german.Zucker, english.Zucker},
+		{german.Ballaststoffe, english.Ballaststoffe},
+		{german.Eiweiss, english.Eiweiss},
+		{german.Salz, english.Salz},
+	} {
+		if values[0] != 0 && values[0] == values[1] {
+			score++
+		}
+	}
+
+	for _, values := range [][2]string{
+		{german.Piktogramme, english.Piktogramme},
+		{german.Foto, english.Foto},
+		{annotationMarkers(german.Title), annotationMarkers(english.Title)},
+	} {
+		if values[0] != "" && values[0] == values[1] {
+			score++
+		}
+	}
+	return score
+}
+
+var annotationMarkerRegexp = regexp.MustCompile(`\(([^()]*)\)`)
+
+// annotationMarkers returns the annotations in parentheses within s.
+// These are language-independent, such as "(Wz,Mi,S)".
+func annotationMarkers(s string) string {
+	var markers []string
+	for _, match := range annotationMarkerRegexp.FindAllStringSubmatch(s, -1) {
+		markers = append(markers, strings.ReplaceAll(match[1], " ", ""))
+	}
+	return strings.Join(markers, "|")
+}
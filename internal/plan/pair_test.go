@@ -0,0 +1,121 @@
+//spellchecker:words faulunch
+package plan_test
+
+//spellchecker:words reflect testing github faulunch internal plan
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tkw1536/faulunch/internal/plan"
+)
+
+// titles returns the german and english titles of the given pairs, using "" for missing items.
+func titles(pairs []plan.ItemPair) (got [][2]string) {
+	for _, pair := range pairs {
+		var title [2]string
+		if pair.German != nil {
+			title[0] = pair.German.Title
+		}
+		if pair.English != nil {
+			title[1] = pair.English.Title
+		}
+		got = append(got, title)
+	}
+	return got
+}
+
+func TestPair(t *testing.T) {
+	tests := []struct {
+		name    string
+		german  []plan.Item
+		english []plan.Item
+		want    [][2]string
+	}{
+		{
+			name:    "empty",
+			german:  nil,
+			english: nil,
+			want:    nil,
+		},
+		{
+			name: "one item per category",
+			german: []plan.Item{
+				{Category: "Essen 1", Title: "Schnitzel"},
+				{Category: "Suppe", Title: "Tomatensuppe"},
+			},
+			english: []plan.Item{
+				{Category: "Suppe", Title: "Tomato soup"},
+				{Category: "Essen 1", Title: "Schnitzel"},
+			},
+			want: [][2]string{{"Schnitzel", "Schnitzel"}, {"Tomatensuppe", "Tomato soup"}},
+		},
+		{
+			name: "multiple items per category are paired by position",
+			german: []plan.Item{
+				{Category: "Suppe", Title: "Tomatensuppe"},
+				{Category: "Suppe", Title: "Linsensuppe"},
+			},
+			english: []plan.Item{
+				{Category: "Suppe", Title: "Tomato soup"},
+				{Category: "Suppe", Title: "Lentil soup"},
+			},
+			want: [][2]string{{"Tomatensuppe", "Tomato soup"}, {"Linsensuppe", "Lentil soup"}},
+		},
+		{
+			name: "different number of items are paired by similarity",
+			german: []plan.Item{
+				{Category: "SB-Theke", Title: "Pommes (veg)", Preis1: 1.5},
+				{Category: "SB-Theke", Title: "Salat (veg)", Preis1: 2},
+				{Category: "SB-Theke", Title: "Nudeln (Wz)", Preis1: 3},
+			},
+			english: []plan.Item{
+				{Category: "SB-Theke", Title: "Pasta (Wz)", Preis1: 3},
+				{Category: "SB-Theke", Title: "Fries (veg)", Preis1: 1.5},
+			},
+			want: [][2]string{{"Pommes (veg)", "Fries (veg)"}, {"Salat (veg)", ""}, {"Nudeln (Wz)", "Pasta (Wz)"}},
+		},
+		{
+			name: "unmatched items are kept",
+			german: []plan.Item{
+				{Category: "Essen 1", Title: "Schnitzel", Preis1: 3},
+			},
+			english: []plan.Item{
+				{Category: "Essen 1", Title: "Fish", Preis1: 4},
+				{Category: "Essen 1", Title: "Pasta", Preis1: 5},
+				{Category: "Dessert", Title: "Pudding"},
+			},
+			want: [][2]string{{"Schnitzel", ""}, {"", "Fish"}, {"", "Pasta"}, {"", "Pudding"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(plan.Pair(tt.german, tt.english)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Pair() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		german  plan.Item
+		english plan.Item
+		want    int
+	}{
+		{name: "empty", german: plan.Item{}, english: plan.Item{}, want: 0},
+		{name: "different", german: plan.Item{Preis1: 1, Kcal: 200}, english: plan.Item{Preis1: 2, Kcal: 300}, want: 0},
+		{name: "same prices", german: plan.Item{Preis1: 1, Preis2: 2, Preis3: 3}, english: plan.Item{Preis1: 1, Preis2: 2, Preis3: 3}, want: 3},
+		{name: "same annotations", german: plan.Item{Title: "Schnitzel (Wz, Ei)"}, english: plan.Item{Title: "Schnitzel (Wz,Ei)"}, want: 1},
+		{name: "same pictograms", german: plan.Item{Piktogramme: "<img src='S.png'>"}, english: plan.Item{Piktogramme: "<img src='S.png'>"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plan.MatchScore(tt.german, tt.english); got != tt.want {
+				t.Errorf("MatchScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
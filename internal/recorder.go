@@ -0,0 +1,63 @@
+//spellchecker:words internal
+package internal
+
+//spellchecker:words bytes http
+import (
+	"bytes"
+	"net/http"
+)
+
+// ResponseRecorder is an [http.ResponseWriter] recording the status and body of a response.
+//
+// The response is passed through to ResponseWriter.
+// If ResponseWriter is nil, the response is only recorded, and headers are written to Headers instead.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	Headers http.Header
+
+	Status int // status code of the response, 0 if nothing was written yet
+	Body   bytes.Buffer
+}
+
+func (rec *ResponseRecorder) Header() http.Header {
+	if rec.ResponseWriter != nil {
+		return rec.ResponseWriter.Header()
+	}
+	if rec.Headers == nil {
+		rec.Headers = make(http.Header)
+	}
+	return rec.Headers
+}
+
+func (rec *ResponseRecorder) WriteHeader(status int) {
+	if rec.Status == 0 {
+		rec.Status = status
+	}
+	if rec.ResponseWriter != nil {
+		rec.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (rec *ResponseRecorder) Write(data []byte) (int, error) {
+	if rec.Status == 0 {
+		rec.Status = http.StatusOK
+	}
+	rec.Body.Write(data)
+	if rec.ResponseWriter == nil {
+		return len(data), nil
+	}
+	return rec.ResponseWriter.Write(data)
+}
+
+func (rec *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// StatusCode returns the status code of the recorded response.
+// If nothing was written, the status is assumed to be [http.StatusOK].
+func (rec *ResponseRecorder) StatusCode() int {
+	if rec.Status == 0 {
+		return http.StatusOK
+	}
+	return rec.Status
+}
@@ -1,13 +1,16 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words errors time gorm
+//spellchecker:words encoding json errors time github zerolog faulunch internal ltime gorm
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/internal/ltime"
 	"gorm.io/gorm"
 )
 
@@ -18,7 +21,55 @@ type SyncEvent struct {
 	Start int64 `json:"start"`
 	Stop  int64 `json:"stop"`
 
-	Data []byte `json:"-"` // for future usage
+	Data   []byte     `json:"-"`               // json-encoded Report
+	Report SyncReport `gorm:"-" json:"report"` // report of the synchronization, stored in Data
+}
+
+// SyncReport holds information about a synchronization event.
+type SyncReport struct {
+	Warnings []SyncWarning `json:"warnings"`
+}
+
+// SyncWarningKind describes the kind of problem a SyncWarning reports.
+type SyncWarningKind string
+
+const (
+	WarningUnknownLocation  SyncWarningKind = "unknown-location"  // the plans refer to a location not in the registry
+	WarningLocationMismatch SyncWarningKind = "location-mismatch" // the german and english plans refer to different locations
+	WarningMissingDay       SyncWarningKind = "missing-day"       // a day is only present in one language
+	WarningDuplicateDay     SyncWarningKind = "duplicate-day"     // a day is present more than once in one language, only the first one is stored
+	WarningUnpairedItem     SyncWarningKind = "unpaired-item"     // an item has no counterpart in the other language
+)
+
+// SyncWarning represents a problem with the upstream data found during synchronization.
+// Items affected by WarningUnpairedItem are stored with only one language, other affected data is not stored.
+type SyncWarning struct {
+	Kind SyncWarningKind `json:"kind"`
+
+	Location  string    `json:"location,omitempty"`  // location slug, if known
+	FeedID    int       `json:"feedID,omitempty"`    // location id of the german plan
+	FeedIDEN  int       `json:"feedIDEN,omitempty"`  // location id of the english plan, if different
+	Day       ltime.Day `json:"day,omitempty"`       // affected day, if any
+	Category  string    `json:"category,omitempty"`  // category of the affected item, if any
+	Title     string    `json:"title,omitempty"`     // title of the affected item, if any
+	MissingEN bool      `json:"missingEN,omitempty"` // true if the english day or item is missing, false if the german one is
+
+	Message string `json:"message"`
+}
+
+// Event logs this warning to the given logger.
+func (sw SyncWarning) Event(logger *zerolog.Logger) {
+	event := logger.Warn().Str("kind", string(sw.Kind)).Str("location", sw.Location).Int("feedID", sw.FeedID)
+	if sw.FeedIDEN != 0 {
+		event = event.Int("feedIDEN", sw.FeedIDEN)
+	}
+	if sw.Day != 0 {
+		event = event.Stringer("day", sw.Day).Bool("missingEN", sw.MissingEN)
+	}
+	if sw.Title != "" {
+		event = event.Str("category", sw.Category).Str("title", sw.Title)
+	}
+	event.Msg(sw.Message)
 }
 
 func (se *SyncEvent) Begin() {
@@ -31,7 +82,13 @@ func (se *SyncEvent) Finish() {
 
 // Store stores this SyncEvent in the database
 func (se *SyncEvent) Store(ctx context.Context, db *gorm.DB) error {
-	err := gorm.G[SyncEvent](db).Create(ctx, se)
+	data, err := json.Marshal(se.Report)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync report: %w", err)
+	}
+	se.Data = data
+
+	err = gorm.G[SyncEvent](db).Create(ctx, se)
 	if err != nil {
 		return fmt.Errorf("failed to store sync event: %w", err)
 	}
@@ -41,5 +98,16 @@ func (se *SyncEvent) Store(ctx context.Context, db *gorm.DB) error {
 var ErrNoSync = errors.New("database was never synced")
 
 func (api *API) LastSync(ctx context.Context) (se SyncEvent, err error) {
-	return gorm.G[SyncEvent](api.DB).Order("Stop DESC").First(ctx)
+	se, err = gorm.G[SyncEvent](api.DB).Order("Stop DESC").First(ctx)
+	if err != nil {
+		return se, err
+	}
+
+	// events stored before reports were introduced do not have any data
+	if len(se.Data) > 0 {
+		if err := json.Unmarshal(se.Data, &se.Report); err != nil {
+			return se, fmt.Errorf("failed to decode sync report: %w", err)
+		}
+	}
+	return se, nil
 }
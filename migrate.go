@@ -0,0 +1,10 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words gorm
+import "gorm.io/gorm"
+
+// Migrate automatically migrates all tables used by faulunch.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&MenuItem{}, &SyncEvent{}, &MenuOverride{}, &UnknownLocation{}, &UnknownAnnotation{}, &CategoryTranslation{})
+}
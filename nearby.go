@@ -0,0 +1,108 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words slices github faulunch internal location ltime types
+import (
+	"cmp"
+	"slices"
+
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+// NearbyLocation represents a location along with its distance to a given point.
+type NearbyLocation struct {
+	Location location.Location `json:"location"`
+	Distance float64           `json:"distance"` // distance in meters
+
+	Day   ltime.Day     `json:"day"`   // the day the menu is for
+	Items []MenuSummary `json:"items"` // summary of the menu on day, may be empty
+}
+
+// MenuSummary is a short summary of a single MenuItem.
+type MenuSummary struct {
+	Category   string
+	CategoryEN string
+
+	TitleDE string
+	TitleEN string
+
+	Preis1 types.LPrice
+	Preis2 types.LPrice
+	Preis3 types.LPrice
+
+	DietaryCategory DietaryCategory
+	Edited          bool
+}
+
+// Summary returns a summary of this menu item.
+func (m MenuItem) Summary() MenuSummary {
+	return MenuSummary{
+		Category:   m.Category,
+		CategoryEN: m.CategoryEN,
+
+		TitleDE: m.TitleDE,
+		TitleEN: m.TitleEN,
+
+		Preis1: m.Preis1,
+		Preis2: m.Preis2,
+		Preis3: m.Preis3,
+
+		DietaryCategory: m.DietaryCategory,
+		Edited:          m.Edited,
+	}
+}
+
+// Nearby returns the locations with known coordinates, sorted by distance to the given coordinates.
+// Each location includes a summary of the menu on the given day.
+// If limit is positive, at most limit locations are returned.
+func (api *API) Nearby(lat, lon float64, day ltime.Day, limit int) (nearby []NearbyLocation, err error) {
+	nearby, err = api.nearby(lat, lon, day, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range nearby {
+		items, err := api.MenuItems(nearby[i].Location, day)
+		if err != nil {
+			return nil, err
+		}
+
+		nearby[i].Items = make([]MenuSummary, len(items))
+		for j, item := range items {
+			nearby[i].Items[j] = item.Summary()
+		}
+	}
+
+	return nearby, nil
+}
+
+// nearby is like [API.Nearby], but does not include the menus.
+func (api *API) nearby(lat, lon float64, day ltime.Day, limit int) (nearby []NearbyLocation, err error) {
+	locations, err := api.Locations()
+	if err != nil {
+		return nil, err
+	}
+
+	nearby = make([]NearbyLocation, 0, len(locations))
+	for _, loc := range locations {
+		desc := loc.Description()
+		if !desc.HasCoordinates() {
+			continue
+		}
+		nearby = append(nearby, NearbyLocation{
+			Location: loc,
+			Distance: desc.Distance(lat, lon),
+			Day:      day,
+		})
+	}
+
+	slices.SortStableFunc(nearby, func(a, b NearbyLocation) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	if limit > 0 && len(nearby) > limit {
+		nearby = nearby[:limit]
+	}
+	return nearby, nil
+}
@@ -1,38 +1,67 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words encoding json http strconv github swaggest swgui embed
+//spellchecker:words encoding json errors iter mime http slices strconv strings github zerolog swaggest swgui
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
+	"iter"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
+	"github.com/rs/zerolog"
 	"github.com/swaggest/swgui/v5emb"
+	"github.com/tkw1536/faulunch/internal/annotations"
 	"github.com/tkw1536/faulunch/internal/location"
 	"github.com/tkw1536/faulunch/internal/ltime"
-
-	_ "embed"
+	"github.com/tkw1536/faulunch/internal/openapi"
 )
 
-//go:embed openapi.json
-var openAPIJSON []byte
-
 // registerAPIRoutes registers API routes to the server mux
 func (server *Server) registerAPIRoutes() {
-	// api + documentation
-	server.mux.Handle("GET /api/", v5emb.NewHandler("FauLunch API", "/api/openapi.json", "/api/"))
-	server.mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+	for _, version := range apiVersions {
+		server.registerAPIVersion(version)
+	}
+
+	server.mux.HandleFunc("GET /api/graphql", server.handleGraphQL)
+	server.mux.HandleFunc("POST /api/graphql", server.handleGraphQL)
+}
+
+// registerAPIVersion registers the routes of the given api version, along with its documentation.
+func (server *Server) registerAPIVersion(version *apiVersion) {
+	doc, err := version.document()
+	if err != nil {
+		panic("api " + version.Base + ": " + err.Error())
+	}
+	docJSON, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("api " + version.Base + ": " + err.Error())
+	}
+	docJSON = append(docJSON, '\n')
+
+	// documentation
+	server.mux.Handle("GET "+version.Explorer, v5emb.NewHandler(version.Info.Title, version.Document, version.Explorer))
+	server.mux.HandleFunc("GET "+version.Document, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.Write(openAPIJSON)
+		w.Write(docJSON)
 	})
 
-	// api endpoints
-	server.mux.HandleFunc("GET /api/v1/healthcheck", server.handleAPIHealth)
-	server.mux.HandleFunc("GET /api/v1/sync", server.handleAPISync)
-	server.mux.HandleFunc("GET /api/v1/locations", server.handleAPILocations)
-	server.mux.HandleFunc("GET /api/v1/menu/{location}", server.handleAPIMenuDays)
-	server.mux.HandleFunc("GET /api/v1/menu/{location}/{day}", server.handleAPIMenu)
-	server.mux.HandleFunc("GET /api/v1/sqlite", server.handleAPIsqlite)
+	// endpoints
+	for _, route := range version.Routes {
+		var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route.Handler(server, w, r)
+		})
+		if server.ValidateAPI != nil {
+			op := doc.Paths[route.Path][strings.ToLower(route.Method)]
+			handler = doc.Middleware(op, handler, server.ValidateAPI)
+		}
+		server.mux.Handle(route.Method+" "+version.Base+route.Path, handler)
+	}
 }
 
 const notFoundError = `{"status":"Not Found"}`
@@ -102,32 +131,275 @@ func (server *Server) handleAPILocations(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(results)
 }
 
+func (server *Server) handleAPINearby(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "API.Nearby").Logger()
+
+	query := r.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+	if errLat != nil || errLon != nil || !location.ValidCoordinates(lat, lon) {
+		server.handleBadRequest(w)
+		return
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 0 {
+		limit = 0 // no limit
+	}
+
+	results, err := server.API.Nearby(lat, lon, ltime.Today(), limit)
+	logger.Trace().Err(err).Msg("API.Nearby")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+const (
+	// apiMaxDays is the maximal number of days in the range of a days query.
+	apiMaxDays = 365
+
+	// apiDefaultDays is the default number of days in the range of a days query, and the default page size.
+	apiDefaultDays = 28
+)
+
+// apiRangeParameters are the parameters of a days query that select a page of days instead of the plain list of days of the deprecated /menu/{location} route.
+var apiRangeParameters = []string{"to", "order", "limit", "after", "include"}
+
+// daysQuery are the parameters of a query for a range of days, see [parseDaysQuery].
+type daysQuery struct {
+	From, To   ltime.Day // range of days, including both ends
+	Descending bool      // return the newest day first
+
+	Limit int       // maximal number of days, 0 if not given
+	After ltime.Day // return only days after this day (in the order of the query), 0 to start at the beginning
+
+	Items bool // include items of each day
+}
+
+var errInvalidDaysQuery = errors.New("invalid days query")
+
+// parseDaysQuery parses the parameters of a query for a range of days.
+// Days are given as unix timestamps.
+func parseDaysQuery(query url.Values) (q daysQuery, err error) {
+	q.From = ltime.Today().Add(-21)
+	if value := query.Get("from"); value != "" {
+		if q.From = ltime.ParseDay(value); q.From == 0 {
+			return q, errInvalidDaysQuery
+		}
+	}
+
+	q.To = q.From.Add(apiDefaultDays - 1)
+	if value := query.Get("to"); value != "" {
+		if q.To = ltime.ParseDay(value); q.To == 0 {
+			return q, errInvalidDaysQuery
+		}
+	} else if value := query.Get("days"); value != "" {
+		// deprecated: the number of days to check, clamped to the range 1 ... apiMaxDays
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return q, errInvalidDaysQuery
+		}
+		q.To = q.From.Add(min(max(count, 1), apiMaxDays) - 1)
+	}
+	if count := q.From.DaysUntil(q.To) + 1; count < 1 || count > apiMaxDays {
+		return q, errInvalidDaysQuery
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+		q.Descending = true
+	case "asc":
+		q.Descending = false
+	default:
+		return q, errInvalidDaysQuery
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if q.Limit, err = strconv.Atoi(value); err != nil || q.Limit < 1 || q.Limit > apiMaxDays {
+			return q, errInvalidDaysQuery
+		}
+	}
+
+	if value := query.Get("after"); value != "" {
+		if q.After = ltime.ParseDay(value); q.After == 0 {
+			return q, errInvalidDaysQuery
+		}
+	}
+
+	switch query.Get("include") {
+	case "":
+	case "items":
+		q.Items = true
+	default:
+		return q, errInvalidDaysQuery
+	}
+
+	return q, nil
+}
+
+// remaining returns the part of the range of the query after its cursor.
+// If the range is empty, from is after to.
+func (q daysQuery) remaining() (from, to ltime.Day) {
+	from, to = q.From, q.To
+	if q.After != 0 {
+		if q.Descending {
+			to = min(to, q.After.Add(-1))
+		} else {
+			from = max(from, q.After.Add(1))
+		}
+	}
+	return from, to
+}
+
+// chunks iterates over consecutive ranges of at most size days, covering the remaining range of the query in its order.
+func (q daysQuery) chunks(size int) iter.Seq2[ltime.Day, ltime.Day] {
+	from, to := q.remaining()
+	return func(yield func(ltime.Day, ltime.Day) bool) {
+		if q.Descending {
+			for end := to; end >= from; end = end.Add(-size) {
+				if !yield(max(end.Add(1-size), from), end) {
+					return
+				}
+			}
+			return
+		}
+		for start := from; start <= to; start = start.Add(size) {
+			if !yield(start, min(start.Add(size-1), to)) {
+				return
+			}
+		}
+	}
+}
+
+// sort sorts menus in the order of the query, keeping the order of locations on the same day.
+// Unless requested, items are removed.
+func (q daysQuery) sort(menus []LocationMenuDay) {
+	if q.Descending {
+		slices.SortStableFunc(menus, func(a, b LocationMenuDay) int { return cmp.Compare(b.Day, a.Day) })
+	}
+	if !q.Items {
+		for i := range menus {
+			menus[i].Items = nil
+		}
+	}
+}
+
+// menusPage returns the page of days with a menu at any of the given locations matching the query.
+// next is the cursor of the next page, or empty on the last page.
+// Pages contain [apiDefaultDays] days unless the query has a limit.
+//
+// Days are counted first, so that items are only loaded for the days of the page.
+func (api *API) menusPage(locations []location.Location, q daysQuery) (menus []LocationMenuDay, next string, err error) {
+	from, to := q.remaining()
+	if from > to {
+		return nil, "", nil
+	}
+
+	menus, err = api.MenuCounts(locations, from, to)
+	if err != nil {
+		return nil, "", err
+	}
+	q.sort(menus)
+
+	// cut the page after the first limit days, keeping all locations of the last day
+	limit := cmp.Or(q.Limit, apiDefaultDays)
+	days := 0
+	for i, menu := range menus {
+		if i > 0 && menu.Day == menus[i-1].Day {
+			continue
+		}
+		if days == limit {
+			next = menus[i-1].Day.String()
+			menus = menus[:i]
+			break
+		}
+		days++
+	}
+
+	if !q.Items || len(menus) == 0 {
+		return menus, next, nil
+	}
+
+	first, last := menus[0].Day, menus[len(menus)-1].Day
+	if q.Descending {
+		first, last = last, first
+	}
+	menus, err = api.Menus(locations, first, last)
+	if err != nil {
+		return nil, "", err
+	}
+	q.sort(menus)
+	return menus, next, nil
+}
+
+// menuDaysPage returns the page of days with a menu at the given location matching the query.
+func (api *API) menuDaysPage(loc location.Location, q daysQuery) (page MenuDays, err error) {
+	menus, next, err := api.menusPage([]location.Location{loc}, q)
+	if err != nil {
+		return page, err
+	}
+
+	page = MenuDays{Location: string(loc), Days: make([]MenuDay, len(menus)), Next: next}
+	for i, menu := range menus {
+		page.Days[i] = menu.MenuDay
+	}
+	return page, nil
+}
+
+// handleAPIMenuDays handles the deprecated /menu/{location} route, see [Server.serveDays].
 func (server *Server) handleAPIMenuDays(w http.ResponseWriter, r *http.Request) {
+	server.serveDays(w, r, "API.MenuDays", true)
+}
+
+// handleAPIDays handles the /days/{location} route, see [Server.serveDays].
+func (server *Server) handleAPIDays(w http.ResponseWriter, r *http.Request) {
+	server.serveDays(w, r, "API.Days", false)
+}
+
+// serveDays serves the days with a menu at a single location, as described by [parseDaysQuery].
+// If legacy is set and none of the [apiRangeParameters] are given, the plain list of days of version 1.0 of the api is returned instead of a page.
+func (server *Server) serveDays(w http.ResponseWriter, r *http.Request, route string, legacy bool) {
 	location := location.Location(r.PathValue("location"))
 
-	logger := server.Logger.With().Str("route", "API.MenuDays").Str("location", string(location)).Logger()
+	logger := server.Logger.With().Str("route", route).Str("location", string(location)).Logger()
 
-	// get the day to start with
-	from := ltime.ParseDay(r.URL.Query().Get("from"))
-	if from == 0 {
-		from = ltime.Today().Add(-21) // default 21 days ago
+	query, err := parseDaysQuery(r.URL.Query())
+	if err != nil {
+		server.handleBadRequest(w)
+		return
 	}
 
-	count, err := strconv.Atoi(r.URL.Query().Get("days"))
+	exists, err := server.API.KnowsLocation(location)
+	logger.Trace().Err(err).Msg("API.KnowsLocation")
+
 	if err != nil {
-		count = 28 // default to 28 days
+		server.handleInternalServerError(w)
+		return
 	}
-
-	// clamp to the range 1 ... 365
-	if count < 1 {
-		count = 1
+	if !exists {
+		server.handleNotFound(w)
+		return
 	}
-	if count > 365 {
-		count = 365
+
+	if wantsJSONLines(r) {
+		server.streamMenus(w, &logger, query, location)
+		return
 	}
 
-	results, err := server.API.Days(location, from, count)
-	logger.Trace().Err(err).Msg("API.Days")
+	var results any
+	if legacy && !slices.ContainsFunc(apiRangeParameters, r.URL.Query().Has) {
+		results, err = server.API.Days(location, query.From, query.From.DaysUntil(query.To)+1)
+		logger.Trace().Err(err).Msg("API.Days")
+	} else {
+		results, err = server.API.menuDaysPage(location, query)
+		logger.Trace().Err(err).Msg("API.menuDaysPage")
+	}
 
 	// something went wrong
 	if err != nil {
@@ -135,25 +407,143 @@ func (server *Server) handleAPIMenuDays(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// check if the location exists
-	if len(results) == 0 {
-		exists, err := server.API.KnowsLocation(location)
+	// send the response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (server *Server) handleAPIMenus(w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "API.Menus").Logger()
+
+	query, err := parseDaysQuery(r.URL.Query())
+	if err != nil {
+		server.handleBadRequest(w)
+		return
+	}
+
+	// determine the locations, defaulting to all of them
+	var locations []location.Location
+	for _, value := range r.URL.Query()["location"] {
+		loc := location.Location(value)
+		if slices.Contains(locations, loc) {
+			continue
+		}
+
+		exists, err := server.API.KnowsLocation(loc)
+		logger.Trace().Err(err).Str("location", value).Msg("API.KnowsLocation")
+
 		if err != nil {
 			server.handleInternalServerError(w)
 			return
 		}
-
 		if !exists {
 			server.handleNotFound(w)
 			return
 		}
+		locations = append(locations, loc)
+	}
+	if len(locations) == 0 {
+		locations, err = server.API.Locations()
+		logger.Trace().Err(err).Msg("API.Locations")
+
+		if err != nil {
+			server.handleInternalServerError(w)
+			return
+		}
+	}
+
+	if wantsJSONLines(r) {
+		server.streamMenus(w, &logger, query, locations...)
+		return
+	}
+
+	menus, next, err := server.API.menusPage(locations, query)
+	logger.Trace().Err(err).Msg("API.menusPage")
+
+	if err != nil {
+		server.handleInternalServerError(w)
+		return
+	}
+
+	results := MenusPage{Menus: menus, Next: next}
+	if results.Menus == nil {
+		results.Menus = []LocationMenuDay{}
 	}
 
-	// send the response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
 }
 
+// wantsJSONLines checks if the Accept header of the request includes JSON Lines.
+// JSON Lines with a quality of 0 are not acceptable, see RFC 9110, Section 12.4.2.
+func wantsJSONLines(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil || mediaType != openapi.JSONLines {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// apiStreamDays is the number of days loaded at once by [Server.streamMenus].
+const apiStreamDays = 7
+
+// streamMenus streams the days with a menu at the given locations in the remaining range of the query as JSON Lines, one [LocationMenuDay] per line.
+// If the query has a limit, at most that many days are streamed.
+//
+// The range is loaded in chunks of [apiStreamDays] days, so that large ranges are not held in memory.
+// If loading a chunk fails after the response was started, the response is cut short.
+func (server *Server) streamMenus(w http.ResponseWriter, logger *zerolog.Logger, query daysQuery, locations ...location.Location) {
+	w.Header().Set("Content-Type", openapi.JSONLines)
+
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+
+	started := false
+	days := 0
+	for from, to := range query.chunks(apiStreamDays) {
+		menus, err := server.API.Menus(locations, from, to)
+		logger.Trace().Err(err).Stringer("from", from).Stringer("to", to).Msg("API.Menus")
+
+		if err != nil {
+			if !started {
+				server.handleInternalServerError(w)
+			}
+			return
+		}
+
+		query.sort(menus)
+		for i, menu := range menus {
+			if i == 0 || menu.Day != menus[i-1].Day {
+				if query.Limit != 0 && days == query.Limit {
+					break
+				}
+				days++
+			}
+			if err := encoder.Encode(menu); err != nil {
+				return
+			}
+		}
+		controller.Flush()
+		started = true
+
+		if query.Limit != 0 && days == query.Limit {
+			break
+		}
+	}
+
+	if !started {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
 func (server *Server) handleAPIMenu(w http.ResponseWriter, r *http.Request) {
 	day := ltime.ParseDay(r.PathValue("day"))
 	location := location.Location(r.PathValue("location"))
@@ -177,6 +567,11 @@ func (server *Server) handleAPIMenu(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(results)
 }
 
+func (server *Server) handleAPIAnnotations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(annotations.NewCatalogue())
+}
+
 func (server *Server) handleAPIsqlite(w http.ResponseWriter, r *http.Request) {
 	if server.API.Copier == nil {
 		server.handleNotFound(w)
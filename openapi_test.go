@@ -0,0 +1,288 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words encoding json http httptest path filepath reflect testing github zerolog faulunch internal export ltime gorm gormlogger
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/export"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// TestAPIDocument checks that the committed openapi documents match the documents generated from the code.
+// They are updated using "go test -run TestAPIDocument -update".
+func TestAPIDocument(t *testing.T) {
+	if _, err := faulunch.APIDocument(); err != nil {
+		t.Fatalf("APIDocument() error = %v", err)
+	}
+	if _, err := faulunch.APIDocumentV2(); err != nil {
+		t.Fatalf("APIDocumentV2() error = %v", err)
+	}
+
+	logger := zerolog.Nop()
+	server := &faulunch.Server{Logger: &logger}
+	compareGolden(t, "openapi.json", get(t, server, "/api/openapi.json"))
+	compareGolden(t, "openapi.v2.json", get(t, server, "/api/v2/openapi.json"))
+}
+
+// TestAPIResponses checks that the responses of all api routes match the generated document.
+func TestAPIResponses(t *testing.T) {
+	logger := zerolog.Nop()
+	db, copier := newSyncedDB(t, &logger)
+
+	api := faulunch.API{DB: db, Copier: copier}
+	days, err := api.Days("mensa-sued", 0, 365*100)
+	if err != nil || len(days) == 0 {
+		t.Fatalf("Days() = %v, %v", days, err)
+	}
+
+	validate := func(r *http.Request, err error) { t.Error(err) }
+	servers := map[string]*faulunch.Server{
+		"export":    {Logger: &logger, API: api, ValidateAPI: validate},
+		"no export": {Logger: &logger, API: faulunch.API{DB: db}, ValidateAPI: validate},
+	}
+
+	tests := []struct {
+		server string
+		path   string
+		status int
+	}{
+		{"export", "/api/v1/healthcheck", http.StatusOK},
+		{"export", "/api/v1/sync", http.StatusOK},
+		{"export", "/api/v1/locations", http.StatusOK},
+		{"export", "/api/v1/locations/nearby?lat=49.5803&lon=11.029&limit=3", http.StatusOK},
+		{"export", "/api/v1/locations/nearby?lat=100&lon=11.029", http.StatusBadRequest},
+		{"export", "/api/v1/menu/mensa-sued?from=" + days[len(days)-1].String() + "&days=365", http.StatusOK},
+		{"export", "/api/v1/menu/mensa-sued?from=" + days[len(days)-1].String() + "&days=1000", http.StatusOK},
+		{"export", "/api/v1/menu/mensa-sued?from=0", http.StatusBadRequest},
+		{"export", "/api/v1/days/mensa-sued?from=" + days[len(days)-1].String() + "&days=7", http.StatusOK},
+		{"export", "/api/v1/menu/mensa-sued?from=" + days[len(days)-1].String() + "&to=" + days[0].String() + "&include=items", http.StatusOK},
+		{"export", "/api/v1/menu/mensa-sued?order=random", http.StatusBadRequest},
+		{"export", "/api/v1/days/mensa-sued?from=" + days[len(days)-1].String() + "&to=" + days[0].String() + "&order=asc&limit=1&include=items", http.StatusOK},
+		{"export", "/api/v1/days/mensa-sued?from=" + days[len(days)-1].String() + "&to=" + days[0].String() + "&after=" + days[0].String(), http.StatusOK},
+		{"export", "/api/v1/days/mensa-sued?order=random", http.StatusBadRequest},
+		{"export", "/api/v1/days/mensa-sued?limit=0", http.StatusBadRequest},
+		{"export", "/api/v1/days/mensa-sued?from=1682028000&to=1600000000", http.StatusBadRequest},
+		{"export", "/api/v1/days/does-not-exist", http.StatusNotFound},
+		{"export", "/api/v1/menu", http.StatusOK},
+		{"export", "/api/v1/menu?location=mensa-sued&location=mensa-sued&include=items&from=" + days[len(days)-1].String() + "&to=" + days[0].String(), http.StatusOK},
+		{"export", "/api/v1/menu?location=does-not-exist", http.StatusNotFound},
+		{"export", "/api/v1/menu?order=random", http.StatusBadRequest},
+		{"export", "/api/v1/menu?limit=0", http.StatusBadRequest},
+		{"export", "/api/v1/menu?location=mensa-sued&limit=1&after=" + days[0].String() + "&from=" + days[len(days)-1].String() + "&to=" + days[0].String(), http.StatusOK},
+		{"export", "/api/v1/menu/does-not-exist", http.StatusNotFound},
+		{"export", "/api/v1/menu/mensa-sued/" + days[0].String(), http.StatusOK},
+		{"export", "/api/v1/menu/mensa-sued/0", http.StatusNotFound},
+		{"export", "/api/v1/annotations", http.StatusOK},
+		{"export", "/api/v1/sqlite", http.StatusOK},
+		{"no export", "/api/v1/sqlite", http.StatusNotFound},
+
+		{"export", "/api/v2/sync", http.StatusOK},
+		{"export", "/api/v2/locations", http.StatusOK},
+		{"export", "/api/v2/locations/nearby?lat=49.5803&lon=11.029&limit=3", http.StatusOK},
+		{"export", "/api/v2/locations/nearby?lat=49.5803", http.StatusBadRequest},
+		{"export", "/api/v2/locations/mensa-sued", http.StatusOK},
+		{"export", "/api/v2/locations/does-not-exist", http.StatusNotFound},
+		{"export", "/api/v2/menu/mensa-sued?from=" + days[len(days)-1].Date() + "&to=" + days[0].Date(), http.StatusOK},
+		{"export", "/api/v2/menu/mensa-sued?from=2023-05-10&to=2023-05-01", http.StatusBadRequest},
+		{"export", "/api/v2/menu/mensa-sued?from=2020-01-01&to=2023-01-01", http.StatusBadRequest},
+		{"export", "/api/v2/menu/does-not-exist", http.StatusNotFound},
+		{"export", "/api/v2/menu/mensa-sued/" + days[0].Date(), http.StatusOK},
+		{"export", "/api/v2/menu/mensa-sued/2000-01-01", http.StatusOK},
+		{"export", "/api/v2/menu/mensa-sued/" + days[0].String(), http.StatusBadRequest},
+		{"export", "/api/v2/menu/does-not-exist/2000-01-01", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.server+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			servers[tt.server].ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("GET %s returned status %d, want %d", tt.path, rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+// TestAPIMenusJSONLines checks that menus streamed as JSON Lines match the json responses.
+func TestAPIMenusJSONLines(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }}
+
+	// the range spans several chunks
+	query := "from=1760306400&to=1762988400&include=items"
+	for _, order := range []string{"asc", "desc"} {
+		t.Run(order, func(t *testing.T) {
+			var page faulunch.MenusPage
+			if err := json.Unmarshal(get(t, server, "/api/v1/menu?order="+order+"&"+query), &page); err != nil {
+				t.Fatalf("failed to decode json response: %v", err)
+			}
+			want := page.Menus
+			if len(want) != 2 || want[0].Count == 0 || len(want[0].Items) != want[0].Count || page.Next != "" {
+				t.Fatalf("json response = %v, want two days with items", page)
+			}
+
+			for path, want := range map[string][]faulunch.LocationMenuDay{
+				"/api/v1/menu?":                                            want,
+				"/api/v1/menu/mensa-sued?":                                 want,
+				"/api/v1/days/mensa-sued?":                                 want,
+				"/api/v1/menu/mensa-sued?limit=1&":                         want[:1],
+				"/api/v1/menu?limit=1&after=" + want[0].Day.String() + "&": want[1:],
+			} {
+				req := httptest.NewRequest(http.MethodGet, path+"order="+order+"&"+query, nil)
+				req.Header.Set("Accept", "application/jsonl, application/json;q=0.5")
+				rec := httptest.NewRecorder()
+				server.ServeHTTP(rec, req)
+
+				if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/jsonl" {
+					t.Fatalf("GET %s returned status %d and content type %q", path, rec.Code, rec.Header().Get("Content-Type"))
+				}
+
+				var got []faulunch.LocationMenuDay
+				decoder := json.NewDecoder(rec.Body)
+				for decoder.More() {
+					var menu faulunch.LocationMenuDay
+					if err := decoder.Decode(&menu); err != nil {
+						t.Fatalf("failed to decode line: %v", err)
+					}
+					got = append(got, menu)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("GET %s streamed %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestAPIMenuDays checks that range parameters of the plain json list of days are not ignored.
+func TestAPIMenuDays(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }}
+
+	query := "?from=1760306400&to=1762988400&include=items"
+
+	var page faulunch.MenuDays
+	if err := json.Unmarshal(get(t, server, "/api/v1/menu/mensa-sued"+query), &page); err != nil {
+		t.Fatalf("failed to decode json response: %v", err)
+	}
+	if len(page.Days) != 2 || page.Days[0].Count == 0 || len(page.Days[0].Items) != page.Days[0].Count {
+		t.Fatalf("json response = %v, want two days with items", page)
+	}
+
+	if got, want := string(get(t, server, "/api/v1/menu/mensa-sued"+query)), string(get(t, server, "/api/v1/days/mensa-sued"+query)); got != want {
+		t.Errorf("GET /api/v1/menu/mensa-sued = %s, want %s", got, want)
+	}
+}
+
+// TestAPIMenusPages checks that the menus of several locations are paginated.
+func TestAPIMenusPages(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }}
+
+	query := "/api/v1/menu?from=1760306400&to=1762988400&order=asc&limit=1"
+
+	var days []ltime.Day
+	after := ""
+	for range 3 {
+		var page faulunch.MenusPage
+		if err := json.Unmarshal(get(t, server, query+after), &page); err != nil {
+			t.Fatalf("failed to decode json response: %v", err)
+		}
+		if len(page.Menus) != 1 {
+			t.Fatalf("GET %s returned %d days, want 1", query+after, len(page.Menus))
+		}
+		days = append(days, page.Menus[0].Day)
+
+		if page.Next == "" {
+			break
+		}
+		after = "&after=" + page.Next
+	}
+
+	want := []ltime.Day{1760911200, 1760997600}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("paginated days = %v, want %v", days, want)
+	}
+}
+
+// TestAPIMenusAccept checks that JSON Lines are only streamed when acceptable.
+func TestAPIMenusAccept(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"application/json", "application/json"},
+		{"application/jsonl", "application/jsonl"},
+		{"application/json, application/jsonl;q=0.5", "application/jsonl"},
+		{"application/json, application/jsonl;q=0", "application/json"},
+		{"application/jsonl; q=0.000", "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/menu", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); got != tt.want {
+				t.Errorf("GET /api/v1/menu with Accept %q returned content type %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+// newSyncedDB returns a new database synced with the mensa-sued plans of the corpus, along with an exporter for it.
+func newSyncedDB(t *testing.T, logger *zerolog.Logger) (*gorm.DB, func(w http.ResponseWriter, r *http.Request) error) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "api.db")), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := faulunch.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	event := faulunch.SyncEvent{Start: 1683704244, Stop: 1683704246}
+	event.Report.Warnings, err = faulunch.Sync(logger, db, readPlan(t, filepath.Join(corpusDir, "mensa-sued.de.xml")), readPlan(t, filepath.Join(corpusDir, "mensa-sued.en.xml")))
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if err := faulunch.RefreshComputedFields(t.Context(), logger, db); err != nil {
+		t.Fatalf("RefreshComputedFields() error = %v", err)
+	}
+	if err := event.Store(t.Context(), db); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	copier, closer := export.NewExporter(t.Context(), logger, sqlDB, "SELECT COUNT(*) FROM menu_items")
+	t.Cleanup(func() { closer() })
+
+	return db, copier
+}
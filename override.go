@@ -0,0 +1,213 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words context errors time github zerolog gorm
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch/internal"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+	"gorm.io/gorm"
+)
+
+// MenuOverride represents a manual change to the menu of a location on a specific day.
+//
+// Overrides are stored separately from synced menu items, so that a [Sync] never touches them.
+// They are merged into the synced items whenever a menu is read.
+type MenuOverride struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	Day      ltime.Day         `gorm:"index" json:"-"` // the day this override is for
+	Location location.Location `gorm:"index" json:"-"` // the location this override is for
+
+	// Category and Position identify the item being overridden.
+	// Position is the position of the item among the upstream items sharing its category, starting at 1.
+	// If Position is zero, all upstream items with this category are overridden.
+	// If no such upstream item exists, a new item is added instead.
+	Category   string `json:"category"`
+	Position   int    `json:"position,omitempty"`
+	CategoryEN string `json:"categoryEN,omitempty"`
+
+	// Hidden indicates that the item should be removed from the menu entirely.
+	Hidden bool `json:"hidden"`
+
+	// Replacement values for the item.
+	// Empty values keep the upstream value.
+	TitleDE       string `json:"titleDE,omitempty"`
+	TitleEN       string `json:"titleEN,omitempty"`
+	DescriptionDE string `json:"descriptionDE,omitempty"`
+	DescriptionEN string `json:"descriptionEN,omitempty"`
+	BeilagenDE    string `json:"beilagenDE,omitempty"`
+	BeilagenEN    string `json:"beilagenEN,omitempty"`
+
+	Preis1 *types.LPrice `json:"preis1,omitempty"` // price (student)
+	Preis2 *types.LPrice `json:"preis2,omitempty"` // price (employee)
+	Preis3 *types.LPrice `json:"preis3,omitempty"` // price (guest)
+
+	Updated int64 `json:"updated"` // unix timestamp of the last change
+}
+
+var (
+	errInvalidOverride = errors.New("override must have a category")
+	errInvalidPosition = errors.New("override must not have a negative position")
+)
+
+// Validate checks that this override can be applied.
+func (mo MenuOverride) Validate() error {
+	if mo.Category == "" {
+		return errInvalidOverride
+	}
+	if mo.Position < 0 {
+		return errInvalidPosition
+	}
+	return nil
+}
+
+// matches checks if this override applies to an upstream item with the given category and position.
+func (mo MenuOverride) matches(category string, position int) bool {
+	return mo.Category == category && (mo.Position == 0 || mo.Position == position)
+}
+
+// Apply applies this override to the given item, and marks it as edited.
+// Computed fields are updated afterwards, using the given category translations.
+func (mo MenuOverride) Apply(logger *zerolog.Logger, translations CategoryTranslations, item *MenuItem) {
+	item.Location = mo.Location
+	item.Day = mo.Day
+	item.Category = mo.Category
+
+	for _, field := range []struct {
+		dest  *string
+		value string
+	}{
+		{&item.TitleDE, mo.TitleDE},
+		{&item.TitleEN, mo.TitleEN},
+		{&item.DescriptionDE, mo.DescriptionDE},
+		{&item.DescriptionEN, mo.DescriptionEN},
+		{&item.BeilagenDE, mo.BeilagenDE},
+		{&item.BeilagenEN, mo.BeilagenEN},
+	} {
+		if field.value != "" {
+			*field.dest = field.value
+		}
+	}
+
+	for _, field := range []struct {
+		dest  *types.LPrice
+		value *types.LPrice
+	}{
+		{&item.Preis1, mo.Preis1},
+		{&item.Preis2, mo.Preis2},
+		{&item.Preis3, mo.Preis3},
+	} {
+		if field.value != nil {
+			*field.dest = *field.value
+		}
+	}
+
+	item.UpdateComputedFields(logger, translations)
+	if mo.CategoryEN != "" {
+		item.CategoryEN = mo.CategoryEN
+	}
+	item.Edited = true
+}
+
+// mergeOverrides merges the given overrides into the given items.
+// Items and overrides are assumed to belong to the same location and day.
+// Items are assumed to be in upstream order, which determines their position within their category.
+func mergeOverrides(logger *zerolog.Logger, translations CategoryTranslations, items []MenuItem, overrides []MenuOverride) []MenuItem {
+	// positions are determined before any override is applied, so that they do not shift when items are hidden.
+	positions := make(map[string]int, len(items))
+	for i := range items {
+		positions[items[i].Category]++
+		items[i].position = positions[items[i].Category]
+	}
+
+	hidden := make([]bool, len(items))
+	for _, override := range overrides {
+		found := false
+		for i := range hidden {
+			if !override.matches(items[i].Category, items[i].position) {
+				continue
+			}
+			found = true
+
+			if override.Hidden {
+				hidden[i] = true
+				continue
+			}
+			override.Apply(logger, translations, &items[i])
+		}
+
+		if found || override.Hidden {
+			continue
+		}
+
+		var item MenuItem
+		internal.SetJSONData(&item.Piktogramme, []annotations.Ingredient{})
+		override.Apply(logger, translations, &item)
+		items = append(items, item)
+	}
+
+	merged := items[:0]
+	for i, item := range items {
+		if i >= len(hidden) || !hidden[i] {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// Overrides returns the overrides for the given location and day.
+func (api *API) Overrides(ctx context.Context, loc location.Location, day ltime.Day) ([]MenuOverride, error) {
+	return gorm.G[MenuOverride](api.DB).Where("Location = ? AND Day = ?", loc, day).Order("ID ASC").Find(ctx)
+}
+
+// ErrNoOverride is returned when an override does not exist.
+var ErrNoOverride = errors.New("override does not exist")
+
+// StoreOverride creates or updates the given override.
+// If override.ID is zero, a new override is created.
+func (api *API) StoreOverride(ctx context.Context, override *MenuOverride) error {
+	if err := override.Validate(); err != nil {
+		return err
+	}
+	override.Updated = time.Now().Unix()
+
+	if override.ID == 0 {
+		if err := gorm.G[MenuOverride](api.DB).Create(ctx, override); err != nil {
+			return fmt.Errorf("failed to create override: %w", err)
+		}
+		return nil
+	}
+
+	res := api.DB.WithContext(ctx).Model(&MenuOverride{}).
+		Where("ID = ? AND Location = ? AND Day = ?", override.ID, override.Location, override.Day).
+		Select("*").
+		Updates(override)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update override: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNoOverride
+	}
+	return nil
+}
+
+// DeleteOverride deletes the override with the given id for the given location and day.
+func (api *API) DeleteOverride(ctx context.Context, loc location.Location, day ltime.Day, id uint) error {
+	count, err := gorm.G[MenuOverride](api.DB).Where("ID = ? AND Location = ? AND Day = ?", id, loc, day).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete override: %w", err)
+	}
+	if count == 0 {
+		return ErrNoOverride
+	}
+	return nil
+}
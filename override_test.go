@@ -0,0 +1,242 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words errors slices testing github zerolog faulunch internal location ltime types
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/types"
+)
+
+func TestMenuOverride_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		override faulunch.MenuOverride
+		wantErr  bool
+	}{
+		{"empty", faulunch.MenuOverride{}, true},
+		{"only title", faulunch.MenuOverride{TitleDE: "Kürbissuppe"}, true},
+		{"category", faulunch.MenuOverride{Category: "Suppe"}, false},
+		{"hidden", faulunch.MenuOverride{Category: "Suppe", Hidden: true}, false},
+		{"position", faulunch.MenuOverride{Category: "Suppe", Position: 2}, false},
+		{"negative position", faulunch.MenuOverride{Category: "Suppe", Position: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.override.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMenuOverride_Apply(t *testing.T) {
+	price := func(p float64) *types.LPrice {
+		lp := types.LPrice(p)
+		return &lp
+	}
+	item := faulunch.MenuItem{
+		Category:   "Essen 1",
+		TitleDE:    "Schweineschnitzel",
+		TitleEN:    "Pork schnitzel",
+		BeilagenDE: "Pommes",
+		Preis1:     3.4,
+		Preis2:     5.1,
+	}
+
+	tests := []struct {
+		name     string
+		override faulunch.MenuOverride
+		check    func(t *testing.T, got faulunch.MenuItem)
+	}{
+		{
+			name:     "empty values keep the item",
+			override: faulunch.MenuOverride{Category: "Essen 1"},
+			check: func(t *testing.T, got faulunch.MenuItem) {
+				if got.TitleDE != item.TitleDE || got.TitleEN != item.TitleEN || got.BeilagenDE != item.BeilagenDE || got.Preis1 != item.Preis1 || got.Preis2 != item.Preis2 {
+					t.Errorf("Apply() = %+v, want unchanged values", got)
+				}
+			},
+		},
+		{
+			name:     "values replace the item",
+			override: faulunch.MenuOverride{Category: "Essen 1", TitleDE: "Kürbissuppe", Preis1: price(2.5), Preis3: price(0)},
+			check: func(t *testing.T, got faulunch.MenuItem) {
+				if got.TitleDE != "Kürbissuppe" || got.TitleEN != item.TitleEN || got.Preis1 != 2.5 || got.Preis2 != item.Preis2 || got.Preis3 != 0 {
+					t.Errorf("Apply() = %+v, want replaced title and first price", got)
+				}
+			},
+		},
+		{
+			name:     "category and location",
+			override: faulunch.MenuOverride{Location: location.MensaSued, Day: 1760911200, Category: "Aktion", CategoryEN: "Special"},
+			check: func(t *testing.T, got faulunch.MenuItem) {
+				if got.Location != location.MensaSued || got.Day != 1760911200 || got.Category != "Aktion" || got.CategoryEN != "Special" {
+					t.Errorf("Apply() = %+v, want category and location of the override", got)
+				}
+			},
+		},
+	}
+
+	logger := zerolog.Nop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := item
+			tt.override.Apply(&logger, nil, &got)
+
+			if !got.Edited {
+				t.Error("Apply() did not mark the item as edited")
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestAPI_MenuItems_overrides(t *testing.T) {
+	monday := ltime.ParseDay("1760911200")
+
+	tests := []struct {
+		name      string
+		overrides []faulunch.MenuOverride
+		want      []string // titles of the merged items
+		edited    string   // title of the edited item, if any
+	}{
+		{
+			name:      "hide",
+			overrides: []faulunch.MenuOverride{faulunch.MenuOverride{Category: "Essen 2", Hidden: true}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Tomatensuppe (veg)", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)"},
+		},
+		{
+			name:      "hide unknown category",
+			overrides: []faulunch.MenuOverride{faulunch.MenuOverride{Category: "Dessert", Hidden: true}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Tomatensuppe (veg)", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)"},
+		},
+		{
+			name:      "edit",
+			overrides: []faulunch.MenuOverride{faulunch.MenuOverride{Category: "Essen 2", TitleDE: "Kichererbsencurry"}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Kichererbsencurry", "Tomatensuppe (veg)", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)"},
+			edited:    "Kichererbsencurry",
+		},
+		{
+			name:      "add",
+			overrides: []faulunch.MenuOverride{faulunch.MenuOverride{Category: "Aktion", TitleDE: "Kürbissuppe"}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Kürbissuppe", "Tomatensuppe (veg)", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)"},
+			edited:    "Kürbissuppe",
+		},
+		{
+			name:      "hide all items of a category",
+			overrides: []faulunch.MenuOverride{{Category: "Suppe", Hidden: true}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis"},
+		},
+		{
+			name:      "hide one item of a category",
+			overrides: []faulunch.MenuOverride{{Category: "Suppe", Position: 2, Hidden: true}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Tomatensuppe (veg)"},
+		},
+		{
+			name:      "edit one item of a category",
+			overrides: []faulunch.MenuOverride{{Category: "Suppe", Position: 1, TitleDE: "Kürbissuppe"}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Kürbissuppe", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)"},
+			edited:    "Kürbissuppe",
+		},
+		{
+			name: "positions do not shift when hiding",
+			overrides: []faulunch.MenuOverride{
+				{Category: "Suppe", Position: 1, Hidden: true},
+				{Category: "Suppe", Position: 2, TitleDE: "Kürbissuppe"},
+			},
+			want:   []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Kürbissuppe"},
+			edited: "Kürbissuppe",
+		},
+		{
+			name:      "unknown position",
+			overrides: []faulunch.MenuOverride{{Category: "Suppe", Position: 3, TitleDE: "Kürbissuppe"}},
+			want:      []string{"Schweineschnitzel (Wz,Ei,Mi) mit Pommes frites (Vegan)", "Gemüsecurry (So,Sel1) mit Basmatireis", "Tomatensuppe (veg)", "Linsensuppe mit Wiener Würstchen (Sel,Xy,2,4)", "Kürbissuppe"},
+			edited:    "Kürbissuppe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			db, _ := newSyncedDB(t, &logger)
+			api := faulunch.API{DB: db}
+
+			for _, override := range tt.overrides {
+				override.Location = location.MensaSued
+				override.Day = monday
+				if err := api.StoreOverride(t.Context(), &override); err != nil {
+					t.Fatalf("StoreOverride() error = %v", err)
+				}
+			}
+
+			items, err := api.MenuItems(location.MensaSued, monday)
+			if err != nil {
+				t.Fatalf("MenuItems() error = %v", err)
+			}
+
+			titles := make([]string, len(items))
+			for i, item := range items {
+				titles[i] = item.TitleDE
+				if item.Edited != (item.TitleDE == tt.edited) {
+					t.Errorf("MenuItems() returned %q with Edited = %v", item.TitleDE, item.Edited)
+				}
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Errorf("MenuItems() = %q, want %q", titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_StoreOverride(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	api := faulunch.API{DB: db}
+
+	monday := ltime.ParseDay("1760911200")
+
+	created := faulunch.MenuOverride{Location: location.MensaSued, Day: monday, Category: "Essen 1", TitleDE: "Kürbissuppe"}
+	if err := api.StoreOverride(t.Context(), &created); err != nil {
+		t.Fatalf("StoreOverride() error = %v", err)
+	}
+	if created.ID == 0 || created.Updated == 0 {
+		t.Fatalf("StoreOverride() = %+v, want id and updated to be set", created)
+	}
+
+	tests := []struct {
+		name     string
+		override faulunch.MenuOverride
+		wantErr  error // nil if any error is acceptable
+		fail     bool
+	}{
+		{"update", faulunch.MenuOverride{ID: created.ID, Location: location.MensaSued, Day: monday, Category: "Essen 1", TitleDE: "Kartoffelsuppe"}, nil, false},
+		{"invalid", faulunch.MenuOverride{ID: created.ID, Location: location.MensaSued, Day: monday}, nil, true},
+		{"unknown id", faulunch.MenuOverride{ID: created.ID + 1, Location: location.MensaSued, Day: monday, Category: "Essen 1"}, faulunch.ErrNoOverride, true},
+		{"other day", faulunch.MenuOverride{ID: created.ID, Location: location.MensaSued, Day: monday.Add(1), Category: "Essen 1"}, faulunch.ErrNoOverride, true},
+		{"other location", faulunch.MenuOverride{ID: created.ID, Location: "cafeteria-come-in", Day: monday, Category: "Essen 1"}, faulunch.ErrNoOverride, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override := tt.override
+			err := api.StoreOverride(t.Context(), &override)
+			if (err != nil) != tt.fail || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("StoreOverride() error = %v, want %v (fail = %v)", err, tt.wantErr, tt.fail)
+			}
+		})
+	}
+
+	overrides, err := api.Overrides(t.Context(), location.MensaSued, monday)
+	if err != nil {
+		t.Fatalf("Overrides() error = %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].ID != created.ID || overrides[0].TitleDE != "Kartoffelsuppe" {
+		t.Errorf("Overrides() = %+v, want the updated override only", overrides)
+	}
+}
@@ -12,76 +12,187 @@ import (
 )
 
 // Merge merges the german and english plan for the given location into a set of menu items, a location, and a timestamp.
-func Merge(logger *zerolog.Logger, german plan.Plan, english plan.Plan) (loc location.Location, timestamps []ltime.Day, menu []MenuItem) {
+//
+// The plans are validated strictly, problems are returned as warnings.
+// If the plans refer to different or unknown locations, nothing is merged.
+// Days only present in one of the plans are skipped.
+// If a plan contains a day more than once, only the first one is used.
+// Items are paired using [plan.Pair], items without a counterpart are kept and reported.
+func Merge(logger *zerolog.Logger, german plan.Plan, english plan.Plan) (loc location.Location, timestamps []ltime.Day, menu []MenuItem, warnings []SyncWarning) {
 	// extract the location
 	loc = location.LocationOfID(german.Location)
+	if german.Location != english.Location {
+		warnings = append(warnings, SyncWarning{
+			Kind:     WarningLocationMismatch,
+			Location: string(loc),
+			FeedID:   german.Location,
+			FeedIDEN: english.Location,
+			Message:  "german and english plans refer to different locations",
+		})
+		return "", nil, nil, warnings
+	}
+	if !loc.Valid() {
+		warnings = append(warnings, SyncWarning{
+			Kind:    WarningUnknownLocation,
+			FeedID:  german.Location,
+			Message: "plans refer to an unknown location",
+		})
+		return "", nil, nil, warnings
+	}
 
-	// create a map from days => category to menuitem
-	dayCatMap := make(map[int]map[string]MenuItem)
-
-	for _, daylang := range []struct {
-		Plan    plan.Plan
-		English bool
-	}{
-		{german, false},
-		{english, true},
-	} {
-		for _, day := range daylang.Plan.Days {
-			timestamp := ltime.ParseDay(day.Timestamp)
-
-			// generate a map of categories
-			catMap := dayCatMap[day.Timestamp]
-			if catMap == nil {
-				catMap = make(map[string]MenuItem, len(day.Items))
-			}
+	// drop duplicate days
+	germanUnique, germanDuplicates := uniqueDays(german)
+	for _, timestamp := range germanDuplicates {
+		warnings = append(warnings, SyncWarning{
+			Kind:     WarningDuplicateDay,
+			Location: string(loc),
+			FeedID:   german.Location,
+			Day:      ltime.ParseDay(timestamp),
+			Message:  "day appears more than once in german plan",
+		})
+	}
+	englishUnique, englishDuplicates := uniqueDays(english)
+	for _, timestamp := range englishDuplicates {
+		warnings = append(warnings, SyncWarning{
+			Kind:     WarningDuplicateDay,
+			Location: string(loc),
+			FeedID:   german.Location,
+			Day:      ltime.ParseDay(timestamp),
+			Message:  "day appears more than once in english plan",
+		})
+	}
+
+	// find the days present in both plans
+	germanDays := daysOf(germanUnique)
+	englishDays := daysOf(englishUnique)
+	for _, day := range germanUnique {
+		if _, ok := englishDays[day.Timestamp]; ok {
+			continue
+		}
+		warnings = append(warnings, SyncWarning{
+			Kind:      WarningMissingDay,
+			Location:  string(loc),
+			FeedID:    german.Location,
+			Day:       ltime.ParseDay(day.Timestamp),
+			MissingEN: true,
+			Message:   "day is missing from english plan",
+		})
+	}
+	for _, day := range englishUnique {
+		if _, ok := germanDays[day.Timestamp]; ok {
+			continue
+		}
+		warnings = append(warnings, SyncWarning{
+			Kind:     WarningMissingDay,
+			Location: string(loc),
+			FeedID:   german.Location,
+			Day:      ltime.ParseDay(day.Timestamp),
+			Message:  "day is missing from german plan",
+		})
+	}
+
+	// pair up the items of each day
+	for _, gDay := range germanUnique {
+		eDay, ok := englishDays[gDay.Timestamp]
+		if !ok {
+			continue
+		}
 
-			for _, item := range day.Items {
-				menu := catMap[item.Category]
-				menu.Location = loc
-				menu.Day = timestamp
-				menu.Category = item.Category
+		timestamp := ltime.ParseDay(gDay.Timestamp)
+		timestamps = append(timestamps, timestamp)
 
-				if daylang.English {
-					menu.TitleEN = item.Title
-					menu.DescriptionEN = item.Description
-					menu.BeilagenEN = item.Beilagen
+		for _, pair := range plan.Pair(gDay.Items, eDay.Items) {
+			if !pair.Paired() {
+				warning := SyncWarning{
+					Kind:     WarningUnpairedItem,
+					Location: string(loc),
+					FeedID:   german.Location,
+					Day:      timestamp,
+				}
+				if pair.English == nil {
+					warning.Category = pair.German.Category
+					warning.Title = pair.German.Title
+					warning.MissingEN = true
+					warning.Message = "item has no english counterpart"
 				} else {
-					menu.TitleDE = item.Title
-					menu.DescriptionDE = item.Description
-					menu.BeilagenDE = item.Beilagen
+					warning.Category = pair.English.Category
+					warning.Title = pair.English.Title
+					warning.Message = "item has no german counterpart"
 				}
-
-				menu.Preis1 = types.LPrice(item.Preis1)
-				menu.Preis2 = types.LPrice(item.Preis2)
-				menu.Preis3 = types.LPrice(item.Preis3)
-
-				// TODO: Extract Piktogramme
-				internal.SetJSONData(&menu.Piktogramme, menu.parseIngredients(item.Piktogramme, logger))
-				menu.Kj = types.LFloat(item.Kj)
-				menu.Kcal = types.LFloat(item.Kcal)
-				menu.Fett = types.LFloat(item.Fett)
-				menu.Gesfett = types.LFloat(item.Gesfett)
-				menu.Kh = types.LFloat(item.Kh)
-				menu.Zucker = types.LFloat(item.Zucker)
-				menu.Ballaststoffe = types.LFloat(item.Ballaststoffe)
-				menu.Eiweiss = types.LFloat(item.Eiweiss)
-				menu.Salz = types.LFloat(item.Salz)
-
-				catMap[item.Category] = menu
+				warnings = append(warnings, warning)
 			}
 
-			dayCatMap[day.Timestamp] = catMap
+			menu = append(menu, newMenuItem(logger, loc, timestamp, pair))
 		}
 	}
 
-	// build the menu and all the timestamps
-	timestamps = make([]ltime.Day, 0, len(dayCatMap))
-	for t, catMap := range dayCatMap {
-		timestamps = append(timestamps, ltime.ParseDay(t))
-		for _, mitem := range catMap {
-			menu = append(menu, mitem)
+	return
+}
+
+// newMenuItem creates a new menu item from the given pair of items.
+// Language-independent fields are taken from the german item, if any.
+func newMenuItem(logger *zerolog.Logger, loc location.Location, day ltime.Day, pair plan.ItemPair) (menu MenuItem) {
+	menu.Location = loc
+	menu.Day = day
+
+	if pair.English != nil {
+		menu.TitleEN = pair.English.Title
+		menu.DescriptionEN = pair.English.Description
+		menu.BeilagenEN = pair.English.Beilagen
+	}
+
+	item := pair.English
+	if pair.German != nil {
+		menu.TitleDE = pair.German.Title
+		menu.DescriptionDE = pair.German.Description
+		menu.BeilagenDE = pair.German.Beilagen
+
+		item = pair.German
+	}
+
+	menu.Category = item.Category
+
+	menu.Preis1 = types.LPrice(item.Preis1)
+	menu.Preis2 = types.LPrice(item.Preis2)
+	menu.Preis3 = types.LPrice(item.Preis3)
+
+	// TODO: Extract Piktogramme
+	ingredients, unknown := menu.parseIngredients(item.Piktogramme, logger)
+	internal.SetJSONData(&menu.Piktogramme, ingredients)
+	internal.SetJSONData(&menu.UnknownPictograms, unknown)
+	menu.Kj = types.LFloat(item.Kj)
+	menu.Kcal = types.LFloat(item.Kcal)
+	menu.Fett = types.LFloat(item.Fett)
+	menu.Gesfett = types.LFloat(item.Gesfett)
+	menu.Kh = types.LFloat(item.Kh)
+	menu.Zucker = types.LFloat(item.Zucker)
+	menu.Ballaststoffe = types.LFloat(item.Ballaststoffe)
+	menu.Eiweiss = types.LFloat(item.Eiweiss)
+	menu.Salz = types.LFloat(item.Salz)
+
+	return menu
+}
+
+// uniqueDays returns the days of the given plan in order, keeping only the first day with each timestamp.
+// duplicates contains the timestamp of each dropped day.
+func uniqueDays(p plan.Plan) (days []plan.Day, duplicates []int) {
+	seen := make(map[int]struct{}, len(p.Days))
+	for _, day := range p.Days {
+		if _, ok := seen[day.Timestamp]; ok {
+			duplicates = append(duplicates, day.Timestamp)
+			continue
 		}
+		seen[day.Timestamp] = struct{}{}
+		days = append(days, day)
 	}
+	return days, duplicates
+}
 
-	return
+// daysOf indexes the given days by timestamp.
+func daysOf(days []plan.Day) map[int]plan.Day {
+	index := make(map[int]plan.Day, len(days))
+	for _, day := range days {
+		index[day.Timestamp] = day
+	}
+	return index
 }
@@ -0,0 +1,144 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words slices testing github zerolog faulunch internal location ltime plan
+import (
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/plan"
+)
+
+func TestMerge(t *testing.T) {
+	const (
+		monday  = 1760911200
+		tuesday = 1760997600
+	)
+
+	day := func(timestamp int, titles ...string) plan.Day {
+		d := plan.Day{Timestamp: timestamp}
+		for _, title := range titles {
+			d.Items = append(d.Items, plan.Item{Category: "Essen 1", Title: title})
+		}
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		german   plan.Plan
+		english  plan.Plan
+		wantLoc  location.Location
+		wantDays []ltime.Day
+		wantDE   []string // german titles of the merged items
+		warnings []faulunch.SyncWarning
+	}{
+		{
+			name:     "matching plans",
+			german:   plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(tuesday, "Fisch")}},
+			english:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(tuesday, "Fish")}},
+			wantLoc:  location.MensaSued,
+			wantDays: []ltime.Day{monday, tuesday},
+			wantDE:   []string{"Schnitzel", "Fisch"},
+		},
+		{
+			name:    "location mismatch",
+			german:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel")}},
+			english: plan.Plan{Location: 10, Days: []plan.Day{day(monday, "Schnitzel")}},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningLocationMismatch, Location: string(location.MensaSued), FeedID: 1, FeedIDEN: 10, Message: "german and english plans refer to different locations"},
+			},
+		},
+		{
+			name:    "unknown location",
+			german:  plan.Plan{Location: 4242, Days: []plan.Day{day(monday, "Schnitzel")}},
+			english: plan.Plan{Location: 4242, Days: []plan.Day{day(monday, "Schnitzel")}},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningUnknownLocation, FeedID: 4242, Message: "plans refer to an unknown location"},
+			},
+		},
+		{
+			name:     "day missing from english plan",
+			german:   plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(tuesday, "Fisch")}},
+			english:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel")}},
+			wantLoc:  location.MensaSued,
+			wantDays: []ltime.Day{monday},
+			wantDE:   []string{"Schnitzel"},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningMissingDay, Location: string(location.MensaSued), FeedID: 1, Day: tuesday, MissingEN: true, Message: "day is missing from english plan"},
+			},
+		},
+		{
+			name:     "day missing from german plan",
+			german:   plan.Plan{Location: 1, Days: []plan.Day{day(tuesday, "Fisch")}},
+			english:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(tuesday, "Fish")}},
+			wantLoc:  location.MensaSued,
+			wantDays: []ltime.Day{tuesday},
+			wantDE:   []string{"Fisch"},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningMissingDay, Location: string(location.MensaSued), FeedID: 1, Day: monday, Message: "day is missing from german plan"},
+			},
+		},
+		{
+			name:    "german only",
+			german:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel")}},
+			english: plan.Plan{Location: 1},
+			wantLoc: location.MensaSued,
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningMissingDay, Location: string(location.MensaSued), FeedID: 1, Day: monday, MissingEN: true, Message: "day is missing from english plan"},
+			},
+		},
+		{
+			name:     "duplicate days",
+			german:   plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(monday, "Schnitzel"), day(tuesday, "Fisch")}},
+			english:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel"), day(tuesday, "Fish"), day(tuesday, "Fish")}},
+			wantLoc:  location.MensaSued,
+			wantDays: []ltime.Day{monday, tuesday},
+			wantDE:   []string{"Schnitzel", "Fisch"},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningDuplicateDay, Location: string(location.MensaSued), FeedID: 1, Day: monday, Message: "day appears more than once in german plan"},
+				{Kind: faulunch.WarningDuplicateDay, Location: string(location.MensaSued), FeedID: 1, Day: tuesday, Message: "day appears more than once in english plan"},
+			},
+		},
+		{
+			name:     "unpaired item",
+			german:   plan.Plan{Location: 1, Days: []plan.Day{{Timestamp: monday, Items: []plan.Item{{Category: "Essen 1", Title: "Schnitzel"}, {Category: "Essen 2", Title: "Curry"}}}}},
+			english:  plan.Plan{Location: 1, Days: []plan.Day{day(monday, "Schnitzel")}},
+			wantLoc:  location.MensaSued,
+			wantDays: []ltime.Day{monday},
+			wantDE:   []string{"Schnitzel", "Curry"},
+			warnings: []faulunch.SyncWarning{
+				{Kind: faulunch.WarningUnpairedItem, Location: string(location.MensaSued), FeedID: 1, Day: monday, Category: "Essen 2", Title: "Curry", MissingEN: true, Message: "item has no english counterpart"},
+			},
+		},
+	}
+
+	logger := zerolog.Nop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, days, menu, warnings := faulunch.Merge(&logger, tt.german, tt.english)
+
+			if loc != tt.wantLoc {
+				t.Errorf("Merge() location = %q, want %q", loc, tt.wantLoc)
+			}
+			if !slices.Equal(days, tt.wantDays) {
+				t.Errorf("Merge() days = %v, want %v", days, tt.wantDays)
+			}
+
+			var titles []string
+			for _, item := range menu {
+				titles = append(titles, item.TitleDE)
+			}
+			if !slices.Equal(titles, tt.wantDE) {
+				t.Errorf("Merge() items = %q, want %q", titles, tt.wantDE)
+			}
+
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("Merge() warnings = %+v, want %+v", warnings, tt.warnings)
+			}
+		})
+	}
+}
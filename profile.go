@@ -0,0 +1,269 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words encoding base json errors html template http slices strings time github faulunch internal annotations i18n
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal"
+	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/i18n"
+)
+
+// Profile describes the dietary restrictions of a single person.
+//
+// Profiles are not stored on the server.
+// Instead they are encoded into a token, see [Profile.Token], which is passed in a cookie or a shareable url.
+type Profile struct {
+	Allergens []annotations.Allergen `json:"allergens,omitempty"` // allergens that must not be contained
+	Additives []annotations.Additive `json:"additives,omitempty"` // additives that must not be contained
+	Diets     []DietaryCategory      `json:"diets,omitempty"`     // preferred diets, empty if any diet is fine
+
+	Hide bool `json:"hide,omitempty"` // hide unsuitable items instead of greying them out
+}
+
+// IsZero checks if this profile does not restrict anything.
+func (p Profile) IsZero() bool {
+	return len(p.Allergens) == 0 && len(p.Additives) == 0 && len(p.Diets) == 0 && !p.Hide
+}
+
+// normalize removes unknown and duplicate entries and sorts the remaining ones.
+func (p Profile) normalize() Profile {
+	allergens := make(map[annotations.Allergen]struct{}, len(p.Allergens))
+	for _, a := range p.Allergens {
+		if a, ok := a.Normalize(); ok {
+			allergens[a] = struct{}{}
+		}
+	}
+
+	additives := make(map[annotations.Additive]struct{}, len(p.Additives))
+	for _, a := range p.Additives {
+		if a, ok := a.Normalize(); ok {
+			additives[a] = struct{}{}
+		}
+	}
+
+	diets := make(map[DietaryCategory]struct{}, len(p.Diets))
+	for _, d := range p.Diets {
+		if slices.Contains(dietaryCategories, d) {
+			diets[d] = struct{}{}
+		}
+	}
+
+	return Profile{
+		Allergens: internal.SortedKeysOf(allergens, func(a, b annotations.Allergen) int { return a.Cmp(b) }),
+		Additives: internal.SortedKeysOf(additives, func(a, b annotations.Additive) int { return a.Cmp(b) }),
+		Diets: internal.SortedKeysOf(diets, func(a, b DietaryCategory) int {
+			return slices.Index(dietaryCategories, a) - slices.Index(dietaryCategories, b)
+		}),
+		Hide: p.Hide,
+	}
+}
+
+// Token encodes this profile into an url-safe token.
+// The zero profile is encoded as the empty string.
+func (p Profile) Token() string {
+	p = p.normalize()
+	if p.IsZero() {
+		return ""
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		panic("Profile.Token: json.Marshal failed")
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+var errInvalidProfile = errors.New("invalid profile token")
+
+// ParseProfile parses a profile from a token created by [Profile.Token].
+// Unknown annotations and diets are silently dropped.
+func ParseProfile(token string) (p Profile, err error) {
+	if token == "" {
+		return p, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return p, errInvalidProfile
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Profile{}, errInvalidProfile
+	}
+	return p.normalize(), nil
+}
+
+// ProfileFromForm reads a profile from the submitted values of the profile form.
+func ProfileFromForm(values url.Values) Profile {
+	var p Profile
+	for _, a := range values["allergen"] {
+		p.Allergens = append(p.Allergens, annotations.Allergen(a))
+	}
+	for _, a := range values["additive"] {
+		p.Additives = append(p.Additives, annotations.Additive(a))
+	}
+	for _, d := range values["diet"] {
+		p.Diets = append(p.Diets, DietaryCategory(d))
+	}
+	p.Hide = values.Get("hide") != ""
+	return p.normalize()
+}
+
+// ProfileCheck is the result of checking a menu item against a profile.
+type ProfileCheck struct {
+	Allergens []annotations.Allergen // excluded allergens contained in the item
+	Additives []annotations.Additive // excluded additives contained in the item
+	Diet      bool                   // the item does not match any preferred diet
+}
+
+// Unsuitable checks if the item should not be eaten by the owner of the profile.
+func (pc ProfileCheck) Unsuitable() bool {
+	return len(pc.Allergens) > 0 || len(pc.Additives) > 0 || pc.Diet
+}
+
+// Check checks the given item against this profile.
+func (p Profile) Check(item MenuItem) (pc ProfileCheck) {
+	for _, a := range item.AllergenAnnotations.Data() {
+		if slices.Contains(p.Allergens, a) {
+			pc.Allergens = append(pc.Allergens, a)
+		}
+	}
+	for _, a := range item.AdditiveAnnotations.Data() {
+		if slices.Contains(p.Additives, a) {
+			pc.Additives = append(pc.Additives, a)
+		}
+	}
+	pc.Diet = len(p.Diets) > 0 && !slices.ContainsFunc(p.Diets, item.DietaryCategory.Satisfies)
+	return pc
+}
+
+// Has checks if the profile contains the given allergen, additive or diet.
+// It is intended to be used from within templates.
+func (p Profile) Has(value any) bool {
+	switch value := value.(type) {
+	case annotations.Allergen:
+		return slices.Contains(p.Allergens, value)
+	case annotations.Additive:
+		return slices.Contains(p.Additives, value)
+	case DietaryCategory:
+		return slices.Contains(p.Diets, value)
+	}
+	return false
+}
+
+// CSS returns style rules marking the excluded annotations within the menu and the legend.
+func (p Profile) CSS() template.CSS {
+	var selectors []string
+	for _, a := range p.Allergens {
+		selectors = append(selectors, `a.annot[href="#all-`+string(a)+`"]`, `tr[id="all-`+string(a)+`"]`)
+	}
+	for _, a := range p.Additives {
+		selectors = append(selectors, `a.annot[href="#add-`+string(a)+`"]`, `tr[id="add-`+string(a)+`"]`)
+	}
+	if len(selectors) == 0 {
+		return ""
+	}
+	return template.CSS(strings.Join(selectors, ",\n") + " { color: var(--unsuitable); text-decoration: line-through; }")
+}
+
+const (
+	profileCookie = "faulunch-profile"
+	profileParam  = "profile"
+	profileMaxAge = 365 * 24 * time.Hour
+)
+
+// RequestProfile returns the profile to use for the given request.
+// A token passed in the url takes precedence over the one stored in a cookie.
+// Invalid tokens result in the zero profile.
+func RequestProfile(r *http.Request) Profile {
+	token := r.URL.Query().Get(profileParam)
+	if token == "" {
+		if cookie, err := r.Cookie(profileCookie); err == nil {
+			token = cookie.Value
+		}
+	}
+
+	p, _ := ParseProfile(token)
+	return p
+}
+
+// setProfileCookie stores the given profile in a cookie, or removes the cookie for the zero profile.
+func setProfileCookie(w http.ResponseWriter, p Profile) {
+	cookie := &http.Cookie{
+		Name:     profileCookie,
+		Value:    p.Token(),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(profileMaxAge / time.Second),
+	}
+	if cookie.Value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+type profileContext struct {
+	globalContext
+
+	Profile   Profile
+	Catalogue annotations.Catalogue
+	Diets     []DietaryCategory
+}
+
+// ShareLink returns a link to the profile page that carries the profile.
+func (pc profileContext) ShareLink() string {
+	link := "/" + pc.Lang.Code() + "/profile"
+	if token := pc.Profile.Token(); token != "" {
+		link += "?" + url.Values{profileParam: {token}}.Encode()
+	}
+	return link
+}
+
+// HandleProfile renders a page to edit the profile of the user.
+func (server *Server) HandleProfile(lang i18n.Language, w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "HandleProfile").Logger()
+
+	pc := profileContext{
+		globalContext: globalContext{
+			Lang:       lang,
+			requestURI: r.URL.RequestURI(),
+			legal:      server.Legal,
+		},
+		Profile:   RequestProfile(r),
+		Catalogue: annotations.NewCatalogue(),
+		Diets:     DietaryCategories(),
+	}
+	if err := pc.loadLastSync(r.Context(), &server.API); err != nil {
+		logger.Debug().Err(err).Msg("LoadLastSync")
+	}
+
+	w.Header().Add("Content-Type", "text/html")
+	err := apiServerTemplate.ExecuteTemplate(w, "profile.html", pc)
+	logger.Debug().Err(err).Msg("ExecuteTemplate")
+}
+
+// HandleProfileForm stores the submitted profile in a cookie and redirects back to the profile page.
+func (server *Server) HandleProfileForm(lang i18n.Language, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	var p Profile
+	if r.PostForm.Get("clear") == "" {
+		p = ProfileFromForm(r.PostForm)
+	}
+	setProfileCookie(w, p)
+
+	http.Redirect(w, r, "/"+lang.Code()+"/profile", http.StatusSeeOther)
+}
@@ -0,0 +1,96 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words slices testing github faulunch internal annotations
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/annotations"
+)
+
+func TestProfile_Token(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile faulunch.Profile
+		want    faulunch.Profile
+	}{
+		{
+			name:    "zero profile",
+			profile: faulunch.Profile{},
+			want:    faulunch.Profile{},
+		},
+		{
+			name: "full profile",
+			profile: faulunch.Profile{
+				Allergens: []annotations.Allergen{annotations.Milk, annotations.Wheat},
+				Additives: []annotations.Additive{annotations.Caffeine},
+				Diets:     []faulunch.DietaryCategory{faulunch.DietaryCategoryVegan, faulunch.DietaryCategoryVegetarian},
+				Hide:      true,
+			},
+			want: faulunch.Profile{
+				Allergens: []annotations.Allergen{annotations.Wheat, annotations.Milk},
+				Additives: []annotations.Additive{annotations.Caffeine},
+				Diets:     []faulunch.DietaryCategory{faulunch.DietaryCategoryVegetarian, faulunch.DietaryCategoryVegan},
+				Hide:      true,
+			},
+		},
+		{
+			name: "unknown and duplicate entries",
+			profile: faulunch.Profile{
+				Allergens: []annotations.Allergen{annotations.Milk, "Xx", annotations.Milk},
+				Diets:     []faulunch.DietaryCategory{"carnivore"},
+			},
+			want: faulunch.Profile{
+				Allergens: []annotations.Allergen{annotations.Milk},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := faulunch.ParseProfile(tt.profile.Token())
+			if err != nil {
+				t.Fatalf("ParseProfile() error = %v", err)
+			}
+			if !equalProfiles(got, tt.want) {
+				t.Errorf("ParseProfile(Token()) = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProfile_invalid(t *testing.T) {
+	for _, token := range []string{"!!!", "bm90IGpzb24"} {
+		if _, err := faulunch.ParseProfile(token); err == nil {
+			t.Errorf("ParseProfile(%q) expected error", token)
+		}
+	}
+}
+
+func TestProfileFromForm(t *testing.T) {
+	got := faulunch.ProfileFromForm(url.Values{
+		"allergen": {"Mi", "Ei"},
+		"additive": {"2"},
+		"diet":     {"vegan"},
+		"hide":     {"1"},
+	})
+	want := faulunch.Profile{
+		Allergens: []annotations.Allergen{annotations.Eggs, annotations.Milk},
+		Additives: []annotations.Additive{annotations.Caffeine},
+		Diets:     []faulunch.DietaryCategory{faulunch.DietaryCategoryVegan},
+		Hide:      true,
+	}
+	if !equalProfiles(got, want) {
+		t.Errorf("ProfileFromForm() = %#v, want %#v", got, want)
+	}
+}
+
+// equalProfiles compares two profiles, treating nil and empty slices as equal.
+func equalProfiles(a, b faulunch.Profile) bool {
+	return slices.Equal(a.Allergens, b.Allergens) &&
+		slices.Equal(a.Additives, b.Additives) &&
+		slices.Equal(a.Diets, b.Diets) &&
+		a.Hide == b.Hide
+}
@@ -0,0 +1,196 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words bytes encoding json xml flag http httptest path filepath regexp strings testing github glebarez sqlite zerolog faulunch internal location ltime plan gorm gormlogger
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/plan"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var update = flag.Bool("update", false, "update golden files instead of comparing against them")
+
+const (
+	corpusDir = "testdata/corpus"
+	goldenDir = "testdata/golden"
+)
+
+// TestReplay replays the recorded upstream xml in the corpus through the full pipeline.
+// It compares the resulting warnings, the menus of both api versions and the html pages with the golden files.
+//
+// The corpus can be recorded using cmd/record, golden files are updated using "go test -run TestReplay -update".
+func TestReplay(t *testing.T) {
+	germanFiles, err := filepath.Glob(filepath.Join(corpusDir, "*.de.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(germanFiles) == 0 {
+		t.Fatal("corpus is empty")
+	}
+
+	for _, germanFile := range germanFiles {
+		loc := location.Location(strings.TrimSuffix(filepath.Base(germanFile), ".de.xml"))
+		t.Run(string(loc), func(t *testing.T) {
+			replay(t, loc, germanFile, strings.TrimSuffix(germanFile, ".de.xml")+".en.xml")
+		})
+	}
+}
+
+func replay(t *testing.T, loc location.Location, germanFile, englishFile string) {
+	t.Helper()
+
+	german := readPlan(t, germanFile)
+	english := readPlan(t, englishFile)
+
+	// sync into a fresh database
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "replay.db")), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := faulunch.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := zerolog.Nop()
+	warnings, err := faulunch.Sync(&logger, db, german, english)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if err := faulunch.RefreshComputedFields(t.Context(), &logger, db); err != nil {
+		t.Fatalf("RefreshComputedFields() error = %v", err)
+	}
+
+	golden := filepath.Join(goldenDir, string(loc))
+	compareGolden(t, filepath.Join(golden, "warnings.json"), encodeJSON(t, warnings))
+
+	api := faulunch.API{DB: db}
+	unknown, err := api.UnknownAnnotations(t.Context())
+	if err != nil {
+		t.Fatalf("UnknownAnnotations() error = %v", err)
+	}
+	for i := range unknown {
+		unknown[i].FirstSeen, unknown[i].LastSeen = 0, 0
+	}
+	compareGolden(t, filepath.Join(golden, "unknown-annotations.json"), encodeJSON(t, unknown))
+
+	categories, err := api.CategoryTranslations(t.Context())
+	if err != nil {
+		t.Fatalf("CategoryTranslations() error = %v", err)
+	}
+	for i := range categories {
+		categories[i].FirstSeen, categories[i].LastSeen = 0, 0
+	}
+	compareGolden(t, filepath.Join(golden, "categories.json"), encodeJSON(t, categories))
+
+	// compare the menu of every day in both versions of the api and the pages in both languages
+	server := &faulunch.Server{Logger: &logger, API: api, ValidateAPI: func(r *http.Request, err error) { t.Error(err) }}
+	for _, day := range german.Days {
+		d := ltime.ParseDay(day.Timestamp)
+
+		compareGolden(t, filepath.Join(golden, d.String()+".json"), indentJSON(t, get(t, server, "/api/v1/menu/"+string(loc)+"/"+d.String())))
+		compareGolden(t, filepath.Join(golden, d.String()+".v2.json"), indentJSON(t, get(t, server, "/api/v2/menu/"+string(loc)+"/"+d.Date())))
+		compareGolden(t, filepath.Join(golden, d.String()+".de.html"), normalizeHTML(get(t, server, "/de/"+string(loc)+"/"+d.String())))
+		compareGolden(t, filepath.Join(golden, d.String()+".en.html"), normalizeHTML(get(t, server, "/en/"+string(loc)+"/"+d.String())))
+	}
+}
+
+// lastSyncRegexp matches the time of the last sync in the footer of a page.
+var lastSyncRegexp = regexp.MustCompile(`<time datetime="[^"]*">[^<]*</time>\.`)
+
+// normalizeHTML replaces the parts of a page that change between runs, so that it can be compared with a golden file.
+// Assets are inlined into the page, so there are no cache-busting hashes to replace.
+func normalizeHTML(page []byte) []byte {
+	return lastSyncRegexp.ReplaceAll(page, []byte(`<time datetime="LAST-SYNC">LAST-SYNC</time>.`))
+}
+
+func readPlan(t *testing.T, path string) (p plan.Plan) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read plan: %v", err)
+	}
+	if err := xml.Unmarshal(data, &p); err != nil {
+		t.Fatalf("failed to decode plan %q: %v", path, err)
+	}
+	return p
+}
+
+func encodeJSON(t *testing.T, value any) []byte {
+	t.Helper()
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode json: %v", err)
+	}
+	return append(data, '\n')
+}
+
+// indentJSON indents the given json, so that golden files can be reviewed line by line.
+func indentJSON(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, bytes.TrimSpace(data), "", "  "); err != nil {
+		t.Fatalf("failed to indent json: %v", err)
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes()
+}
+
+// get makes a GET request to the given server and returns the response body.
+func get(t *testing.T, server http.Handler, path string) []byte {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET %s returned status %d", path, rec.Code)
+	}
+	return rec.Body.Bytes()
+}
+
+// compareGolden compares got with the content of the golden file at path.
+// If the update flag is set, the golden file is written instead.
+func compareGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match golden file %s (run with -update to update it)", path)
+	}
+}
@@ -1,14 +1,15 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words embed html template http regexp strings sync time github zerolog faulunch internal golang text language
+//spellchecker:words embed errors html template http strconv strings sync time github zerolog faulunch internal
 import (
 	"context"
 	"embed"
+	"errors"
 	"html/template"
 	"net/http"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,9 +17,9 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/tkw1536/faulunch/internal"
 	"github.com/tkw1536/faulunch/internal/annotations"
+	"github.com/tkw1536/faulunch/internal/i18n"
 	"github.com/tkw1536/faulunch/internal/location"
 	"github.com/tkw1536/faulunch/internal/ltime"
-	"golang.org/x/text/language"
 )
 
 //go:embed "api_server"
@@ -42,6 +43,25 @@ type Server struct {
 
 	API   API
 	Legal ServerLegal
+
+	// AdminToken is the token required to access administrative routes.
+	// If empty, administrative routes are disabled.
+	AdminToken string
+
+	// CacheMaxAge is the maximum age clients may cache public responses for.
+	// If zero, clients are asked to revalidate responses if the response cache is enabled, and no caching headers are sent otherwise.
+	CacheMaxAge time.Duration
+
+	// CacheSize is the maximal size in bytes of public responses kept in memory.
+	// Cached responses are dropped whenever the database changes.
+	// If zero, responses are not cached.
+	CacheSize int
+	cache     serverCache
+
+	// ValidateAPI, if not nil, validates every api response against its openapi document, see [APIDocument].
+	// Problems are passed to ValidateAPI, responses are not changed.
+	// It is intended to be used in tests.
+	ValidateAPI func(r *http.Request, err error)
 }
 
 type ServerLegal struct {
@@ -66,88 +86,112 @@ func (legal ServerLegal) ENHTML() template.HTML {
 	return template.HTML("<a href='" + legal.Link + "' target='_blank' rel='noopener noreferer'>" + template.HTMLEscapeString(legal.ENString) + "</a>")
 }
 
-var matcher = language.NewMatcher([]language.Tag{
-	language.German,
-	language.English,
-})
-
-var regexpValidLocation = regexp.MustCompile("^[aA-zZ0-9-_]+$")
-
 func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	server.init.Do(func() {
+		server.cache.responses.MaxBytes = server.CacheSize
+
 		server.mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path != "/" && r.URL.Path != "" {
 				http.NotFound(w, r)
 				return
 			}
-			tag, _ := language.MatchStrings(matcher, r.Header.Get("Accept-Language"))
-			if tag == language.German {
-				http.Redirect(w, r, "/de/", http.StatusTemporaryRedirect)
-			} else {
-				http.Redirect(w, r, "/en/", http.StatusTemporaryRedirect)
-			}
+			lang := i18n.Match(r.Header.Get("Accept-Language"))
+			http.Redirect(w, r, "/"+lang.Code()+"/", http.StatusTemporaryRedirect)
 		})
 
-		// index
-		server.mux.HandleFunc("GET /en/", func(w http.ResponseWriter, r *http.Request) {
-			server.HandleIndex(true, w, r)
-		})
-		server.mux.HandleFunc("GET /de/", func(w http.ResponseWriter, r *http.Request) {
-			server.HandleIndex(false, w, r)
-		})
-
-		// specific locations
-		locations, _ := server.API.Locations()
-		for _, l := range locations {
-			l := string(l)
-			if !regexpValidLocation.MatchString(l) || l == "de" || l == "en" {
-				server.Logger.Warn().Str("location", l).Msg("Skipping invalid location")
+		// specific locations, resolved on every request so that newly synced locations are picked up
+		server.mux.HandleFunc("GET /{location}/", func(w http.ResponseWriter, r *http.Request) {
+			l := r.PathValue("location")
+			if r.URL.Path != "/"+l+"/" {
+				http.NotFound(w, r)
+				return
 			}
-			server.mux.HandleFunc("GET /"+l+"/", func(w http.ResponseWriter, r *http.Request) {
-				tag, _ := language.MatchStrings(matcher, r.Header.Get("Accept-Language"))
-				if tag == language.German {
-					http.Redirect(w, r, "/de/"+l+"/", http.StatusTemporaryRedirect)
-				} else {
-					http.Redirect(w, r, "/en/"+l+"/", http.StatusTemporaryRedirect)
-				}
-			})
-		}
-
-		// location
-		server.mux.HandleFunc("GET /en/{location}/", func(w http.ResponseWriter, r *http.Request) {
-			loc := location.Location(r.PathValue("location"))
-			server.HandleLocation(loc, true, w, r)
-		})
 
-		server.mux.HandleFunc("GET /de/{location}/", func(w http.ResponseWriter, r *http.Request) {
-			loc := location.Location(r.PathValue("location"))
-			server.HandleLocation(loc, false, w, r)
-		})
-
-		// menu
+			exists, err := server.API.KnowsLocation(location.Location(l))
+			if err != nil || !exists {
+				http.NotFound(w, r)
+				return
+			}
 
-		server.mux.HandleFunc("GET /en/{location}/{day}", func(w http.ResponseWriter, r *http.Request) {
-			day := ltime.ParseDay(r.PathValue("day"))
-			loc := location.Location(r.PathValue("location"))
-			server.HandleMenu(loc, day, true, w, r)
+			lang := i18n.Match(r.Header.Get("Accept-Language"))
+			http.Redirect(w, r, "/"+lang.Code()+"/"+l+"/", http.StatusTemporaryRedirect)
 		})
 
-		server.mux.HandleFunc("GET /de/{location}/{day}", func(w http.ResponseWriter, r *http.Request) {
-			day := ltime.ParseDay(r.PathValue("day"))
-			loc := location.Location(r.PathValue("location"))
-			server.HandleMenu(loc, day, false, w, r)
-		})
+		// localized pages, one set of routes per supported language
+		for _, lang := range i18n.Languages() {
+			server.registerLanguageRoutes(lang)
+		}
 
 		// API
 		server.registerAPIRoutes()
+
+		// admin
+		server.registerAdminRoutes()
 	})
 
+	server.setCacheHeaders(w, r)
+
+	if server.cacheable(r) {
+		server.serveCached(w, r)
+		return
+	}
 	server.mux.ServeHTTP(w, r)
 }
 
+// setCacheHeaders sets the caching headers for the given request.
+// Only public GET and HEAD requests may be cached.
+func (server *Server) setCacheHeaders(w http.ResponseWriter, r *http.Request) {
+	if (server.CacheMaxAge <= 0 && server.CacheSize <= 0) || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return
+	}
+	if isAdminPath(r.URL.Path) {
+		return
+	}
+
+	if server.CacheMaxAge > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(server.CacheMaxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	// responses depend on the requested format, the preferred language and the dietary profile
+	w.Header().Add("Vary", "Accept, Accept-Language, Cookie")
+}
+
+// registerLanguageRoutes registers the routes of all localized pages in the given language.
+func (server *Server) registerLanguageRoutes(lang i18n.Language) {
+	prefix := "/" + lang.Code()
+
+	// index
+	server.mux.HandleFunc("GET "+prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		server.HandleIndex(lang, w, r)
+	})
+
+	// location
+	server.mux.HandleFunc("GET "+prefix+"/{location}/", func(w http.ResponseWriter, r *http.Request) {
+		loc := location.Location(r.PathValue("location"))
+		server.HandleLocation(loc, lang, w, r)
+	})
+
+	// menu
+	server.mux.HandleFunc("GET "+prefix+"/{location}/{day}", func(w http.ResponseWriter, r *http.Request) {
+		day := ltime.ParseDay(r.PathValue("day"))
+		loc := location.Location(r.PathValue("location"))
+		server.HandleMenu(loc, day, lang, w, r)
+	})
+
+	// profile
+	server.mux.HandleFunc("GET "+prefix+"/profile", func(w http.ResponseWriter, r *http.Request) {
+		server.HandleProfile(lang, w, r)
+	})
+	server.mux.HandleFunc("POST "+prefix+"/profile", func(w http.ResponseWriter, r *http.Request) {
+		server.HandleProfileForm(lang, w, r)
+	})
+}
+
 type globalContext struct {
 	requestURI string // uri being requested
-	English    bool
+	Lang       i18n.Language
 
 	legal    ServerLegal
 	LastSync time.Time
@@ -164,32 +208,79 @@ func (gc *globalContext) loadLastSync(ctx context.Context, api *API) error {
 }
 
 func (gc globalContext) LegalHTML() template.HTML {
-	if gc.English {
-		return gc.legal.ENHTML()
-	} else {
+	if gc.Lang.German() {
 		return gc.legal.DEHTML()
 	}
+	return gc.legal.ENHTML()
 }
 
 func (gc globalContext) LangAttr() template.HTMLAttr {
-	if gc.English {
-		return "lang=\"en\""
+	return template.HTMLAttr("lang=\"" + gc.Lang.Code() + "\"")
+}
+
+// SemesterBreaks returns the lecture-free periods, where the break hours of a location apply.
+func (gc globalContext) SemesterBreaks() []location.Period {
+	return location.SemesterBreaks()
+}
+
+// English checks if upstream texts should be shown in english.
+func (gc globalContext) English() bool {
+	return gc.Lang.English()
+}
+
+// UpstreamLangAttr returns a lang attribute for upstream texts, if they are not in the language of the page.
+func (gc globalContext) UpstreamLangAttr() template.HTMLAttr {
+	if gc.Lang.German() || gc.Lang == i18n.English {
+		return ""
 	}
-	return "lang=\"de\""
+	return template.HTMLAttr("lang=\"" + i18n.English.Code() + "\"")
+}
+
+// T returns the message with the given key in the language of the page.
+func (gc globalContext) T(key string, args ...any) string {
+	return gc.Lang.Sprintf(key, args...)
+}
+
+// HTML returns the message with the given key in the language of the page.
+// The message may contain html, see [i18n.Language.HTML].
+func (gc globalContext) HTML(key string, args ...any) template.HTML {
+	return gc.Lang.HTML(key, args...)
+}
+
+// Name returns the name of the given value in the language of the page.
+func (gc globalContext) Name(value i18n.Named) string {
+	return gc.Lang.Name(value)
 }
 
+// Number formats the given number in the language of the page.
+func (gc globalContext) Number(value i18n.Named) string {
+	return gc.Lang.Number(value)
+}
+
+// Date formats the given day in the language of the page.
+func (gc globalContext) Date(d ltime.Day) string {
+	return gc.Lang.Date(d)
+}
+
+// DateHTML formats the given day in the language of the page, wrapped in a time element.
+func (gc globalContext) DateHTML(d ltime.Day) template.HTML {
+	return gc.Lang.DateHTML(d)
+}
+
+// Alternate returns links to the current page in all other languages.
 func (gc globalContext) Alternate() template.HTML {
-	alternate := gc.requestURI
-	var lang, title string
-	if gc.English {
-		alternate = strings.ReplaceAll(alternate, "/en/", "/de/")
-		lang, title = "de", "🇩🇪 Deutsche Version"
-	} else {
-		alternate = strings.ReplaceAll(alternate, "/de/", "/en/")
-		lang, title = "en", "🇬🇧 English Version"
-	}
+	current := "/" + gc.Lang.Code() + "/"
 
-	return template.HTML("<a href='" + alternate + "' rel='alternate' lang='" + lang + "'>" + title + "</a>")
+	var links []string
+	for _, lang := range i18n.Languages() {
+		if lang == gc.Lang {
+			continue
+		}
+
+		alternate := strings.Replace(gc.requestURI, current, "/"+lang.Code()+"/", 1)
+		links = append(links, "<a href='"+template.HTMLEscapeString(alternate)+"' rel='alternate' lang='"+lang.Code()+"'>"+template.HTMLEscapeString(lang.Sprintf("language.alternate"))+"</a>")
+	}
+	return template.HTML(strings.Join(links, "\n"))
 }
 
 type indexContext struct {
@@ -197,7 +288,7 @@ type indexContext struct {
 	Locations []location.Location
 }
 
-func (server *Server) HandleIndex(english bool, w http.ResponseWriter, r *http.Request) {
+func (server *Server) HandleIndex(lang i18n.Language, w http.ResponseWriter, r *http.Request) {
 	logger := server.Logger.With().Str("route", "Index").Logger()
 
 	// fetch all the items
@@ -213,7 +304,7 @@ func (server *Server) HandleIndex(english bool, w http.ResponseWriter, r *http.R
 	{
 		context := indexContext{
 			globalContext: globalContext{
-				English:    english,
+				Lang:       lang,
 				requestURI: r.URL.RequestURI(),
 				legal:      server.Legal,
 			},
@@ -236,6 +327,7 @@ type menuContext struct {
 	Location   location.Location
 	Pagination Pagination
 	Items      []MenuItem
+	Profile    Profile
 
 	Allergens   []annotations.Allergen
 	Additives   []annotations.Additive
@@ -246,47 +338,154 @@ func (mc menuContext) ID(id string) string {
 	return strings.ReplaceAll(id, " ", "-")
 }
 
-func (mc menuContext) Link(d ltime.Day) template.HTML {
-	link := string(mc.Location) + "/" + d.String()
-	var date string
-	if mc.globalContext.English {
-		link = "/en/" + link
-		date = string(d.ENHTML())
-	} else {
-		link = "/de/" + link
-		date = string(d.DEHTML())
+// ItemID returns a unique id for the item with the given index.
+// Items sharing a category are numbered, starting with the second one.
+func (mc menuContext) ItemID(index int) string {
+	category := mc.Items[index].Category
+
+	count := 1
+	for _, item := range mc.Items[:index] {
+		if item.Category == category {
+			count++
+		}
 	}
 
-	return template.HTML("<a href='" + link + "'>" + date + "</a>")
+	id := mc.ID(category)
+	if count > 1 {
+		id += "-" + strconv.Itoa(count)
+	}
+	return id
+}
+
+// Check checks the item with the given index against the profile of the user.
+func (mc menuContext) Check(index int) ProfileCheck {
+	return mc.Profile.Check(mc.Items[index])
+}
+
+func (mc menuContext) Link(d ltime.Day) template.HTML {
+	link := "/" + mc.Lang.Code() + "/" + string(mc.Location) + "/" + d.String()
+	return template.HTML("<a href='" + link + "'>" + string(mc.DateHTML(d)) + "</a>")
 }
 
 const (
 	menuPaginationSize = 2
 )
 
-func (server *Server) HandleLocation(loc location.Location, english bool, w http.ResponseWriter, r *http.Request) {
+func (server *Server) HandleLocation(loc location.Location, lang i18n.Language, w http.ResponseWriter, r *http.Request) {
 	logger := server.Logger.With().Str("route", "HandleLocation").Str("location", string(loc)).Logger()
 
-	now, err := server.API.CurrentDay(loc, ltime.Today())
+	today := ltime.Today()
+	if !loc.Description().OpenOn(today) {
+		server.HandleClosed(loc, today, lang, w, r)
+		return
+	}
+
+	now, err := server.API.CurrentDay(loc, today)
 	logger.Debug().Err(err).Msg("API.CurrentDay")
-	if err != nil {
+	if err != nil && !errors.Is(err, errNoCurrent) {
 		http.NotFound(w, r)
 		return
 	}
-	server.HandleMenu(loc, now, english, w, r)
+
+	// there is no menu for today (yet)
+	if now != today {
+		server.HandleNoMenu(loc, today, now, lang, w, r)
+		return
+	}
+	server.HandleMenu(loc, now, lang, w, r)
+}
+
+type closedContext struct {
+	menuContext
+
+	Reason       string    // reason for the closure, if any
+	NextOpen     ltime.Day // next day the location is open, 0 if unknown
+	NextOpenMenu bool      // is there a menu for NextOpen?
+
+	Open   bool      // is the location known to be open on the day, but without a menu?
+	NoMenu bool      // is there no menu on the day, but the opening hours are unknown?
+	Latest ltime.Day // latest earlier day with a menu if Open or NoMenu, 0 if unknown
+}
+
+// HandleClosed renders a page indicating that the given location is closed on the given day.
+func (server *Server) HandleClosed(loc location.Location, day ltime.Day, lang i18n.Language, w http.ResponseWriter, r *http.Request) {
+	server.handleClosed(loc, day, false, 0, lang, w, r)
+}
+
+// HandleNoMenu renders a page indicating that the given location has no menu for the given day.
+// If the opening hours of the location are known, the page states that the location is open on the day.
+// latest is the latest earlier day with a menu, or 0 if there is none.
+func (server *Server) HandleNoMenu(loc location.Location, day, latest ltime.Day, lang i18n.Language, w http.ResponseWriter, r *http.Request) {
+	server.handleClosed(loc, day, true, latest, lang, w, r)
+}
+
+// handleClosed implements [Server.HandleClosed] and [Server.HandleNoMenu].
+func (server *Server) handleClosed(loc location.Location, day ltime.Day, open bool, latest ltime.Day, lang i18n.Language, w http.ResponseWriter, r *http.Request) {
+	logger := server.Logger.With().Str("route", "HandleClosed").Str("location", string(loc)).Stringer("day", day).Bool("open", open).Logger()
+
+	exists, err := server.API.KnowsLocation(loc)
+	logger.Debug().Err(err).Msg("API.KnowsLocation")
+	if err != nil || !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	desc := loc.Description()
+
+	cc := closedContext{
+		menuContext: menuContext{
+			globalContext: globalContext{
+				Lang:       lang,
+				requestURI: r.URL.RequestURI(),
+				legal:      server.Legal,
+			},
+			Location: loc,
+			Day:      day,
+		},
+		Open:   open && desc.KnowsHours(),
+		NoMenu: open && !desc.KnowsHours(),
+		Latest: latest,
+	}
+	if err := cc.loadLastSync(r.Context(), &server.API); err != nil {
+		logger.Debug().Err(err).Msg("LoadLastSync")
+	}
+
+	if closure, ok := desc.ClosureOn(day); ok && !open {
+		if lang.English() {
+			cc.Reason = closure.ReasonEN
+		} else {
+			cc.Reason = closure.ReasonDE
+		}
+	}
+
+	if next, ok := desc.NextOpen(day); ok && !open {
+		cc.NextOpen = next
+
+		days, err := server.API.Days(loc, next, 1)
+		logger.Debug().Err(err).Msg("API.Days")
+		cc.NextOpenMenu = err == nil && len(days) > 0
+	}
+
+	cc.Pagination, err = server.API.DayPagination(loc, day, menuPaginationSize)
+	logger.Debug().Err(err).Msg("API.DayPagination")
+
+	w.Header().Add("Content-Type", "text/html")
+	err = apiServerTemplate.ExecuteTemplate(w, "closed.html", cc)
+	logger.Debug().Err(err).Msg("ExecuteTemplate")
 }
 
-func (server *Server) HandleMenu(loc location.Location, day ltime.Day, english bool, w http.ResponseWriter, r *http.Request) {
+func (server *Server) HandleMenu(loc location.Location, day ltime.Day, lang i18n.Language, w http.ResponseWriter, r *http.Request) {
 	logger := server.Logger.With().Str("route", "HandleMenu").Str("location", string(loc)).Stringer("day", day).Logger()
 
 	mc := menuContext{
 		globalContext: globalContext{
-			English:    english,
+			Lang:       lang,
 			requestURI: r.URL.RequestURI(),
 			legal:      server.Legal,
 		},
 		Location: loc,
 		Day:      day,
+		Profile:  RequestProfile(r),
 	}
 
 	if err := mc.loadLastSync(r.Context(), &server.API); err != nil {
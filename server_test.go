@@ -0,0 +1,154 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words http httptest strings testing github zerolog faulunch internal location ltime
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/i18n"
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+)
+
+func TestServer_HandleClosed(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}}
+
+	tuesday := ltime.ParseDay("1760997600")
+	nextWeek := tuesday.Add(7)
+
+	tests := []struct {
+		name    string
+		handle  func(w http.ResponseWriter, r *http.Request)
+		want    []string
+		notWant []string
+		status  int
+	}{
+		{
+			name: "closed",
+			handle: func(w http.ResponseWriter, r *http.Request) {
+				server.HandleClosed(location.MensaSued, tuesday.Add(4), i18n.English, w, r)
+			},
+			want:   []string{"is closed today.", "It is next open on"},
+			status: http.StatusOK,
+		},
+		{
+			name: "open without menu",
+			handle: func(w http.ResponseWriter, r *http.Request) {
+				server.HandleNoMenu(location.MensaSued, nextWeek, tuesday, i18n.English, w, r)
+			},
+			want:    []string{"is open today, but there is no menu for today yet.", "The latest menu is from <a href='/en/mensa-sued/1760997600'>"},
+			notWant: []string{"closed today", "next open"},
+			status:  http.StatusOK,
+		},
+		{
+			name: "open without any menu",
+			handle: func(w http.ResponseWriter, r *http.Request) {
+				server.HandleNoMenu(location.MensaSued, nextWeek, 0, i18n.German, w, r)
+			},
+			want:   []string{"es gibt aber noch keinen Speiseplan für heute."},
+			status: http.StatusOK,
+		},
+		{
+			name: "unknown location",
+			handle: func(w http.ResponseWriter, r *http.Request) {
+				server.HandleNoMenu("does-not-exist", nextWeek, 0, i18n.English, w, r)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handle(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			body := rec.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("body contains %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_breakHours(t *testing.T) {
+	registry, err := location.ParseRegistry([]byte(`{
+		"semesterBreaks": [{"from": "2026-07-25", "to": "2026-10-11"}],
+		"locations": [{
+			"id": "mensa-sued", "feedID": 1, "name": "Südmensa", "refactory": true,
+			"hours": {"monday": [{"open": "11:00", "close": "14:00"}]},
+			"breakHours": {"monday": [{"open": "11:30", "close": "13:30"}]}
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+	location.Use(registry)
+	defer location.Use(nil)
+
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}}
+
+	body := string(get(t, server, "/en/mensa-sued/1760911200"))
+	for _, want := range []string{"<caption>Lecture period</caption>", "<caption>Semester break</caption>", "11:30–13:30", "2026-07-25 – 2026-10-11"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("location page does not contain %q", want)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/api/v1/locations":            `"BreakHours":{"monday":[{"open":"11:30","close":"13:30"}]}`,
+		"/api/v2/locations/mensa-sued": `"breakHours":{"monday":[{"open":"11:30","close":"13:30"}]}`,
+	} {
+		if body := string(get(t, server, path)); !strings.Contains(body, want) || !strings.Contains(body, `{"from":"2026-07-25","to":"2026-10-11"}`) {
+			t.Errorf("GET %s does not contain the break hours and semester breaks: %s", path, body)
+		}
+	}
+
+	// a monday inside the semester break is open with the break hours
+	if hours := registry.Description(location.MensaSued).HoursOn(ltime.ParseDay("1785103200")); len(hours) != 1 || hours[0].Open != "11:30" {
+		t.Errorf("HoursOn() = %v, want the break hours", hours)
+	}
+}
+
+func TestServer_unknownHours(t *testing.T) {
+	registry, err := location.ParseRegistry([]byte(`{
+		"locations": [{"id": "mensa-sued", "feedID": 1, "name": "Südmensa", "refactory": true}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+	location.Use(registry)
+	defer location.Use(nil)
+
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	server := &faulunch.Server{Logger: &logger, API: faulunch.API{DB: db}}
+
+	// the corpus has no menu for today, but the location might just as well be closed
+	body := string(get(t, server, "/en/mensa-sued/"))
+	if want := "There is no menu for <em>Südmensa</em>"; !strings.Contains(body, want) {
+		t.Errorf("location page does not contain %q", want)
+	}
+	for _, notWant := range []string{"is open today", "closed today"} {
+		if strings.Contains(body, notWant) {
+			t.Errorf("location page contains %q", notWant)
+		}
+	}
+}
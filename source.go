@@ -1,10 +1,9 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words encoding errors http time github zerolog gorm
+//spellchecker:words encoding errors time github zerolog gorm
 import (
 	"context"
-	"net/http"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -13,10 +12,15 @@ import (
 	"gorm.io/gorm"
 )
 
-// FetchAndSyncAll fetches and syncs all items into the database.
+// FetchAndSyncAll fetches and syncs all items into the database, using the given client to fetch plans.
 // It then updates computed fields.
 // Returns a boolean indicating failure.
-func FetchAndSyncAll(ctx context.Context, logger *zerolog.Logger, db *gorm.DB) (failed bool) {
+func FetchAndSyncAll(ctx context.Context, logger *zerolog.Logger, db *gorm.DB, client plan.ClientLike) (failed bool) {
+	return FetchAndSyncLocations(ctx, logger, db, client, location.Locations())
+}
+
+// FetchAndSyncLocations is like [FetchAndSyncAll], but only syncs the given locations.
+func FetchAndSyncLocations(ctx context.Context, logger *zerolog.Logger, db *gorm.DB, client plan.ClientLike, locations []location.Location) (failed bool) {
 	var se SyncEvent
 	se.Begin()
 	defer func() {
@@ -26,8 +30,10 @@ func FetchAndSyncAll(ctx context.Context, logger *zerolog.Logger, db *gorm.DB) (
 		logger.Err(res).Msg("logging sync event")
 	}()
 
-	for _, loc := range location.Locations() {
-		if FetchAndSync(ctx, logger, db, loc) != nil {
+	for _, loc := range locations {
+		warnings, err := FetchAndSync(ctx, logger, db, client, loc)
+		se.Report.Warnings = append(se.Report.Warnings, warnings...)
+		if err != nil {
 			failed = true
 		}
 	}
@@ -40,17 +46,17 @@ func FetchAndSyncAll(ctx context.Context, logger *zerolog.Logger, db *gorm.DB) (
 }
 
 // FetchAndSync is like calling Fetch() and then Sync() for the given location.
-func FetchAndSync(ctx context.Context, logger *zerolog.Logger, db *gorm.DB, loc location.Location) error {
-	german, err := plan.Fetch(ctx, http.DefaultClient, loc, false)
+func FetchAndSync(ctx context.Context, logger *zerolog.Logger, db *gorm.DB, client plan.ClientLike, loc location.Location) ([]SyncWarning, error) {
+	german, err := plan.Fetch(ctx, client, loc, false)
 	logger.Err(err).Str("location", string(loc)).Bool("english", false).Msg("fetching data")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	english, err := plan.Fetch(ctx, http.DefaultClient, loc, true)
+	english, err := plan.Fetch(ctx, client, loc, true)
 	logger.Err(err).Str("location", string(loc)).Bool("english", true).Msg("fetching data")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	return Sync(logger, db, german, english)
@@ -58,9 +64,24 @@ func FetchAndSync(ctx context.Context, logger *zerolog.Logger, db *gorm.DB, loc
 
 // Sync synchronizes the given german and english plans into the database
 // Any previous content for the existing days and locations is erased.
-func Sync(logger *zerolog.Logger, db *gorm.DB, german, english plan.Plan) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		location, timestamps, items := Merge(logger, german, english)
+//
+// Problems with the plans are returned as warnings, see [Merge].
+// Feed ids of either plan that are not contained in the location registry are recorded in the database.
+func Sync(logger *zerolog.Logger, db *gorm.DB, german, english plan.Plan) (warnings []SyncWarning, err error) {
+	for _, id := range unknownFeedIDs(german, english) {
+		err := recordUnknownLocation(db, id)
+		logger.Err(err).Int("id", id).Msg("recording unknown location")
+	}
+
+	location, timestamps, items, warnings := Merge(logger, german, english)
+	for _, warning := range warnings {
+		warning.Event(logger)
+	}
+	if location == "" {
+		return warnings, nil
+	}
+
+	return warnings, db.Transaction(func(tx *gorm.DB) error {
 		times := make([]time.Time, len(timestamps))
 		for i, day := range timestamps {
 			times[i] = day.Time()
@@ -94,15 +115,25 @@ func Sync(logger *zerolog.Logger, db *gorm.DB, german, english plan.Plan) error
 }
 
 // RefreshComputedFields refreshes all computed fields in the database.
+// It also updates the report of unknown annotations and the recorded categories.
 func RefreshComputedFields(ctx context.Context, logger *zerolog.Logger, db *gorm.DB) error {
 	pageSize := 100
 
 	return db.Transaction(func(tx *gorm.DB) error {
+		translations, err := loadCategoryTranslations(tx)
+		if err != nil {
+			return err
+		}
+
 		var items []MenuItem
 
+		report := make(unknownAnnotationReport)
+		categories := make(categoryReport)
 		res := tx.Model(MenuItem{}).FindInBatches(&items, pageSize, func(tx *gorm.DB, batch int) error {
 			for i := range items {
-				items[i].UpdateComputedFields(logger)
+				items[i].UpdateComputedFields(logger, translations)
+				report.Add(&items[i])
+				categories.Add(&items[i], translations)
 			}
 
 			res := tx.Save(&items)
@@ -110,6 +141,20 @@ func RefreshComputedFields(ctx context.Context, logger *zerolog.Logger, db *gorm
 			return res.Error
 		})
 		logger.Info().Err(res.Error).Int("rowsAffected", int(res.RowsAffected)).Msg("refreshed computed fields")
-		return res.Error
+		if res.Error != nil {
+			return res.Error
+		}
+
+		now := time.Now().Unix()
+
+		err = report.Store(tx, now)
+		logger.Err(err).Msg("storing unknown annotations")
+		if err != nil {
+			return err
+		}
+
+		err = categories.Store(tx, now)
+		logger.Err(err).Msg("storing categories")
+		return err
 	})
 }
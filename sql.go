@@ -1,15 +1,15 @@
 //spellchecker:words faulunch
 package faulunch
 
-//spellchecker:words html template strconv strings github zerolog gorm datatypes
+//spellchecker:words html template strconv github zerolog faulunch internal ordering gorm datatypes
 import (
 	"html/template"
-	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/tkw1536/faulunch/internal/annotations"
 	"github.com/tkw1536/faulunch/internal/location"
 	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/ordering"
 	"github.com/tkw1536/faulunch/internal/types"
 	"gorm.io/datatypes"
 )
@@ -50,6 +50,10 @@ type MenuItem struct {
 
 	GlutenFree      bool            // is this gluten free?
 	DietaryCategory DietaryCategory // the dietary category of this item
+	DietaryLabels                   // multi-label dietary classification of this item
+
+	Edited   bool `gorm:"-"` // has this item been changed by a MenuOverride?
+	position int  // position among the upstream items sharing its category, see [MenuOverride.Position]
 
 	// Annotations properly replaced with <span class='#type'> and inside <sup>s
 	HTMLTitleDE       template.HTML
@@ -62,46 +66,28 @@ type MenuItem struct {
 	AllergenAnnotations   datatypes.JSONType[[]annotations.Allergen]
 	AdditiveAnnotations   datatypes.JSONType[[]annotations.Additive]
 	IngredientAnnotations datatypes.JSONType[[]annotations.Ingredient]
-}
+	EUAllergens           datatypes.JSONType[[]annotations.EUAllergen] // EU allergen groups of AllergenAnnotations
 
-var categoryTranslations = map[string]string{
-	"Essen":             "Meal",
-	"Aktionsessen":      "Special Meal",
-	"Aktion":            "Special",
-	"Suppe":             "Soup",
-	"Suppen":            "Soups",
-	"SB-Theke":          "Self-Service Counter",
-	"Tagesangebot":      "Daily Special",
-	"Tipp des Tages":    "Tip Of The Day",
-	"Pizza":             "Pizza",
-	"vegetarisch/vegan": "Vegetarian/Vegan",
-	"Fleisch/Fisch":     "Meat/Fish",
+	UnknownTokens     datatypes.JSONType[[]UnknownToken] `json:"-"` // unknown annotations found in the text fields
+	UnknownPictograms datatypes.JSONType[[]string]       `json:"-"` // unknown pictograms found upstream
 }
 
-func (m *MenuItem) UpdateComputedFields(logger *zerolog.Logger) {
-	m.translateCategoryNames(logger)
+// UpdateComputedFields updates all fields computed from upstream fields.
+// Category names are translated using the given translations.
+func (m *MenuItem) UpdateComputedFields(logger *zerolog.Logger, translations CategoryTranslations) {
+	m.translateCategoryNames(logger, translations)
 	m.extractAnnotations(logger)
 	m.extractGlutenFree()
 	m.extractDietaryCategory()
+	m.extractDietaryLabels()
 }
 
-func (m *MenuItem) translateCategoryNames(logger *zerolog.Logger) {
-	if complete, ok := categoryTranslations[m.Category]; ok {
-		m.CategoryEN = complete
-		return
-	}
-
-	fields := strings.Fields(m.Category)
-	for i, field := range fields {
-		trans, ok := categoryTranslations[field]
-		if ok {
-			fields[i] = trans
-		} else if !isOnlyDigits(field) {
-			logger.Debug().Str("part", field).Msg("untranslatable category part")
-		}
+func (m *MenuItem) translateCategoryNames(logger *zerolog.Logger, translations CategoryTranslations) {
+	var missing []string
+	m.CategoryEN, missing = translations.Translate(m.Category)
+	for _, part := range missing {
+		logger.Debug().Str("part", part).Msg("untranslatable category part")
 	}
-
-	m.CategoryEN = strings.Join(fields, " ")
 }
 
 func (m *MenuItem) extractGlutenFree() {
@@ -134,26 +120,8 @@ func (m MenuItem) Ingredients() []annotations.Ingredient {
 	return m.Piktogramme.Data()
 }
 
-func (m MenuItem) catScore() int {
-	switch {
-	case strings.HasPrefix(m.Category, "Essen "):
-		return -3
-	case strings.HasPrefix(m.Category, "Aktionsessen "):
-		return -2
-	case strings.HasPrefix(m.Category, "Suppe "):
-		return -1
-	default:
-		return 0
-	}
-}
-
 // Cmp compares two menu items by their "category".
+// The category ordering of the location of m is used, see [ordering.Cmp].
 func (m MenuItem) Cmp(other MenuItem) int {
-	ours, theirs := m.catScore(), other.catScore()
-	if ours < theirs {
-		return -1
-	} else if ours > theirs {
-		return 1
-	}
-	return strings.Compare(m.Category, other.Category)
+	return ordering.Cmp(m.Location, m.Category, other.Category)
 }
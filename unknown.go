@@ -0,0 +1,143 @@
+//spellchecker:words faulunch
+package faulunch
+
+//spellchecker:words slices time github faulunch internal location ltime plan gorm clause
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/tkw1536/faulunch/internal/location"
+	"github.com/tkw1536/faulunch/internal/ltime"
+	"github.com/tkw1536/faulunch/internal/plan"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnknownLocation represents a location id reported by the feed which is not contained in the location registry.
+type UnknownLocation struct {
+	ID int `gorm:"primaryKey;autoIncrement:false" json:"id"` // id as reported by the feed
+
+	FirstSeen int64 `json:"firstSeen"`
+	LastSeen  int64 `json:"lastSeen"`
+	Count     int64 `json:"count"` // number of times this id was seen
+}
+
+// unknownFeedIDs returns the feed ids of the given plans which are not contained in the location registry.
+// Plans without a feed id are ignored.
+func unknownFeedIDs(plans ...plan.Plan) (ids []int) {
+	for _, p := range plans {
+		if p.Location <= 0 || location.LocationOfID(p.Location).Valid() || slices.Contains(ids, p.Location) {
+			continue
+		}
+		ids = append(ids, p.Location)
+	}
+	return ids
+}
+
+// recordUnknownLocation records that the feed reported the given unknown location id.
+func recordUnknownLocation(db *gorm.DB, id int) error {
+	now := time.Now().Unix()
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"last_seen": now,
+			"count":     gorm.Expr("unknown_locations.count + 1"),
+		}),
+	}).Create(&UnknownLocation{ID: id, FirstSeen: now, LastSeen: now, Count: 1}).Error
+}
+
+// UnknownLocations returns all unknown location ids seen in the feed, most recently seen first.
+func (api *API) UnknownLocations(ctx context.Context) ([]UnknownLocation, error) {
+	return gorm.G[UnknownLocation](api.DB).Order("last_seen DESC, id ASC").Find(ctx)
+}
+
+// UnknownAnnotationKind is the kind of an UnknownAnnotation.
+type UnknownAnnotationKind string
+
+const (
+	UnknownKindAnnotation UnknownAnnotationKind = "annotation" // an annotation in parentheses within a text field
+	UnknownKindPictogram  UnknownAnnotationKind = "pictogram"  // a pictogram
+)
+
+// UnknownAnnotation represents an unknown annotation or pictogram found on the menu.
+// They are aggregated from all menu items whenever computed fields are refreshed.
+type UnknownAnnotation struct {
+	Kind  UnknownAnnotationKind `gorm:"primaryKey" json:"kind"`
+	Token string                `gorm:"primaryKey" json:"token"`
+
+	Count     int64 `json:"count"`     // number of menu items currently containing this token
+	FirstSeen int64 `json:"firstSeen"` // unix timestamp when this token was first found
+	LastSeen  int64 `json:"lastSeen"`  // unix timestamp when this token was last found
+
+	// the most recent occurrence of the token, by day
+	Location string    `json:"location"`
+	Day      ltime.Day `json:"day"`
+	Field    string    `json:"field"`   // field of the menu item, e.g. "TitleDE"
+	Context  string    `json:"context"` // text surrounding the token
+}
+
+// unknownAnnotationReport aggregates unknown annotations from menu items.
+type unknownAnnotationReport map[UnknownAnnotationKind]map[string]*UnknownAnnotation
+
+// Add adds the unknown annotations of the given item to the report.
+func (report unknownAnnotationReport) Add(item *MenuItem) {
+	for _, token := range item.UnknownTokens.Data() {
+		report.add(UnknownKindAnnotation, token.Token, item, token.Field, token.Context)
+	}
+	for _, pictogram := range item.UnknownPictograms.Data() {
+		report.add(UnknownKindPictogram, pictogram, item, "Piktogramme", item.TitleDE)
+	}
+}
+
+func (report unknownAnnotationReport) add(kind UnknownAnnotationKind, token string, item *MenuItem, field, context string) {
+	if report[kind] == nil {
+		report[kind] = make(map[string]*UnknownAnnotation)
+	}
+
+	annot, ok := report[kind][token]
+	if !ok {
+		annot = &UnknownAnnotation{Kind: kind, Token: token}
+		report[kind][token] = annot
+	}
+
+	annot.Count++
+	if item.Day >= annot.Day {
+		annot.Location = string(item.Location)
+		annot.Day = item.Day
+		annot.Field = field
+		annot.Context = context
+	}
+}
+
+// Store stores the report in the database, seen at the given time.
+// Previously stored annotations keep the time they were first seen.
+// Previously stored annotations not contained in the report are kept with a count of 0.
+func (report unknownAnnotationReport) Store(db *gorm.DB, now int64) error {
+	annots := make([]*UnknownAnnotation, 0, len(report))
+	for _, tokens := range report {
+		for _, annot := range tokens {
+			annot.FirstSeen = now // only used when the annotation is new, see DoUpdates below
+			annot.LastSeen = now
+			annots = append(annots, annot)
+		}
+	}
+
+	if len(annots) > 0 {
+		res := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "kind"}, {Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"count", "last_seen", "location", "day", "field", "context"}),
+		}).Create(&annots)
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	return db.Model(&UnknownAnnotation{}).Where("last_seen < ?", now).Update("count", 0).Error
+}
+
+// UnknownAnnotations returns all unknown annotations found on the menu.
+// Annotations currently on the menu come first, most recently seen first.
+func (api *API) UnknownAnnotations(ctx context.Context) ([]UnknownAnnotation, error) {
+	return gorm.G[UnknownAnnotation](api.DB).Order("count > 0 DESC, last_seen DESC, day DESC, kind ASC, token ASC").Find(ctx)
+}
@@ -0,0 +1,85 @@
+//spellchecker:words faulunch
+package faulunch_test
+
+//spellchecker:words testing github zerolog faulunch internal plan
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tkw1536/faulunch"
+	"github.com/tkw1536/faulunch/internal/plan"
+)
+
+func TestAPI_UnknownLocations(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	api := faulunch.API{DB: db}
+
+	for _, plans := range [][2]plan.Plan{
+		{{Location: 4242}, {Location: 4242}}, // unknown location
+		{{Location: 1}, {Location: 4343}},    // only the english plan is unknown
+		{{Location: 4444}, {Location: 1}},    // only the german plan is unknown
+		{{Location: 4242}, {Location: 4343}}, // both plans are unknown
+		{{Location: 1}, {Location: 1}},       // known location
+		{{Location: 1}, {}},                  // plan without a location
+	} {
+		if _, err := faulunch.Sync(&logger, db, plans[0], plans[1]); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+
+	unknown, err := api.UnknownLocations(t.Context())
+	if err != nil {
+		t.Fatalf("UnknownLocations() error = %v", err)
+	}
+
+	counts := make(map[int]int64, len(unknown))
+	for _, loc := range unknown {
+		if loc.FirstSeen == 0 || loc.LastSeen < loc.FirstSeen {
+			t.Errorf("UnknownLocations() returned %d with first seen %d and last seen %d", loc.ID, loc.FirstSeen, loc.LastSeen)
+		}
+		counts[loc.ID] = loc.Count
+	}
+
+	want := map[int]int64{4242: 2, 4343: 2, 4444: 1}
+	if len(counts) != len(want) {
+		t.Errorf("UnknownLocations() = %v, want %v", counts, want)
+	}
+	for id, count := range want {
+		if counts[id] != count {
+			t.Errorf("UnknownLocations() counted %d %d times, want %d", id, counts[id], count)
+		}
+	}
+}
+
+func TestRefreshComputedFields_unknownAnnotations(t *testing.T) {
+	logger := zerolog.Nop()
+	db, _ := newSyncedDB(t, &logger)
+	api := faulunch.API{DB: db}
+
+	// pretend that the annotations were first and last seen long ago
+	const longAgo = 1683704244
+	if err := db.Model(&faulunch.UnknownAnnotation{}).Where("1 = 1").Updates(map[string]any{"first_seen": longAgo, "last_seen": longAgo}).Error; err != nil {
+		t.Fatalf("failed to update unknown annotations: %v", err)
+	}
+
+	if err := faulunch.RefreshComputedFields(t.Context(), &logger, db); err != nil {
+		t.Fatalf("RefreshComputedFields() error = %v", err)
+	}
+
+	unknown, err := api.UnknownAnnotations(t.Context())
+	if err != nil {
+		t.Fatalf("UnknownAnnotations() error = %v", err)
+	}
+	if len(unknown) == 0 {
+		t.Fatal("UnknownAnnotations() returned no annotations")
+	}
+	for _, annot := range unknown {
+		if annot.FirstSeen != longAgo {
+			t.Errorf("UnknownAnnotations() returned %q first seen at %d, want %d", annot.Token, annot.FirstSeen, longAgo)
+		}
+		if annot.LastSeen <= longAgo {
+			t.Errorf("UnknownAnnotations() returned %q last seen at %d, want it to be updated", annot.Token, annot.LastSeen)
+		}
+	}
+}